@@ -1,7 +1,126 @@
+const crypto = require('crypto');
 const jwt = require('jsonwebtoken');
-const JWT_SECRET = process.env.JWT_SECRET;
+const AUTH_SERVICE_URL = process.env.AUTH_SERVICE_URL || 'http://auth-service:8081';
+// Devem coincidir com JWT_ISSUER e JWT_AUDIENCE do Auth Service.
+const JWT_ISSUER = process.env.JWT_ISSUER || 'auth-service';
+const JWT_AUDIENCE = process.env.JWT_AUDIENCE || 'services-with-gateway';
+// Chave de API de uma conta de serviço com a role token-introspect, exigida pelo Auth Service
+// na consulta à lista de revogação.
+const AUTH_API_KEY = process.env.AUTH_API_KEY;
 
-const authMiddleware = (req, res, next) => {
+// Intervalo mínimo entre recargas do JWKS disparadas por um kid desconhecido.
+const JWKS_REFRESH_INTERVAL_MS = 30 * 1000;
+
+// Antecedência com que o token da conta de serviço é renovado antes de expirar.
+const SERVICE_TOKEN_RENEW_MS = 60 * 1000;
+
+let jwksKeys = new Map();
+let jwksFetchedAt = 0;
+
+const fetchJwks = async () => {
+  const response = await fetch(`${AUTH_SERVICE_URL}/.well-known/jwks.json`);
+  if (!response.ok) {
+    throw new Error(`Auth Service respondeu ${response.status}`);
+  }
+  const body = await response.json();
+  const keys = new Map();
+  for (const jwk of body.keys || []) {
+    keys.set(jwk.kid, { alg: jwk.alg, key: crypto.createPublicKey({ key: jwk, format: 'jwk' }) });
+  }
+  jwksKeys = keys;
+  jwksFetchedAt = Date.now();
+};
+
+// Retorna a chave pública do kid, recarregando o JWKS quando o kid ainda não é conhecido
+// (ex.: logo após uma rotação de chaves no Auth Service).
+const getVerificationKey = async (kid) => {
+  if (!jwksKeys.has(kid) && Date.now() - jwksFetchedAt > JWKS_REFRESH_INTERVAL_MS) {
+    await fetchJwks();
+  }
+  return jwksKeys.get(kid);
+};
+
+// Verifica assinatura, emissor, audiência e validade do token com a chave publicada pelo Auth Service.
+const verifyToken = (token, header, verificationKey) => {
+  if (!verificationKey || verificationKey.alg !== header.alg) {
+    throw new Error('Chave de assinatura desconhecida');
+  }
+  if (header.alg === 'RS256') {
+    return jwt.verify(token, verificationKey.key, {
+      algorithms: ['RS256'],
+      issuer: JWT_ISSUER,
+      audience: JWT_AUDIENCE,
+    });
+  }
+
+  // jsonwebtoken não suporta EdDSA: a assinatura Ed25519 é verificada diretamente.
+  const [encodedHeader, encodedPayload, signature] = token.split('.');
+  const valid = crypto.verify(
+    null,
+    Buffer.from(`${encodedHeader}.${encodedPayload}`),
+    verificationKey.key,
+    Buffer.from(signature, 'base64url')
+  );
+  if (!valid) {
+    throw new Error('Assinatura inválida');
+  }
+  const claims = JSON.parse(Buffer.from(encodedPayload, 'base64url').toString());
+  const now = Date.now() / 1000;
+  if (typeof claims.exp !== 'number' || claims.exp <= now || (claims.nbf && claims.nbf > now)) {
+    throw new Error('Token expirado');
+  }
+  const audience = Array.isArray(claims.aud) ? claims.aud : [claims.aud];
+  if (claims.iss !== JWT_ISSUER || !audience.includes(JWT_AUDIENCE)) {
+    throw new Error('Emissor ou audiência inválidos');
+  }
+  return claims;
+};
+
+let serviceToken = null;
+let serviceTokenExpiresAt = 0;
+
+// Retorna o token da conta de serviço do gateway, trocando AUTH_API_KEY por um novo JWT
+// quando o atual está perto de expirar.
+const getServiceToken = async () => {
+  if (serviceToken && Date.now() < serviceTokenExpiresAt - SERVICE_TOKEN_RENEW_MS) {
+    return serviceToken;
+  }
+  if (!AUTH_API_KEY) {
+    throw new Error('AUTH_API_KEY não configurada');
+  }
+  const response = await fetch(`${AUTH_SERVICE_URL}/api/v1/token/api-key`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ api_key: AUTH_API_KEY }),
+  });
+  if (!response.ok) {
+    throw new Error(`Auth Service respondeu ${response.status} na troca da chave de API`);
+  }
+  const body = await response.json();
+  serviceToken = body.token;
+  serviceTokenExpiresAt = Date.now() + body.expires_in * 1000;
+  return serviceToken;
+};
+
+// Consulta o Auth Service para saber se o jti foi revogado (logout) ou se a sessão do token
+// foi encerrada antes do exp.
+const isRevoked = async (jti, sid) => {
+  const query = sid ? `?sid=${encodeURIComponent(sid)}` : '';
+  const response = await fetch(`${AUTH_SERVICE_URL}/api/v1/revoked/${encodeURIComponent(jti)}${query}`, {
+    headers: { Authorization: `Bearer ${await getServiceToken()}` },
+  });
+  if (response.status === 401) {
+    // Token da conta de serviço revogado ou assinado por uma chave já descartada.
+    serviceToken = null;
+  }
+  if (!response.ok) {
+    throw new Error(`Auth Service respondeu ${response.status}`);
+  }
+  const body = await response.json();
+  return body.revoked === true;
+};
+
+const authMiddleware = async (req, res, next) => {
   const authHeader = req.headers.authorization;
   if (!authHeader) {
     return res.status(401).json({ error: 'Token de autorização não fornecido' });
@@ -14,15 +133,41 @@ const authMiddleware = (req, res, next) => {
 
   const token = parts[1];
 
-  try {
+  const decoded = jwt.decode(token, { complete: true });
+  if (!decoded || !decoded.header.kid) {
+    return res.status(401).json({ error: 'Token inválido ou expirado' });
+  }
 
-    const claims = jwt.verify(token, JWT_SECRET);
+  let verificationKey;
+  try {
+    verificationKey = await getVerificationKey(decoded.header.kid);
+  } catch (err) {
+    console.error(`[Gateway] Falha ao obter JWKS: ${err.message}`);
+    return res.status(503).json({ error: 'Não foi possível validar o token' });
+  }
 
-    req.user = claims; 
-    next();
+  let claims;
+  try {
+    claims = verifyToken(token, decoded.header, verificationKey);
   } catch (err) {
     return res.status(401).json({ error: 'Token inválido ou expirado' });
   }
+
+  if (!claims.jti) {
+    return res.status(401).json({ error: 'Token inválido ou expirado' });
+  }
+
+  try {
+    if (await isRevoked(claims.jti, claims.sid)) {
+      return res.status(401).json({ error: 'Token revogado' });
+    }
+  } catch (err) {
+    console.error(`[Gateway] Falha ao consultar revogação: ${err.message}`);
+    return res.status(503).json({ error: 'Não foi possível validar o token' });
+  }
+
+  req.user = claims;
+  next();
 };
 
 const permissionMiddleware = (requiredPermission) => {
@@ -34,6 +34,7 @@ const converterServiceTarget = 'http://converter-service:8083';
 app.use('/api/v1/login', createProxyMiddleware({
   target: authServiceTarget,
   changeOrigin: true,
+  xfwd: true,
 
   pathRewrite: {
     '^/': '/api/v1/login',
@@ -44,6 +45,157 @@ app.use('/api/v1/login', createProxyMiddleware({
   }
 }));
 
+app.use('/api/v1/token/refresh', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/token/refresh',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Troca da chave de API de uma conta de serviço por um access token.
+app.use('/api/v1/token/api-key', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/token/api-key',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Identidade e permissões do usuário autenticado, para o frontend montar os menus.
+app.use('/api/v1/me', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/me',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Empresas clientes do usuário e troca da empresa ativa (exigem token, validado pelo Auth Service).
+app.use('/api/v1/tenants', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/$': '/api/v1/tenants',
+    '^/': '/api/v1/tenants/',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Sessões do usuário (listar e encerrar logins em outros dispositivos; exigem token, validado pelo Auth Service).
+app.use('/api/v1/sessions', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/$': '/api/v1/sessions',
+    '^/': '/api/v1/sessions/',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Login com provedores de identidade externos (OIDC): redirecionamentos do navegador, sem token.
+app.use('/api/v1/oidc', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/oidc/',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+app.use('/api/v1/logout', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/logout',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Troca e redefinição de senha: a troca exige token, validado pelo próprio Auth Service.
+app.use('/api/v1/password', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/password/',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Segundo fator (TOTP): a verificação do login é pública; o cadastro exige token,
+// validado pelo próprio Auth Service.
+app.use('/api/v1/mfa', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/mfa/',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
+// Rotas de administração: o Auth Service valida o token e exige a role "admin".
+app.use('/api/v1/admin', createProxyMiddleware({
+  target: authServiceTarget,
+  changeOrigin: true,
+  xfwd: true,
+
+  pathRewrite: {
+    '^/': '/api/v1/admin/',
+  },
+
+  onProxyReq: (proxyReq, req, res) => {
+    console.log(`[Gateway] Proxying to Auth Service: ${req.method} ${req.path}`);
+  }
+}));
+
 app.use(
   '/api/v1/analyze/icms',
   authMiddleware, 
@@ -2,24 +2,49 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"analysis-service/internal/api/handlers"
 	"analysis-service/internal/api/responses"
+	"analysis-service/internal/archive"
+	"analysis-service/internal/config"
 	"analysis-service/internal/core/analysis"
 
 	"github.com/gin-gonic/gin"
+	"shared/middleware"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: configuração inválida: %v", err)
+	}
+
 	responses.InitLogger()
 
-	analysisService := analysis.NewService()
-	analysisHandler := handlers.NewAnalysisHandler(analysisService)
+	analysisService := analysis.NewService(analysis.Config{
+		ValueTolerance:   cfg.Tolerances.Value,
+		ItemSumTolerance: cfg.Tolerances.ItemSum,
+		ICMSTolerance:    cfg.Tolerances.ICMS,
+		XMLWorkers:       cfg.XMLWorkers,
+	})
+	analysisHandler := handlers.NewAnalysisHandler(analysisService, archive.Limits{
+		MaxEntries:   cfg.Archives.MaxEntries,
+		MaxTotalSize: cfg.Archives.MaxTotalMB << 20,
+		MaxRatio:     cfg.Archives.MaxRatio,
+	})
 
 	router := gin.Default()
+	router.MaxMultipartMemory = cfg.MultipartMemoryMB << 20
 
-	apiV1 := router.Group("/api/v1")
+	apiV1 := router.Group("/api/v1", middleware.MaxBodySize(cfg.MaxUploadMB<<20, responses.Error))
 	{
 		// Sem Middleware -- Gateway lida com isso
 		apiV1.POST("/analyze/icms", analysisHandler.HandleAnalysisIcms)
@@ -30,9 +55,8 @@ func main() {
 		c.JSON(200, gin.H{"status": "UP", "service": "analysis-service"})
 	})
 
-	const port = "8082"
-	log.Printf("🚀 Analysis Service (Go) iniciado e escutando na porta %s", port)
-	if err := router.Run(":" + port); err != nil {
+	log.Printf("🚀 Analysis Service (Go) iniciado e escutando na porta %d", cfg.Port)
+	if err := router.Run(fmt.Sprintf(":%d", cfg.Port)); err != nil {
 		log.Fatal("Falha ao iniciar o servidor de análise: ", err)
 	}
 }
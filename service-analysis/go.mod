@@ -6,6 +6,7 @@ require (
 	github.com/gin-gonic/gin v1.11.0
 	go.uber.org/zap v1.27.0
 	golang.org/x/text v0.30.0
+	shared v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -41,3 +42,5 @@ require (
 	golang.org/x/tools v0.37.0 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+replace shared => ../shared
@@ -2,12 +2,15 @@
 package handlers
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"strings"
 
 	"analysis-service/internal/api/responses"
+	"analysis-service/internal/archive"
 	"analysis-service/internal/core/analysis"
+	"analysis-service/internal/domain"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,46 +18,25 @@ import (
 // AnalysisHandler handles analysis-related API requests.
 type AnalysisHandler struct {
 	service analysis.Service
+	limits  archive.Limits
 }
 
-// NewAnalysisHandler creates a new analysis handler.
-func NewAnalysisHandler(service analysis.Service) *AnalysisHandler {
+// NewAnalysisHandler creates a new analysis handler. limits bound the extraction of the
+// compressed uploads of each request.
+func NewAnalysisHandler(service analysis.Service, limits archive.Limits) *AnalysisHandler {
 	return &AnalysisHandler{
 		service: service,
+		limits:  limits,
 	}
 }
 
 // HandleAnalysisIcms handles ICMS analysis requests.
 func (h *AnalysisHandler) HandleAnalysisIcms(c *gin.Context) {
-	spedFileHeader, err := c.FormFile("spedFile")
-	if err != nil {
-		responses.Error(c, http.StatusBadRequest, "Arquivo SPED não encontrado ou inválido")
-		return
-	}
-	spedFile, err := spedFileHeader.Open()
-	if err != nil {
-		responses.Error(c, http.StatusInternalServerError, "Não foi possível abrir o arquivo SPED")
+	files, ok := h.openUploads(c)
+	if !ok {
 		return
 	}
-	defer spedFile.Close()
-
-	form, _ := c.MultipartForm()
-	xmlFileHeaders := form.File["xmlFiles"]
-	if len(xmlFileHeaders) == 0 {
-		responses.Error(c, http.StatusBadRequest, "Nenhum arquivo XML foi enviado")
-		return
-	}
-
-	var xmlReaders []io.Reader
-	for _, header := range xmlFileHeaders {
-		file, err := header.Open()
-		if err != nil {
-			responses.Error(c, http.StatusInternalServerError, "Não foi possível abrir um dos arquivos XML")
-			return
-		}
-		defer file.Close()
-		xmlReaders = append(xmlReaders, file)
-	}
+	defer files.Close()
 
 	cfopsStr := c.PostForm("cfopsIgnorados")
 	var cfopsIgnorados []string
@@ -68,59 +50,119 @@ func (h *AnalysisHandler) HandleAnalysisIcms(c *gin.Context) {
 		}
 	}
 
-	resultados, err := h.service.AnalyzeICMSFiles(spedFile, xmlReaders, cfopsIgnorados)
+	resultados, err := h.service.AnalyzeICMSFiles(files.sped, files.xmls, cfopsIgnorados)
 	if err != nil {
 		responses.Error(c, http.StatusInternalServerError, "Erro na análise de ICMS", err.Error())
 		return
 	}
+	resultados = append(resultados, skippedResults(domain.TypeICMS, files.skipped)...)
 
 	responses.Success(c, resultados, "Análise de ICMS concluída com sucesso")
 }
 
 // HandleAnalysisIpiSt handles IPI and ST analysis requests.
 func (h *AnalysisHandler) HandleAnalysisIpiSt(c *gin.Context) {
-	spedFileHeader, err := c.FormFile("spedFile")
-	if err != nil {
-		responses.Error(c, http.StatusBadRequest, "Arquivo SPED não encontrado ou inválido")
+	files, ok := h.openUploads(c)
+	if !ok {
 		return
 	}
-	spedFile, err := spedFileHeader.Open()
+	defer files.Close()
+
+	resultados, err := h.service.AnalyzeIPISTFiles(files.sped, files.xmls)
 	if err != nil {
-		responses.Error(c, http.StatusInternalServerError, "Não foi possível abrir o arquivo SPED")
+		responses.Error(c, http.StatusInternalServerError, "Erro na análise de IPI e ST", err.Error())
 		return
 	}
-	defer spedFile.Close()
+	resultados = append(resultados, skippedResults(domain.TypeIPIST, files.skipped)...)
+
+	responses.Success(c, resultados, "Análise de IPI e ST concluída com sucesso")
+}
 
+// uploads holds the files of an analysis request with the archives already expanded.
+type uploads struct {
+	sped    io.Reader
+	xmls    []domain.XMLFile
+	skipped []archive.Skipped
+	closers []io.Closer
+}
+
+// Close closes the uploaded files.
+func (u *uploads) Close() {
+	for _, closer := range u.closers {
+		closer.Close()
+	}
+}
+
+// openUploads opens the spedFile and xmlFiles parts, expanding .zip and .tar.gz archives.
+// On failure it writes the error response, closes what was opened and returns false.
+func (h *AnalysisHandler) openUploads(c *gin.Context) (*uploads, bool) {
+	spedFileHeader, err := c.FormFile("spedFile")
+	if err != nil {
+		responses.Error(c, http.StatusBadRequest, "Arquivo SPED não encontrado ou inválido")
+		return nil, false
+	}
 	form, _ := c.MultipartForm()
 	xmlFileHeaders := form.File["xmlFiles"]
 	if len(xmlFileHeaders) == 0 {
 		responses.Error(c, http.StatusBadRequest, "Nenhum arquivo XML foi enviado")
-		return
+		return nil, false
 	}
 
-	var xmlReaders []io.Reader
-	var closers []io.Closer
-	defer func() {
-		for _, closer := range closers {
-			closer.Close()
-		}
-	}()
+	files := &uploads{}
+	extractor := archive.NewExtractor(h.limits)
+	fail := func(code int, message string, errs ...string) (*uploads, bool) {
+		files.Close()
+		responses.Error(c, code, message, errs...)
+		return nil, false
+	}
+
+	spedFile, err := spedFileHeader.Open()
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Não foi possível abrir o arquivo SPED")
+	}
+	files.closers = append(files.closers, spedFile)
+	files.sped, err = extractor.SPED(spedFileHeader.Filename, spedFile, spedFileHeader.Size)
+	if err != nil {
+		return fail(archiveErrorStatus(err), "Arquivo SPED compactado inválido", err.Error())
+	}
 
 	for _, header := range xmlFileHeaders {
 		file, err := header.Open()
 		if err != nil {
-			responses.Error(c, http.StatusInternalServerError, "Não foi possível abrir um dos arquivos XML")
-			return
+			return fail(http.StatusInternalServerError, "Não foi possível abrir um dos arquivos XML")
 		}
-		xmlReaders = append(xmlReaders, file)
-		closers = append(closers, file)
+		files.closers = append(files.closers, file)
+		xmls, skipped, err := extractor.XMLs(header.Filename, file, header.Size)
+		if err != nil {
+			return fail(archiveErrorStatus(err), "Arquivo compactado de XMLs inválido", err.Error())
+		}
+		files.xmls = append(files.xmls, xmls...)
+		files.skipped = append(files.skipped, skipped...)
+	}
+	if len(files.xmls) == 0 {
+		return fail(http.StatusBadRequest, "Nenhum arquivo XML encontrado nos arquivos enviados")
 	}
+	return files, true
+}
 
-	resultados, err := h.service.AnalyzeIPISTFiles(spedFile, xmlReaders)
-	if err != nil {
-		responses.Error(c, http.StatusInternalServerError, "Erro na análise de IPI e ST", err.Error())
-		return
+// archiveErrorStatus maps an extraction error to 413 when a limit was exceeded, 400 otherwise.
+func archiveErrorStatus(err error) int {
+	if errors.Is(err, archive.ErrLimitExceeded) {
+		return http.StatusRequestEntityTooLarge
 	}
+	return http.StatusBadRequest
+}
 
-	responses.Success(c, resultados, "Análise de IPI e ST concluída com sucesso")
+// skippedResults reports the archive entries that were not analyzed.
+func skippedResults(analysisType domain.AnalysisType, skipped []archive.Skipped) []domain.AnalysisResult {
+	results := make([]domain.AnalysisResult, 0, len(skipped))
+	for _, entry := range skipped {
+		results = append(results, domain.AnalysisResult{
+			Type:       analysisType,
+			StatusCode: domain.StatusArquivoIgnorado,
+			Alerts:     []string{"Arquivo ignorado: " + entry.Reason},
+			Data:       domain.SkippedFileData{XMLFile: entry.Name},
+		})
+	}
+	return results
 }
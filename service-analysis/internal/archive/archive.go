@@ -0,0 +1,307 @@
+// internal/archive/archive.go
+
+// Package archive expands the compressed uploads accepted by the analysis endpoints: .zip
+// and .tar.gz archives of XMLs, and a zipped SPED file. Entries are extracted as streams
+// under Limits, so a zip bomb is rejected before it is decompressed.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"analysis-service/internal/domain"
+)
+
+// ErrLimitExceeded is returned when an archive exceeds one of the Limits.
+var ErrLimitExceeded = errors.New("limite de extração excedido")
+
+// ratioMinSize is the uncompressed size below which the compression ratio is not checked:
+// small, repetitive XMLs compress well beyond any sensible ratio.
+const ratioMinSize = 1 << 20
+
+// Limits bounds what an Extractor accepts across all archives of one request.
+type Limits struct {
+	// MaxEntries is the number of files accepted, XMLs or not.
+	MaxEntries int
+	// MaxTotalSize is the uncompressed size of all extracted XMLs, in bytes.
+	MaxTotalSize int64
+	// MaxRatio is the largest uncompressed/compressed ratio accepted.
+	MaxRatio float64
+}
+
+// File is an uploaded file; multipart.File satisfies it.
+type File interface {
+	io.Reader
+	io.ReaderAt
+}
+
+// Skipped is an archive entry that was not analyzed, with the reason.
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+// Extractor expands the archives of one request, accumulating the entry count and size
+// so the limits apply to the request as a whole. It is not safe for concurrent use.
+type Extractor struct {
+	limits    Limits
+	entries   int
+	totalSize int64
+}
+
+// NewExtractor creates an Extractor for one request.
+func NewExtractor(limits Limits) *Extractor {
+	return &Extractor{limits: limits}
+}
+
+// IsArchive reports whether name has an extension handled by the package.
+func IsArchive(name string) bool {
+	return isZip(name) || isTarGz(name)
+}
+
+// XMLs returns the XMLs in an uploaded file. A .zip or .tar.gz is expanded and its entries
+// are named "archive.zip/path/inside.xml"; entries that are not XMLs are returned as
+// skipped. Any other file is returned as is, as a single XML.
+//
+// ZIP entries are opened lazily when read, so they are decompressed by the analysis
+// workers; archive/zip fails any entry larger than its declared size, which is what the
+// limits are checked against. A .tar.gz can only be read sequentially, so its XMLs are read
+// into memory, bounded by MaxTotalSize.
+func (e *Extractor) XMLs(name string, file File, size int64) ([]domain.XMLFile, []Skipped, error) {
+	switch {
+	case isZip(name):
+		return e.zipXMLs(name, file, size)
+	case isTarGz(name):
+		return e.tarGzXMLs(name, file)
+	default:
+		return []domain.XMLFile{{Name: name, Reader: file}}, nil, nil
+	}
+}
+
+// SPED returns the SPED file inside an uploaded .zip, which must hold exactly one file, or
+// the upload itself otherwise. The SPED file is streamed by the analysis, so only the
+// compression ratio is limited.
+func (e *Extractor) SPED(name string, file File, size int64) (io.Reader, error) {
+	if !isZip(name) {
+		return file, nil
+	}
+	zr, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: arquivo ZIP inválido: %w", name, err)
+	}
+	var entry *zip.File
+	for _, candidate := range zr.File {
+		if candidate.FileInfo().IsDir() || ignored(candidate.Name) {
+			continue
+		}
+		if entry != nil {
+			return nil, fmt.Errorf("%s: o ZIP do SPED deve conter um único arquivo", name)
+		}
+		entry = candidate
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%s: o ZIP do SPED está vazio", name)
+	}
+	if err := e.checkRatio(name+"/"+entry.Name, entry.UncompressedSize64, entry.CompressedSize64); err != nil {
+		return nil, err
+	}
+	return &zipEntry{file: entry}, nil
+}
+
+func (e *Extractor) zipXMLs(name string, file io.ReaderAt, size int64) ([]domain.XMLFile, []Skipped, error) {
+	zr, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: arquivo ZIP inválido: %w", name, err)
+	}
+	var xmls []domain.XMLFile
+	var skipped []Skipped
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() || ignored(entry.Name) {
+			continue
+		}
+		entryName := name + "/" + path.Clean(entry.Name)
+		if err := e.countEntry(); err != nil {
+			return nil, nil, err
+		}
+		if reason, ok := skipReason(entry.Name); !ok {
+			skipped = append(skipped, Skipped{Name: entryName, Reason: reason})
+			continue
+		}
+		if err := e.checkRatio(entryName, entry.UncompressedSize64, entry.CompressedSize64); err != nil {
+			return nil, nil, err
+		}
+		if err := e.addSize(entry.UncompressedSize64); err != nil {
+			return nil, nil, err
+		}
+		xmls = append(xmls, domain.XMLFile{Name: entryName, Reader: &zipEntry{file: entry}})
+	}
+	return xmls, skipped, nil
+}
+
+func (e *Extractor) tarGzXMLs(name string, file io.Reader) ([]domain.XMLFile, []Skipped, error) {
+	compressed := &countingReader{r: file}
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: arquivo gzip inválido: %w", name, err)
+	}
+	defer gz.Close()
+	// The ratio is checked on the decompressed stream itself, so skipping a huge non-XML
+	// entry cannot inflate the archive unchecked.
+	tr := tar.NewReader(&ratioReader{r: gz, compressed: compressed, name: name, maxRatio: e.limits.MaxRatio})
+
+	var xmls []domain.XMLFile
+	var skipped []Skipped
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, wrapArchiveError(name, err)
+		}
+		if header.Typeflag != tar.TypeReg || ignored(header.Name) {
+			continue
+		}
+		entryName := name + "/" + path.Clean(header.Name)
+		if err := e.countEntry(); err != nil {
+			return nil, nil, err
+		}
+		if reason, ok := skipReason(header.Name); !ok {
+			skipped = append(skipped, Skipped{Name: entryName, Reason: reason})
+			continue
+		}
+		if header.Size < 0 {
+			return nil, nil, fmt.Errorf("%s: tamanho inválido", entryName)
+		}
+		if err := e.addSize(uint64(header.Size)); err != nil {
+			return nil, nil, err
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, wrapArchiveError(entryName, err)
+		}
+		xmls = append(xmls, domain.XMLFile{Name: entryName, Reader: bytes.NewReader(content)})
+	}
+	return xmls, skipped, nil
+}
+
+func (e *Extractor) countEntry() error {
+	e.entries++
+	if e.entries > e.limits.MaxEntries {
+		return fmt.Errorf("%w: mais de %d arquivos nos arquivos compactados", ErrLimitExceeded, e.limits.MaxEntries)
+	}
+	return nil
+}
+
+func (e *Extractor) addSize(size uint64) error {
+	if size > uint64(e.limits.MaxTotalSize-e.totalSize) {
+		return fmt.Errorf("%w: os XMLs descompactados ultrapassam %d MB", ErrLimitExceeded, e.limits.MaxTotalSize>>20)
+	}
+	e.totalSize += int64(size)
+	return nil
+}
+
+func (e *Extractor) checkRatio(name string, uncompressed, compressed uint64) error {
+	if exceedsRatio(uncompressed, compressed, e.limits.MaxRatio) {
+		return fmt.Errorf("%w: %s tem taxa de compressão acima de %.0f:1", ErrLimitExceeded, name, e.limits.MaxRatio)
+	}
+	return nil
+}
+
+func exceedsRatio(uncompressed, compressed uint64, maxRatio float64) bool {
+	return uncompressed > ratioMinSize && float64(uncompressed) > float64(compressed)*maxRatio
+}
+
+// wrapArchiveError keeps limit errors recognizable and reports the rest as a corrupt archive.
+func wrapArchiveError(name string, err error) error {
+	if errors.Is(err, ErrLimitExceeded) {
+		return err
+	}
+	return fmt.Errorf("%s: arquivo compactado inválido: %w", name, err)
+}
+
+func isZip(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".zip")
+}
+
+func isTarGz(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// skipReason reports whether an entry is an XML and, when it is not, why it is skipped.
+func skipReason(name string) (string, bool) {
+	switch {
+	case strings.EqualFold(path.Ext(name), ".xml"):
+		return "", true
+	case IsArchive(name):
+		return "arquivos compactados dentro de outro arquivo compactado não são suportados", false
+	default:
+		return "o arquivo não é um XML", false
+	}
+}
+
+// ignored reports entries added by archivers rather than by the user, such as the
+// resource forks macOS stores under __MACOSX/ and as "._name" files.
+func ignored(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), "._")
+}
+
+// zipEntry opens a ZIP entry on the first Read and closes it at the end or on error.
+type zipEntry struct {
+	file *zip.File
+	rc   io.ReadCloser
+	err  error
+}
+
+func (z *zipEntry) Read(p []byte) (int, error) {
+	if z.rc == nil && z.err == nil {
+		z.rc, z.err = z.file.Open()
+	}
+	if z.err != nil {
+		return 0, z.err
+	}
+	n, err := z.rc.Read(p)
+	if err != nil {
+		z.rc.Close()
+		z.err = err
+	}
+	return n, err
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ratioReader fails once more than maxRatio times the compressed bytes have come out of r.
+type ratioReader struct {
+	r          io.Reader
+	compressed *countingReader
+	name       string
+	maxRatio   float64
+	n          int64
+}
+
+func (r *ratioReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if exceedsRatio(uint64(r.n), uint64(r.compressed.n), r.maxRatio) {
+		return n, fmt.Errorf("%w: %s tem taxa de compressão acima de %.0f:1", ErrLimitExceeded, r.name, r.maxRatio)
+	}
+	return n, err
+}
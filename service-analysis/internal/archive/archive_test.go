@@ -0,0 +1,232 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name    string
+	content string
+}
+
+// zipFile builds a .zip with the entries in order; a name ending in "/" is a directory.
+func zipFile(t *testing.T, entries ...entry) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, e.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// tarGzFile builds a .tar.gz with the entries in order; a name ending in "/" is a directory.
+func tarGzFile(t *testing.T, entries ...entry) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(e.name, "/") {
+			header.Typeflag, header.Size, header.Mode = tar.TypeDir, 0, 0o755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, e.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// archiveFile builds name as a .zip or .tar.gz, depending on its extension.
+func archiveFile(t *testing.T, name string, entries ...entry) *bytes.Reader {
+	if isZip(name) {
+		return zipFile(t, entries...)
+	}
+	return tarGzFile(t, entries...)
+}
+
+var generous = Limits{MaxEntries: 100, MaxTotalSize: 100 << 20, MaxRatio: 100}
+
+func TestXMLsExpandsArchives(t *testing.T) {
+	entries := []entry{
+		{"notas/", ""},
+		{"notas/nfe1.xml", "<nfe>1</nfe>"},
+		{"notas/./NFE2.XML", "<nfe>2</nfe>"},
+		{"leia-me.txt", "texto"},
+		{"outras.zip", "PK"},
+		{"__MACOSX/notas/._nfe1.xml", "recurso"},
+		{"notas/._nfe2.xml", "recurso"},
+	}
+	for _, name := range []string{"lote.zip", "lote.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			file := archiveFile(t, name, entries...)
+			xmls, skipped, err := NewExtractor(generous).XMLs(name, file, file.Size())
+			if err != nil {
+				t.Fatalf("XMLs: %v", err)
+			}
+			var names, contents []string
+			for _, xml := range xmls {
+				content, err := io.ReadAll(xml.Reader)
+				if err != nil {
+					t.Fatalf("%s: %v", xml.Name, err)
+				}
+				names = append(names, xml.Name)
+				contents = append(contents, string(content))
+			}
+			if !slices.Equal(names, []string{name + "/notas/nfe1.xml", name + "/notas/NFE2.XML"}) ||
+				!slices.Equal(contents, []string{"<nfe>1</nfe>", "<nfe>2</nfe>"}) {
+				t.Errorf("XMLs = %v %v", names, contents)
+			}
+			want := []Skipped{
+				{Name: name + "/leia-me.txt", Reason: "o arquivo não é um XML"},
+				{Name: name + "/outras.zip", Reason: "arquivos compactados dentro de outro arquivo compactado não são suportados"},
+			}
+			if !slices.Equal(skipped, want) {
+				t.Errorf("ignorados = %v, esperado %v", skipped, want)
+			}
+		})
+	}
+}
+
+func TestXMLsPassesOtherFilesThrough(t *testing.T) {
+	file := bytes.NewReader([]byte("<nfe/>"))
+	xmls, skipped, err := NewExtractor(Limits{}).XMLs("nota.xml", file, file.Size())
+	if err != nil || len(skipped) != 0 || len(xmls) != 1 || xmls[0].Name != "nota.xml" || xmls[0].Reader != file {
+		t.Errorf("XMLs = %v, %v, %v; esperado o próprio arquivo", xmls, skipped, err)
+	}
+}
+
+func TestXMLsLimits(t *testing.T) {
+	// zeros compresses about 1000:1 and is above ratioMinSize, so only the ratio rejects it.
+	zeros := strings.Repeat("0", 2<<20)
+	tests := []struct {
+		name    string
+		limits  Limits
+		entries []entry
+	}{
+		{
+			name:    "entradas",
+			limits:  Limits{MaxEntries: 2, MaxTotalSize: 1 << 20, MaxRatio: 100},
+			entries: []entry{{"a.xml", "<a/>"}, {"b.txt", "b"}, {"c.xml", "<c/>"}},
+		},
+		{
+			name:    "tamanho total",
+			limits:  Limits{MaxEntries: 10, MaxTotalSize: 10, MaxRatio: 100},
+			entries: []entry{{"a.xml", "<a/>"}, {"b.xml", "<nfe>12345</nfe>"}},
+		},
+		{
+			name:    "taxa de compressão",
+			limits:  Limits{MaxEntries: 10, MaxTotalSize: 100 << 20, MaxRatio: 100},
+			entries: []entry{{"a.xml", zeros}},
+		},
+	}
+	for _, tt := range tests {
+		for _, name := range []string{"lote.zip", "lote.tar.gz"} {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				file := archiveFile(t, name, tt.entries...)
+				_, _, err := NewExtractor(tt.limits).XMLs(name, file, file.Size())
+				if !errors.Is(err, ErrLimitExceeded) {
+					t.Errorf("XMLs = %v, esperado %v", err, ErrLimitExceeded)
+				}
+			})
+		}
+	}
+}
+
+func TestXMLsLimitsApplyToTheWholeRequest(t *testing.T) {
+	extractor := NewExtractor(Limits{MaxEntries: 3, MaxTotalSize: 1 << 20, MaxRatio: 100})
+	first := zipFile(t, entry{"a.xml", "<a/>"}, entry{"b.xml", "<b/>"})
+	if _, _, err := extractor.XMLs("um.zip", first, first.Size()); err != nil {
+		t.Fatalf("primeiro arquivo: %v", err)
+	}
+	second := tarGzFile(t, entry{"c.xml", "<c/>"}, entry{"d.xml", "<d/>"})
+	if _, _, err := extractor.XMLs("dois.tar.gz", second, second.Size()); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("segundo arquivo = %v, esperado %v pela soma das entradas", err, ErrLimitExceeded)
+	}
+}
+
+func TestTarGzRatioChecksSkippedEntries(t *testing.T) {
+	// A skipped entry is never extracted, but its bytes still pass through the decompressor;
+	// the ratioReader stops the stream instead of inflating it unchecked.
+	file := tarGzFile(t, entry{"bomba.txt", strings.Repeat("0", 8<<20)}, entry{"a.xml", "<a/>"})
+	_, _, err := NewExtractor(generous).XMLs("lote.tgz", file, file.Size())
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("XMLs = %v, esperado %v", err, ErrLimitExceeded)
+	}
+
+	// Below ratioMinSize, repetitive content is accepted whatever its ratio.
+	file = tarGzFile(t, entry{"a.xml", strings.Repeat("0", ratioMinSize/2)})
+	if _, _, err := NewExtractor(generous).XMLs("lote.tgz", file, file.Size()); err != nil {
+		t.Errorf("XMLs abaixo de ratioMinSize = %v", err)
+	}
+}
+
+func TestXMLsRejectsCorruptArchives(t *testing.T) {
+	for _, name := range []string{"lote.zip", "lote.tar.gz"} {
+		file := bytes.NewReader([]byte("isto não é um arquivo compactado"))
+		_, _, err := NewExtractor(generous).XMLs(name, file, file.Size())
+		if err == nil || errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: XMLs = %v, esperado erro de arquivo inválido", name, err)
+		}
+	}
+}
+
+func TestSPED(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    string
+		limit   bool
+	}{
+		{"um arquivo", []entry{{"__MACOSX/._sped.txt", "x"}, {"sped.txt", "|0000|"}}, "|0000|", false},
+		{"dois arquivos", []entry{{"a.txt", "|0000|"}, {"b.txt", "|0000|"}}, "", false},
+		{"vazio", []entry{{"pasta/", ""}}, "", false},
+		{"taxa de compressão", []entry{{"sped.txt", strings.Repeat("0", 2<<20)}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := zipFile(t, tt.entries...)
+			r, err := NewExtractor(generous).SPED("sped.zip", file, file.Size())
+			if tt.want == "" {
+				if err == nil || errors.Is(err, ErrLimitExceeded) != tt.limit {
+					t.Errorf("SPED = %v, esperado erro (limite: %v)", err, tt.limit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SPED: %v", err)
+			}
+			content, err := io.ReadAll(r)
+			if err != nil || string(content) != tt.want {
+				t.Errorf("SPED = %q, %v; esperado %q", content, err, tt.want)
+			}
+		})
+	}
+}
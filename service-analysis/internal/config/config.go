@@ -0,0 +1,83 @@
+// internal/config/config.go
+
+// Package config holds the typed configuration of the analysis service, loaded from
+// defaults, an optional YAML file, environment variables (including .env) and flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"shared/configloader"
+)
+
+// Config holds all analysis service settings.
+type Config struct {
+	Port int `yaml:"port" env:"PORT" flag:"port" default:"8082" usage:"HTTP port of the service"`
+	// MaxUploadMB caps the size of each request body (SPED plus XML files); zero, the default,
+	// leaves it unlimited so that large uploads are bounded only by the gateway.
+	MaxUploadMB int64 `yaml:"max_upload_mb" env:"MAX_UPLOAD_MB" flag:"max-upload-mb" default:"0" usage:"maximum size of each request in MB (0 for no limit)"`
+	// MultipartMemoryMB is how much of each upload is kept in memory; the rest of the files
+	// is spooled to temporary files and streamed from disk during the analysis.
+	MultipartMemoryMB int64 `yaml:"multipart_memory_mb" env:"MULTIPART_MEMORY_MB" default:"8"`
+
+	// XMLWorkers is how many XMLs each request parses concurrently; zero uses every CPU.
+	XMLWorkers int `yaml:"xml_workers" env:"ANALYSIS_XML_WORKERS" flag:"xml-workers" default:"0" usage:"XMLs parsed concurrently per request (0 uses every CPU)"`
+
+	Tolerances Tolerances `yaml:"tolerances"`
+	Archives   Archives   `yaml:"archives"`
+}
+
+// Tolerances are the monetary differences accepted by the analyses before a document is
+// reported, in reais.
+type Tolerances struct {
+	// Value applies to XML vs SPED IPI/ST totals; SPED values up to it are treated as zero.
+	Value float64 `yaml:"value" env:"ANALYSIS_VALUE_TOLERANCE" default:"0.01"`
+	// ItemSum applies to the C100 totals vs the sum of their C170 items.
+	ItemSum float64 `yaml:"item_sum" env:"ANALYSIS_ITEM_SUM_TOLERANCE" default:"0.5"`
+	// ICMS applies to XML vs SPED ICMS; zero requires the rounded values to match.
+	ICMS float64 `yaml:"icms" env:"ANALYSIS_ICMS_TOLERANCE" default:"0"`
+}
+
+// Archives limits the extraction of .zip and .tar.gz uploads, guarding against zip bombs.
+// The limits apply to all archives of one request together.
+type Archives struct {
+	MaxEntries int `yaml:"max_entries" env:"ARCHIVE_MAX_ENTRIES" default:"20000"`
+	// MaxTotalMB is the uncompressed size of all XMLs extracted, in MB.
+	MaxTotalMB int64 `yaml:"max_total_mb" env:"ARCHIVE_MAX_TOTAL_MB" default:"1024"`
+	// MaxRatio is the largest compression ratio accepted for entries over 1 MB.
+	MaxRatio float64 `yaml:"max_ratio" env:"ARCHIVE_MAX_RATIO" default:"100"`
+}
+
+// Load reads the configuration; args are the command line arguments (nil skips flags).
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	if err := configloader.Load(cfg, "analysis", args); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the loaded values.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT inválido: %d", c.Port))
+	}
+	if c.MaxUploadMB < 0 {
+		errs = append(errs, fmt.Errorf("MAX_UPLOAD_MB não pode ser negativo: %d", c.MaxUploadMB))
+	}
+	if c.MultipartMemoryMB <= 0 {
+		errs = append(errs, fmt.Errorf("MULTIPART_MEMORY_MB deve ser positivo: %d", c.MultipartMemoryMB))
+	}
+	if c.XMLWorkers < 0 {
+		errs = append(errs, fmt.Errorf("ANALYSIS_XML_WORKERS não pode ser negativo: %d", c.XMLWorkers))
+	}
+	if c.Archives.MaxEntries <= 0 || c.Archives.MaxTotalMB <= 0 || c.Archives.MaxRatio <= 0 {
+		errs = append(errs, errors.New("os limites de extração de arquivos compactados devem ser positivos"))
+	}
+	if c.Tolerances.Value < 0 || c.Tolerances.ItemSum < 0 || c.Tolerances.ICMS < 0 {
+		errs = append(errs, errors.New("as tolerâncias da análise não podem ser negativas"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,102 @@
+// internal/core/analysis/document.go
+package analysis
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"analysis-service/internal/domain"
+)
+
+// fiscalDocument is an NF-e, NFC-e or CT-e read from an XML, with or without its
+// authorization protocol. Exactly one of nfe and cte is set.
+type fiscalDocument struct {
+	docType domain.DocumentType
+	// key is the access key from the protocol or, without one, from the Id attribute.
+	key        string
+	number     string
+	authorized bool
+	nfe        *domain.NFeXML
+	cte        *domain.CTeXML
+}
+
+// decodeFiscalDocument classifies an XML by its root element (nfeProc, NFe, cteProc or
+// CTe) and decodes it. NF-e and NFC-e share the layout and are told apart by ide/mod.
+func decodeFiscalDocument(data []byte) (*fiscalDocument, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root, err := rootElement(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &fiscalDocument{}
+	switch root.Name.Local {
+	case "nfeProc":
+		var proc domain.NFeProc
+		if err := decoder.DecodeElement(&proc, &root); err != nil {
+			return nil, err
+		}
+		doc.nfe = &proc.NFe
+		doc.key = proc.ProtNFe.InfProt.ChNFe
+	case "NFe":
+		doc.nfe = &domain.NFeXML{}
+		if err := decoder.DecodeElement(doc.nfe, &root); err != nil {
+			return nil, err
+		}
+	case "cteProc":
+		var proc domain.CTeProc
+		if err := decoder.DecodeElement(&proc, &root); err != nil {
+			return nil, err
+		}
+		doc.cte = &proc.CTe
+		doc.key = proc.ProtCTe.InfProt.ChCTe
+	case "CTe":
+		doc.cte = &domain.CTeXML{}
+		if err := decoder.DecodeElement(doc.cte, &root); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("documento não suportado: <%s> (esperado NF-e, NFC-e ou CT-e)", root.Name.Local)
+	}
+	doc.authorized = doc.key != ""
+
+	if doc.nfe != nil {
+		infNFe := doc.nfe.InfNFe
+		doc.docType = domain.DocumentNFe
+		if infNFe.Ide.Mod == "65" {
+			doc.docType = domain.DocumentNFCe
+		}
+		doc.number = infNFe.Ide.NNF
+		if doc.key == "" {
+			doc.key = strings.TrimPrefix(infNFe.ID, "NFe")
+		}
+	} else {
+		infCte := doc.cte.InfCte
+		doc.docType = domain.DocumentCTe
+		doc.number = infCte.Ide.NCT
+		if doc.key == "" {
+			doc.key = strings.TrimPrefix(infCte.ID, "CTe")
+		}
+	}
+	return doc, nil
+}
+
+// rootElement returns the first start element, skipping the XML declaration and comments.
+func rootElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return xml.StartElement{}, errors.New("XML vazio")
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"analysis-service/internal/domain"
+)
+
+const (
+	nfeKey  = "35240112345678000199550010000001231000001234"
+	nfceKey = "35240112345678000199650010000004561000004567"
+	cteKey  = "35240198765432000110570010000007891000007890"
+)
+
+// nfeXML builds an NF-e or NFC-e of model mod, wrapped in nfeProc with its protocol when
+// authorized.
+func nfeXML(mod, key string, authorized bool) string {
+	nfe := fmt.Sprintf(`<NFe xmlns="http://www.portalfiscal.inf.br/nfe"><infNFe Id="NFe%s" versao="4.00">`+
+		`<ide><mod>%s</mod><nNF>123</nNF></ide>`+
+		`<det nItem="1"><imposto><ICMS><ICMS00><vICMS>90.00</vICMS></ICMS00></ICMS></imposto></det>`+
+		`</infNFe></NFe>`, key, mod)
+	if !authorized {
+		return `<?xml version="1.0" encoding="UTF-8"?>` + nfe
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?><nfeProc xmlns="http://www.portalfiscal.inf.br/nfe" versao="4.00">` + nfe +
+		`<protNFe><infProt><chNFe>` + key + `</chNFe></infProt></protNFe></nfeProc>`
+}
+
+// cteXML builds a CT-e with the ICMS group icms, wrapped in cteProc with its protocol when
+// authorized.
+func cteXML(icms string, authorized bool) string {
+	cte := `<CTe xmlns="http://www.portalfiscal.inf.br/cte"><infCte Id="CTe` + cteKey + `" versao="4.00">` +
+		`<ide><mod>57</mod><nCT>789</nCT></ide><imp><ICMS>` + icms + `</ICMS></imp></infCte></CTe>`
+	if !authorized {
+		return cte
+	}
+	return `<cteProc xmlns="http://www.portalfiscal.inf.br/cte" versao="4.00">` + cte +
+		`<protCTe><infProt><chCTe>` + cteKey + `</chCTe></infProt></protCTe></cteProc>`
+}
+
+func TestDecodeFiscalDocument(t *testing.T) {
+	icms00 := `<ICMS00><CST>00</CST><vICMS>36.00</vICMS></ICMS00>`
+	tests := []struct {
+		name       string
+		xml        string
+		docType    domain.DocumentType
+		key        string
+		number     string
+		authorized bool
+	}{
+		{"nfeProc", nfeXML("55", nfeKey, true), domain.DocumentNFe, nfeKey, "123", true},
+		{"NFe sem protocolo", nfeXML("55", nfeKey, false), domain.DocumentNFe, nfeKey, "123", false},
+		{"NFC-e", nfeXML("65", nfceKey, true), domain.DocumentNFCe, nfceKey, "123", true},
+		{"NFC-e sem protocolo", nfeXML("65", nfceKey, false), domain.DocumentNFCe, nfceKey, "123", false},
+		{"cteProc", cteXML(icms00, true), domain.DocumentCTe, cteKey, "789", true},
+		{"CTe sem protocolo", cteXML(icms00, false), domain.DocumentCTe, cteKey, "789", false},
+		{"comentário antes da raiz", "<!-- exportado -->" + cteXML(icms00, false), domain.DocumentCTe, cteKey, "789", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := decodeFiscalDocument([]byte(tt.xml))
+			if err != nil {
+				t.Fatalf("decodeFiscalDocument: %v", err)
+			}
+			if doc.docType != tt.docType || doc.key != tt.key || doc.number != tt.number || doc.authorized != tt.authorized {
+				t.Errorf("documento = %s %s nº %s autorizado=%v; esperado %s %s nº %s autorizado=%v",
+					doc.docType, doc.key, doc.number, doc.authorized, tt.docType, tt.key, tt.number, tt.authorized)
+			}
+			if (doc.nfe != nil) == (doc.cte != nil) {
+				t.Errorf("nfe = %v, cte = %v; esperado exatamente um", doc.nfe, doc.cte)
+			}
+		})
+	}
+}
+
+func TestDecodeFiscalDocumentRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want string
+	}{
+		{"raiz desconhecida", `<nfse><numero>1</numero></nfse>`, "documento não suportado: <nfse>"},
+		{"vazio", `<?xml version="1.0"?>`, "XML vazio"},
+		{"malformado", `<nfeProc><NFe>`, "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeFiscalDocument([]byte(tt.xml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("decodeFiscalDocument = %v, esperado erro com %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCTeICMS(t *testing.T) {
+	tests := []struct {
+		name string
+		icms string
+		want float64
+	}{
+		{"ICMS00", `<ICMS00><vICMS>36.00</vICMS></ICMS00>`, 36},
+		{"ICMS20", `<ICMS20><vICMS>24.50</vICMS></ICMS20>`, 24.5},
+		{"ICMS90", `<ICMS90><vICMS>12.00</vICMS></ICMS90>`, 12},
+		{"ICMSOutraUF", `<ICMSOutraUF><vICMSOutraUF>7.20</vICMSOutraUF></ICMSOutraUF>`, 7.2},
+		// ICMS retained by substitution is owed by the service taker, not declared in D190.
+		{"ICMS60", `<ICMS60><vICMSSTRet>36.00</vICMSSTRet></ICMS60>`, 0},
+		{"ICMS45", `<ICMS45><CST>40</CST></ICMS45>`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := decodeFiscalDocument([]byte(cteXML(tt.icms, true)))
+			if err != nil {
+				t.Fatalf("decodeFiscalDocument: %v", err)
+			}
+			if got := cteICMS(doc.cte.InfCte.Imp.ICMS); got != tt.want {
+				t.Errorf("cteICMS = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeICMSMatchesCTeWithD100(t *testing.T) {
+	// The CT-e is matched against the D100 of its key and compared with the sum of its D190;
+	// the NF-e in the same upload is matched against C100/C190.
+	d100 := func(key string, d190 ...string) []string {
+		lines := []string{"|D100|0|1|T001|57|00|1||789|" + key + "|10012024|11012024|0||300,00|0|1|300,00|300,00|36,00|0|||3550308|3304557|"}
+		return append(lines, d190...)
+	}
+	spedFile := func(d100Lines []string) string {
+		lines := []string{
+			"|0000|017|0|01012024|31012024|EMPRESA LTDA|12345678000199||SP|123456789|3550308|||A|1|",
+			"|C001|0|",
+			"|C100|1|0|C001|55|00|1|123|" + nfeKey + "|05012024|05012024|1000,00|",
+			"|C190|000|5102|18,00|1000,00|1000,00|90,00|",
+			"|C990|4|",
+			"|D001|0|",
+		}
+		lines = append(lines, d100Lines...)
+		lines = append(lines, "|D990|4|", "|9999|12|")
+		return strings.Join(lines, "\r\n") + "\r\n"
+	}
+	icms00 := `<ICMS00><vICMS>36.00</vICMS></ICMS00>`
+
+	tests := []struct {
+		name    string
+		sped    string
+		cte     string
+		status  domain.StatusCode
+		alerts  []string
+		icmsXML float64
+	}{
+		{
+			name: "D190 somados conferem",
+			sped: spedFile(d100(cteKey, "|D190|000|1352|12,00|200,00|200,00|24,00|0||", "|D190|000|2352|12,00|100,00|100,00|12,00|0||")),
+			cte:  cteXML(icms00, true),
+		},
+		{
+			name:    "divergência com o D190",
+			sped:    spedFile(d100(cteKey, "|D190|000|1352|12,00|300,00|300,00|30,00|0||")),
+			cte:     cteXML(icms00, false),
+			status:  domain.StatusDiscrepanciaICMS,
+			alerts:  []string{"Discrepância detectada: ICMS XML=36.00, SPED=30.00", alertSemProtocolo},
+			icmsXML: 36,
+		},
+		{
+			name:    "D100 de outra chave",
+			sped:    spedFile(d100("35240198765432000110570010000009991000009990", "|D190|000|1352|12,00|300,00|300,00|36,00|0||")),
+			cte:     cteXML(icms00, true),
+			status:  domain.StatusNaoEncontradaSPED,
+			alerts:  []string{"CT-e não encontrado no SPED (D100)"},
+			icmsXML: 36,
+		},
+		{
+			name: "ICMS60 fora do D190",
+			sped: spedFile(d100(cteKey)),
+			cte:  cteXML(`<ICMS60><vICMSSTRet>36.00</vICMSSTRet></ICMS60>`, true),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xmlFiles := []domain.XMLFile{
+				{Name: "nfe.xml", Reader: strings.NewReader(nfeXML("55", nfeKey, true))},
+				{Name: "cte.xml", Reader: strings.NewReader(tt.cte)},
+			}
+			results, err := NewService(Config{}).AnalyzeICMSFiles(strings.NewReader(tt.sped), xmlFiles, nil)
+			if err != nil {
+				t.Fatalf("AnalyzeICMSFiles: %v", err)
+			}
+			if tt.status == domain.StatusOK {
+				if len(results) != 0 {
+					t.Errorf("resultados = %+v, esperado nenhum", results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("resultados = %+v, esperado só o do CT-e", results)
+			}
+			result := results[0]
+			data, _ := result.Data.(domain.ICMSData)
+			if result.DocumentType != domain.DocumentCTe || result.NFeKey != cteKey || result.StatusCode != tt.status ||
+				strings.Join(result.Alerts, "|") != strings.Join(tt.alerts, "|") || data.XMLFile != "cte.xml" || data.IcmsXML != tt.icmsXML {
+				t.Errorf("resultado = %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// internal/core/analysis/parallel.go
+package analysis
+
+import (
+	"io"
+	"sync"
+
+	"analysis-service/internal/domain"
+)
+
+// parseConcurrently runs parse on every file with at most workers goroutines. Results and
+// errors are indexed like files, so the outcome does not depend on which worker finishes
+// first and each error stays attached to its file. A workers value below one runs a single
+// worker.
+func parseConcurrently[T any](files []domain.XMLFile, workers int, parse func(io.Reader) (T, error)) ([]T, []error) {
+	results := make([]T, len(files))
+	errs := make([]error, len(files))
+	workers = max(1, min(workers, len(files)))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = parse(files[i].Reader)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results, errs
+}
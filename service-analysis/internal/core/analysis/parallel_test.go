@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"analysis-service/internal/domain"
+)
+
+func TestParseConcurrently(t *testing.T) {
+	// Each file holds its index; parsing sleeps longer for the first files so workers finish
+	// out of order, and every third file fails with an error naming its index.
+	parse := func(r io.Reader) (int, error) {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return 0, err
+		}
+		i, err := strconv.Atoi(string(content))
+		if err != nil {
+			return 0, err
+		}
+		time.Sleep(time.Duration(20-i%20) * 100 * time.Microsecond)
+		if i%3 == 0 {
+			return 0, fmt.Errorf("arquivo %d inválido", i)
+		}
+		return i * 10, nil
+	}
+
+	for _, workers := range []int{-1, 0, 1, 4, 100} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			files := make([]domain.XMLFile, 40)
+			for i := range files {
+				files[i] = domain.XMLFile{Name: fmt.Sprintf("nfe-%d.xml", i), Reader: strings.NewReader(strconv.Itoa(i))}
+			}
+			results, errs := parseConcurrently(files, workers, parse)
+			if len(results) != len(files) || len(errs) != len(files) {
+				t.Fatalf("%d resultados e %d erros, esperado %d", len(results), len(errs), len(files))
+			}
+			for i := range files {
+				if i%3 == 0 {
+					if errs[i] == nil || errs[i].Error() != fmt.Sprintf("arquivo %d inválido", i) {
+						t.Errorf("erro do arquivo %d = %v", i, errs[i])
+					}
+					continue
+				}
+				if errs[i] != nil || results[i] != i*10 {
+					t.Errorf("arquivo %d = %d, %v; esperado %d", i, results[i], errs[i], i*10)
+				}
+			}
+		})
+	}
+
+	t.Run("sem arquivos", func(t *testing.T) {
+		results, errs := parseConcurrently(nil, 0, func(io.Reader) (int, error) { return 0, errors.New("não chamado") })
+		if len(results) != 0 || len(errs) != 0 {
+			t.Errorf("resultados = %v, erros = %v", results, errs)
+		}
+	})
+}
+
+// BenchmarkParseConcurrently parses the same batch of NF-e with one worker, the previous
+// sequential path, and with GOMAXPROCS workers, the default of NewService. Use -cpu to
+// compare several GOMAXPROCS values.
+func BenchmarkParseConcurrently(b *testing.B) {
+	fixture, err := os.ReadFile("testdata/nfe.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	const batch = 1000
+
+	for _, workers := range slices.Compact([]int{1, runtime.GOMAXPROCS(0)}) {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(fixture) * batch))
+			for b.Loop() {
+				files := make([]domain.XMLFile, batch)
+				for i := range files {
+					files[i] = domain.XMLFile{Name: fmt.Sprintf("nfe-%d.xml", i), Reader: bytes.NewReader(fixture)}
+				}
+				_, errs := parseConcurrently(files, workers, parseXMLForICMS)
+				for _, err := range errs {
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -2,48 +2,68 @@
 package analysis
 
 import (
-	"bufio"
-	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"runtime"
+	"slices"
 	"strconv"
-	"strings"
 
 	"analysis-service/internal/domain"
-
-	"golang.org/x/text/encoding/charmap"
+	"analysis-service/internal/sped"
 )
 
-const EPSILON = 0.01
-
 // Service defines the interface for SPED file analysis services.
 type Service interface {
-	AnalyzeICMSFiles(spedFile io.Reader, xmlFiles []io.Reader, cfopsToIgnore []string) ([]domain.AnalysisResult, error)
-	AnalyzeIPISTFiles(spedFile io.Reader, xmlFiles []io.Reader) ([]domain.AnalysisResult, error)
+	AnalyzeICMSFiles(spedFile io.Reader, xmlFiles []domain.XMLFile, cfopsToIgnore []string) ([]domain.AnalysisResult, error)
+	AnalyzeIPISTFiles(spedFile io.Reader, xmlFiles []domain.XMLFile) ([]domain.AnalysisResult, error)
 }
 
-type service struct{}
+// Config holds the tolerances, in reais, used when comparing XML and SPED values.
+type Config struct {
+	// ValueTolerance is the accepted XML vs SPED difference in IPI/ST totals; SPED values up
+	// to it are treated as zero when choosing between the C100, C170 and C190 records.
+	ValueTolerance float64
+	// ItemSumTolerance is the accepted difference between C100 totals and the sum of its C170 items.
+	ItemSumTolerance float64
+	// ICMSTolerance is the accepted XML vs SPED ICMS difference; zero requires an exact match.
+	ICMSTolerance float64
+	// XMLWorkers is how many XMLs are parsed concurrently; zero uses GOMAXPROCS.
+	XMLWorkers int
+}
+
+type service struct {
+	config Config
+}
 
 // NewService creates a new analysis service.
-func NewService() Service {
-	return &service{}
+func NewService(config Config) Service {
+	if config.XMLWorkers <= 0 {
+		config.XMLWorkers = runtime.GOMAXPROCS(0)
+	}
+	return &service{config: config}
 }
 
 // AnalyzeIPISTFiles analyzes IPI and ST from SPED and XML files.
-func (s *service) AnalyzeIPISTFiles(spedFile io.Reader, xmlFiles []io.Reader) ([]domain.AnalysisResult, error) {
-	xmlDataMap, err := s.parseXMLsForIPIST(xmlFiles)
+func (s *service) AnalyzeIPISTFiles(spedFile io.Reader, xmlFiles []domain.XMLFile) ([]domain.AnalysisResult, error) {
+	xmlDataMap, nfeKeys, err := s.parseXMLsForIPIST(xmlFiles)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao processar arquivos XML: %w", err)
 	}
 
-	spedDataMap, err := s.parseSpedForIPIST(spedFile)
+	wanted := make(map[string]bool, len(xmlDataMap))
+	for nfeKey := range xmlDataMap {
+		wanted[nfeKey] = true
+	}
+	spedDataMap, err := s.parseSpedForIPIST(spedFile, wanted)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao processar arquivo SPED: %w", err)
 	}
 
 	var finalResults []domain.AnalysisResult
-	for nfeKey, xmlData := range xmlDataMap {
+	for _, nfeKey := range nfeKeys {
+		xmlData := xmlDataMap[nfeKey]
 		spedData, foundInSped := spedDataMap[nfeKey]
 		if !foundInSped {
 			continue
@@ -55,24 +75,29 @@ func (s *service) AnalyzeIPISTFiles(spedFile io.Reader, xmlFiles []io.Reader) ([
 		var statusCode domain.StatusCode = domain.StatusOK
 		var alerts []string = spedData.Alerts
 
-		if math.Abs(stDifference) > EPSILON || math.Abs(ipiDifference) > EPSILON {
+		if math.Abs(stDifference) > s.config.ValueTolerance || math.Abs(ipiDifference) > s.config.ValueTolerance {
 			statusCode = domain.StatusDiscrepanciaIPIST
 			alerts = append(alerts, "Discrepância detectada nos valores de IPI/ST")
 		}
 
 		if statusCode != domain.StatusOK {
+			if !xmlData.Authorized {
+				alerts = append(alerts, alertSemProtocolo)
+			}
 			data := domain.IPISTData{
+				XMLFile:      xmlData.XMLFile,
 				STValueXML:   xmlData.STValue,
 				IPIValueXML:  xmlData.IPIValue,
 				STValueSPED:  spedData.STValueSPED,
 				IPIValueSPED: spedData.IPIValueSPED,
 			}
 			result := domain.AnalysisResult{
-				Type:       domain.TypeIPIST,
-				NFeKey:     nfeKey,
-				StatusCode: statusCode,
-				Alerts:     alerts,
-				Data:       data,
+				Type:         domain.TypeIPIST,
+				DocumentType: xmlData.DocumentType,
+				NFeKey:       nfeKey,
+				StatusCode:   statusCode,
+				Alerts:       alerts,
+				Data:         data,
 			}
 			finalResults = append(finalResults, result)
 		}
@@ -81,31 +106,57 @@ func (s *service) AnalyzeIPISTFiles(spedFile io.Reader, xmlFiles []io.Reader) ([
 	return finalResults, nil
 }
 
-// parseXMLsForIPIST parses XML files for IPI and ST data.
-func (s *service) parseXMLsForIPIST(xmlFiles []io.Reader) (map[string]domain.XMLTaxData, error) {
-	xmlDataMap := make(map[string]domain.XMLTaxData)
+// parseXMLsForIPIST parses XML files for IPI and ST data. Unreadable files and CT-e, which
+// carry neither tax, are skipped. The keys are returned in upload order; a key repeated in
+// a later file takes its values.
+func (s *service) parseXMLsForIPIST(xmlFiles []domain.XMLFile) (map[string]domain.XMLTaxData, []string, error) {
+	parsed, errs := parseConcurrently(xmlFiles, s.config.XMLWorkers, parseXMLForIPIST)
 
-	for _, xmlFile := range xmlFiles {
-		bytes, err := io.ReadAll(xmlFile)
-		if err != nil {
+	xmlDataMap := make(map[string]domain.XMLTaxData)
+	var nfeKeys []string
+	for i, data := range parsed {
+		if errs[i] != nil || data.taxes.DocumentType == domain.DocumentCTe {
 			continue
 		}
-
-		var nfeProc domain.NFeProc
-		if err := xml.Unmarshal(bytes, &nfeProc); err != nil {
+		nfeKey := data.nfeKey
+		if nfeKey == "" {
 			continue
 		}
-
-		infNFe := nfeProc.NFe.InfNFe
-		nfeKey := strings.TrimPrefix(infNFe.ID, "NFe")
-		if nfeKey != "" {
-			xmlDataMap[nfeKey] = domain.XMLTaxData{
-				STValue:  infNFe.Total.ICMSTot.VST,
-				IPIValue: infNFe.Total.ICMSTot.VIPI,
-			}
+		if _, seen := xmlDataMap[nfeKey]; !seen {
+			nfeKeys = append(nfeKeys, nfeKey)
 		}
+		data.taxes.XMLFile = xmlFiles[i].Name
+		xmlDataMap[nfeKey] = data.taxes
+	}
+	return xmlDataMap, nfeKeys, nil
+}
+
+// ipistXMLResult holds the IPI and ST totals read from one XML.
+type ipistXMLResult struct {
+	nfeKey string
+	taxes  domain.XMLTaxData
+}
+
+// parseXMLForIPIST reads the IPI and ST totals of one XML.
+func parseXMLForIPIST(xmlFile io.Reader) (ipistXMLResult, error) {
+	bytes, err := io.ReadAll(xmlFile)
+	if err != nil {
+		return ipistXMLResult{}, err
 	}
-	return xmlDataMap, nil
+
+	doc, err := decodeFiscalDocument(bytes)
+	if err != nil {
+		return ipistXMLResult{}, err
+	}
+	result := ipistXMLResult{
+		nfeKey: doc.key,
+		taxes:  domain.XMLTaxData{DocumentType: doc.docType, Authorized: doc.authorized},
+	}
+	if doc.nfe != nil {
+		result.taxes.STValue = doc.nfe.InfNFe.Total.ICMSTot.VST
+		result.taxes.IPIValue = doc.nfe.InfNFe.Total.ICMSTot.VIPI
+	}
+	return result, nil
 }
 
 // SpedIPISTResult holds SPED data for IPI/ST.
@@ -115,46 +166,40 @@ type SpedIPISTResult struct {
 	Alerts       []string
 }
 
-// parseSpedForIPIST parses SPED file for IPI and ST data.
-func (s *service) parseSpedForIPIST(spedFile io.Reader) (map[string]SpedIPISTResult, error) {
+// parseSpedForIPIST parses SPED file for IPI and ST data, keeping only the wanted NFe keys.
+func (s *service) parseSpedForIPIST(spedFile io.Reader, wanted map[string]bool) (map[string]SpedIPISTResult, error) {
 	contexts := make(map[string]*domain.SpedTaxContext)
-	var currentC100Key string
-
-	decoder := charmap.ISO8859_1.NewDecoder()
-	scanner := bufio.NewScanner(decoder.Reader(spedFile))
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
-			continue
+	err := forEachDocument(spedFile, func(doc *sped.RegC100) error {
+		if !wanted[doc.AccessKey] {
+			return nil
 		}
-		recordType := parts[1]
-		switch recordType {
-		case "C100":
-			if len(parts) > 25 {
-				nfeKey := parts[9]
-				currentC100Key = nfeKey
-				if _, ok := contexts[nfeKey]; !ok {
-					contexts[nfeKey] = &domain.SpedTaxContext{}
-				}
-				contexts[nfeKey].C100IPIValue = parseNumberSped(parts[25])
-				contexts[nfeKey].C100STValue = parseNumberSped(parts[24])
-			}
-		case "C170":
-			if ctx, ok := contexts[currentC100Key]; ok && len(parts) > 24 {
-				ctx.C170SumST += parseNumberSped(parts[18])
-				ctx.C170SumIPI += parseNumberSped(parts[24])
+		if err := doc.Problems.Check("VL_ICMS_ST", "VL_IPI"); err != nil {
+			return err
+		}
+		ctx, ok := contexts[doc.AccessKey]
+		if !ok {
+			ctx = &domain.SpedTaxContext{}
+			contexts[doc.AccessKey] = ctx
+		}
+		ctx.C100IPIValue = doc.IPI
+		ctx.C100STValue = doc.ST
+		for _, item := range doc.Items {
+			if err := item.Problems.Check("VL_ICMS_ST", "VL_IPI"); err != nil {
+				return err
 			}
-		case "C190":
-			if ctx, ok := contexts[currentC100Key]; ok && len(parts) > 9 {
-				ctx.C190SumST += parseNumberSped(parts[9])
+			ctx.C170SumST += item.ST
+			ctx.C170SumIPI += item.IPI
+		}
+		for _, summary := range doc.Summaries {
+			if err := summary.Problems.Check("VL_ICMS_ST"); err != nil {
+				return err
 			}
+			ctx.C190SumST += summary.ST
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao ler arquivo SPED: %w", err)
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	finalizedResults := make(map[string]SpedIPISTResult)
@@ -162,24 +207,24 @@ func (s *service) parseSpedForIPIST(spedFile io.Reader) (map[string]SpedIPISTRes
 		var finalST, finalIPI float64
 		var alerts []string
 
-		if ctx.C100STValue > EPSILON {
+		if ctx.C100STValue > s.config.ValueTolerance {
 			finalST = ctx.C100STValue
-		} else if ctx.C170SumST > EPSILON {
+		} else if ctx.C170SumST > s.config.ValueTolerance {
 			finalST = ctx.C170SumST
-		} else if ctx.C190SumST > EPSILON {
+		} else if ctx.C190SumST > s.config.ValueTolerance {
 			finalST = ctx.C190SumST
 		}
 
-		if ctx.C100IPIValue > EPSILON {
+		if ctx.C100IPIValue > s.config.ValueTolerance {
 			finalIPI = ctx.C100IPIValue
-		} else if ctx.C170SumIPI > EPSILON {
+		} else if ctx.C170SumIPI > s.config.ValueTolerance {
 			finalIPI = ctx.C170SumIPI
 		}
 
-		if ctx.C100STValue > 0 && math.Abs(ctx.C100STValue-ctx.C170SumST) > 0.5 {
+		if ctx.C100STValue > 0 && math.Abs(ctx.C100STValue-ctx.C170SumST) > s.config.ItemSumTolerance {
 			alerts = append(alerts, "Divergência entre ST do C100 e a soma dos itens C170")
 		}
-		if ctx.C100IPIValue > 0 && math.Abs(ctx.C100IPIValue-ctx.C170SumIPI) > 0.5 {
+		if ctx.C100IPIValue > 0 && math.Abs(ctx.C100IPIValue-ctx.C170SumIPI) > s.config.ItemSumTolerance {
 			alerts = append(alerts, "Divergência entre IPI do C100 e a soma dos itens C170")
 		}
 
@@ -193,32 +238,42 @@ func (s *service) parseSpedForIPIST(spedFile io.Reader) (map[string]SpedIPISTRes
 }
 
 // AnalyzeICMSFiles analyzes ICMS from SPED and XML files.
-func (s *service) AnalyzeICMSFiles(spedFile io.Reader, xmlFiles []io.Reader, cfopsToIgnore []string) ([]domain.AnalysisResult, error) {
+func (s *service) AnalyzeICMSFiles(spedFile io.Reader, xmlFiles []domain.XMLFile, cfopsToIgnore []string) ([]domain.AnalysisResult, error) {
 	cfopsMap := make(map[string]bool)
 	for _, cfop := range cfopsToIgnore {
 		cfopsMap[cfop] = true
 	}
 
-	spedData, err := s.parseSpedFileForICMS(spedFile, cfopsMap)
+	// XMLs are read first so that only their keys are kept while streaming the SPED file.
+	xmlResults, xmlErrors := parseConcurrently(xmlFiles, s.config.XMLWorkers, parseXMLForICMS)
+	wanted := make(map[string]bool, len(xmlFiles))
+	for i, xmlResult := range xmlResults {
+		if xmlErrors[i] == nil {
+			wanted[xmlResult.NFeKey] = true
+		}
+	}
+
+	spedData, err := s.parseSpedFileForICMS(spedFile, cfopsMap, wanted)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao processar arquivo SPED: %w", err)
 	}
 
 	var problematicResults []domain.AnalysisResult
 
-	for _, xmlFile := range xmlFiles {
-		xmlResult, err := s.parseXMLForICMS(xmlFile)
-		if err != nil {
+	for i, xmlResult := range xmlResults {
+		if err := xmlErrors[i]; err != nil {
 			data := domain.ICMSData{
+				XMLFile:   xmlFiles[i].Name,
 				DocNumber: xmlResult.DocNumber,
 				IcmsXML:   xmlResult.IcmsXML,
 			}
 			result := domain.AnalysisResult{
-				Type:       domain.TypeICMS,
-				NFeKey:     xmlResult.NFeKey,
-				StatusCode: domain.StatusXMLInvalido,
-				Alerts:     []string{err.Error()},
-				Data:       data,
+				Type:         domain.TypeICMS,
+				DocumentType: xmlResult.DocumentType,
+				NFeKey:       xmlResult.NFeKey,
+				StatusCode:   domain.StatusXMLInvalido,
+				Alerts:       []string{err.Error()},
+				Data:         data,
 			}
 			problematicResults = append(problematicResults, result)
 			continue
@@ -229,38 +284,49 @@ func (s *service) AnalyzeICMSFiles(spedFile io.Reader, xmlFiles []io.Reader, cfo
 
 		if spedInfo, ok := spedData[xmlResult.NFeKey]; ok {
 			data := domain.ICMSData{
+				XMLFile:   xmlFiles[i].Name,
 				DocNumber: xmlResult.DocNumber,
 				IcmsXML:   xmlResult.IcmsXML,
 				IcmsSPED:  spedInfo.Icms,
 				CfopsSPED: spedInfo.Cfops,
 			}
 
-			if !spedInfo.TemCfopIgnorado && xmlResult.IcmsXML != spedInfo.Icms {
+			if !spedInfo.TemCfopIgnorado && math.Abs(xmlResult.IcmsXML-spedInfo.Icms) > s.config.ICMSTolerance {
 				statusCode = domain.StatusDiscrepanciaICMS
 				alerts = append(alerts, fmt.Sprintf("Discrepância detectada: ICMS XML=%.2f, SPED=%.2f", xmlResult.IcmsXML, spedInfo.Icms))
 			}
 
 			if statusCode != domain.StatusOK {
+				if !xmlResult.Authorized {
+					alerts = append(alerts, alertSemProtocolo)
+				}
 				result := domain.AnalysisResult{
-					Type:       domain.TypeICMS,
-					NFeKey:     xmlResult.NFeKey,
-					StatusCode: statusCode,
-					Alerts:     alerts,
-					Data:       data,
+					Type:         domain.TypeICMS,
+					DocumentType: xmlResult.DocumentType,
+					NFeKey:       xmlResult.NFeKey,
+					StatusCode:   statusCode,
+					Alerts:       alerts,
+					Data:         data,
 				}
 				problematicResults = append(problematicResults, result)
 			}
 		} else {
 			data := domain.ICMSData{
+				XMLFile:   xmlFiles[i].Name,
 				DocNumber: xmlResult.DocNumber,
 				IcmsXML:   xmlResult.IcmsXML,
 			}
+			alerts = append(alerts, notFoundAlert(xmlResult.DocumentType))
+			if !xmlResult.Authorized {
+				alerts = append(alerts, alertSemProtocolo)
+			}
 			result := domain.AnalysisResult{
-				Type:       domain.TypeICMS,
-				NFeKey:     xmlResult.NFeKey,
-				StatusCode: domain.StatusNaoEncontradaSPED,
-				Alerts:     []string{"NFe não encontrada no SPED"},
-				Data:       data,
+				Type:         domain.TypeICMS,
+				DocumentType: xmlResult.DocumentType,
+				NFeKey:       xmlResult.NFeKey,
+				StatusCode:   domain.StatusNaoEncontradaSPED,
+				Alerts:       alerts,
+				Data:         data,
 			}
 			problematicResults = append(problematicResults, result)
 		}
@@ -268,37 +334,58 @@ func (s *service) AnalyzeICMSFiles(spedFile io.Reader, xmlFiles []io.Reader, cfo
 	return problematicResults, nil
 }
 
+// icmsXMLResult holds the ICMS data read from one XML.
+type icmsXMLResult struct {
+	DocumentType domain.DocumentType
+	Authorized   bool
+	DocNumber    string
+	NFeKey       string
+	IcmsXML      float64
+}
+
+// alertSemProtocolo flags a reported XML that has no authorization protocol, such as a
+// bare <NFe> exported before transmission.
+const alertSemProtocolo = "XML sem protocolo de autorização"
+
+// notFoundAlert is the alert for a document missing from the SPED file.
+func notFoundAlert(docType domain.DocumentType) string {
+	switch docType {
+	case domain.DocumentNFCe:
+		return "NFC-e não encontrada no SPED"
+	case domain.DocumentCTe:
+		return "CT-e não encontrado no SPED (D100)"
+	default:
+		return "NFe não encontrada no SPED"
+	}
+}
+
 // parseXMLForICMS parses an XML file for ICMS data.
-func (s *service) parseXMLForICMS(xmlFile io.Reader) (struct {
-	DocNumber string
-	NFeKey    string
-	IcmsXML   float64
-}, error) {
-	result := struct {
-		DocNumber string
-		NFeKey    string
-		IcmsXML   float64
-	}{DocNumber: "ERRO", NFeKey: "ERRO"}
+func parseXMLForICMS(xmlFile io.Reader) (icmsXMLResult, error) {
+	result := icmsXMLResult{DocNumber: "ERRO", NFeKey: "ERRO"}
 	xmlData, err := io.ReadAll(xmlFile)
 	if err != nil {
 		return result, fmt.Errorf("erro ao ler dados do XML: %w", err)
 	}
 
-	var nfeProc domain.NFeProc
-	if err := xml.Unmarshal(xmlData, &nfeProc); err != nil {
+	doc, err := decodeFiscalDocument(xmlData)
+	if err != nil {
 		return result, fmt.Errorf("falha ao fazer parse do XML: %w", err)
 	}
-
-	infNFe := nfeProc.NFe.InfNFe
-	if infNFe.Ide.NNF == "" {
-		return result, fmt.Errorf("XML inválido ou não é uma NF-e")
+	if doc.number == "" {
+		return result, fmt.Errorf("XML inválido: número do documento %s ausente", doc.docType)
 	}
 
-	result.DocNumber = infNFe.Ide.NNF
-	result.NFeKey = nfeProc.ProtNFe.InfProt.ChNFe
+	result.DocumentType = doc.docType
+	result.Authorized = doc.authorized
+	result.DocNumber = doc.number
+	result.NFeKey = doc.key
+	if doc.cte != nil {
+		result.IcmsXML = round(cteICMS(doc.cte.InfCte.Imp.ICMS), 2)
+		return result, nil
+	}
 
 	var totalICMS float64
-	for _, det := range infNFe.Det {
+	for _, det := range doc.nfe.InfNFe.Det {
 		icms := det.Imposto.ICMS
 		var vICMSStr string
 		switch {
@@ -323,72 +410,106 @@ func (s *service) parseXMLForICMS(xmlFile io.Reader) (struct {
 	return result, nil
 }
 
-// parseSpedFileForICMS parses SPED file for ICMS data.
-func (s *service) parseSpedFileForICMS(spedFile io.Reader, cfopsSemCredito map[string]bool) (map[string]domain.SpedInfo, error) {
+// cteICMS returns the carrier's own ICMS of a CT-e. ICMS retained by substitution (ICMS60)
+// is owed by the service taker and is not part of D190, so it is left out.
+func cteICMS(icms domain.CTeICMSXML) float64 {
+	var vICMSStr string
+	switch {
+	case icms.ICMS00.VICMS != "":
+		vICMSStr = icms.ICMS00.VICMS
+	case icms.ICMS20.VICMS != "":
+		vICMSStr = icms.ICMS20.VICMS
+	case icms.ICMS90.VICMS != "":
+		vICMSStr = icms.ICMS90.VICMS
+	case icms.ICMSOutraUF.VICMSOutraUF != "":
+		vICMSStr = icms.ICMSOutraUF.VICMSOutraUF
+	}
+	vICMS, _ := strconv.ParseFloat(vICMSStr, 64)
+	return vICMS
+}
+
+// parseSpedFileForICMS parses SPED file for ICMS data, keeping only the wanted keys. NF-e and
+// NFC-e come from C100/C190 and CT-e from D100/D190.
+func (s *service) parseSpedFileForICMS(spedFile io.Reader, cfopsSemCredito map[string]bool, wanted map[string]bool) (map[string]domain.SpedInfo, error) {
 	spedData := make(map[string]domain.SpedInfo)
-	decoder := charmap.ISO8859_1.NewDecoder()
-	scanner := bufio.NewScanner(decoder.Reader(spedFile))
-
-	var currentC100Key string
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
-			continue
+	// addSummary accumulates one C190 or D190; with an empty CFOP it only registers the key.
+	addSummary := func(key, cfop string, icms float64) {
+		info, ok := spedData[key]
+		if !ok {
+			info = domain.SpedInfo{Cfops: []string{}}
 		}
-
-		recordType := parts[1]
-		switch recordType {
-		case "C100":
-			if len(parts) > 9 {
-				currentC100Key = parts[9]
-				if _, ok := spedData[currentC100Key]; !ok {
-					spedData[currentC100Key] = domain.SpedInfo{Cfops: []string{}}
-				}
+		if cfop != "" && !slices.Contains(info.Cfops, cfop) {
+			info.Cfops = append(info.Cfops, cfop)
+		}
+		if cfopsSemCredito[cfop] {
+			info.TemCfopIgnorado = true
+		}
+		info.Icms += icms
+		spedData[key] = info
+	}
+	err := forEachDocument(spedFile, func(doc *sped.RegC100) error {
+		if !wanted[doc.AccessKey] {
+			return nil
+		}
+		addSummary(doc.AccessKey, "", 0)
+		for _, summary := range doc.Summaries {
+			if err := summary.Problems.Check("VL_ICMS"); err != nil {
+				return err
 			}
-		case "C190":
-			if info, ok := spedData[currentC100Key]; ok && len(parts) > 7 {
-				cfop := parts[3]
-				found := false
-				for _, existingCfop := range info.Cfops {
-					if existingCfop == cfop {
-						found = true
-						break
-					}
-				}
-				if !found {
-					info.Cfops = append(info.Cfops, cfop)
-				}
-
-				if cfopsSemCredito[cfop] {
-					info.TemCfopIgnorado = true
-				}
-				icmsVal := parseNumberSped(parts[7])
-				info.Icms += icmsVal
-				spedData[currentC100Key] = info
+			addSummary(doc.AccessKey, summary.CFOP, summary.ICMS)
+		}
+		return nil
+	}, func(doc *sped.RegD100) error {
+		if !wanted[doc.AccessKey] {
+			return nil
+		}
+		addSummary(doc.AccessKey, "", 0)
+		for _, summary := range doc.Summaries {
+			if err := summary.Problems.Check("VL_ICMS"); err != nil {
+				return err
 			}
+			addSummary(doc.AccessKey, summary.CFOP, summary.ICMS)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	for key, info := range spedData {
 		info.Icms = round(info.Icms, 2)
 		spedData[key] = info
 	}
-
-	return spedData, scanner.Err()
+	return spedData, nil
 }
 
-// parseNumberSped parses a number from SPED format.
-func parseNumberSped(val string) float64 {
-	if val == "" {
-		return 0.0
-	}
-	s := strings.Replace(val, ",", ".", 1)
-	f, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0.0
+// forEachDocument streams the SPED file, calling nfe for each C100 with its items and
+// summaries and cte, when not nil, for each D100 with its summaries; only the current
+// document is held in memory. Lines that are not valid records are skipped, as are invalid
+// fields the callbacks do not check, so odd values elsewhere in the file do not stop the
+// analysis; the first callback error does.
+func forEachDocument(spedFile io.Reader, nfe func(doc *sped.RegC100) error, cte func(doc *sped.RegD100) error) error {
+	reader := sped.NewReader(spedFile)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch doc := record.(type) {
+		case *sped.RegC100:
+			err = nfe(doc)
+		case *sped.RegD100:
+			if cte != nil {
+				err = cte(doc)
+			}
+		}
+		if err != nil {
+			return err
+		}
 	}
-	return f
 }
 
 // round rounds a float to the specified places.
@@ -3,6 +3,7 @@ package domain
 
 import (
 	"encoding/xml"
+	"io"
 	"time"
 )
 
@@ -15,6 +16,16 @@ const (
 	TypeIPIST AnalysisType = "IPIST"
 )
 
+// DocumentType classifies the fiscal document of an XML.
+type DocumentType string
+
+// Constants for the document types read from XMLs.
+const (
+	DocumentNFe  DocumentType = "NFe"  // NF-e, model 55
+	DocumentNFCe DocumentType = "NFCe" // NFC-e, model 65
+	DocumentCTe  DocumentType = "CTe"  // CT-e, model 57
+)
+
 // StatusCode defines a type for analysis status codes.
 type StatusCode int
 
@@ -25,19 +36,29 @@ const (
 	StatusNaoEncontradaSPED StatusCode = 2
 	StatusXMLInvalido       StatusCode = 3
 	StatusDiscrepanciaIPIST StatusCode = 4
+	StatusArquivoIgnorado   StatusCode = 5
 )
 
-// AnalysisResult is the generic structure for analysis results.
+// AnalysisResult is the generic structure for analysis results. NFeKey holds the access key
+// of any document type, CT-e included.
 type AnalysisResult struct {
-	Type       AnalysisType `json:"type"`
-	NFeKey     string       `json:"nfe_key"`
-	StatusCode StatusCode   `json:"status_code"`
-	Alerts     []string     `json:"alerts"`
-	Data       interface{}  `json:"data"`
+	Type         AnalysisType `json:"type"`
+	DocumentType DocumentType `json:"document_type,omitempty"`
+	NFeKey       string       `json:"nfe_key"`
+	StatusCode   StatusCode   `json:"status_code"`
+	Alerts       []string     `json:"alerts"`
+	Data         interface{}  `json:"data"`
+}
+
+// XMLFile is an uploaded XML document with its file name, used to attribute results.
+type XMLFile struct {
+	Name   string
+	Reader io.Reader
 }
 
 // ICMSData holds specific data for ICMS analysis.
 type ICMSData struct {
+	XMLFile   string   `json:"xml_file,omitempty"`
 	DocNumber string   `json:"doc_number"`
 	IcmsXML   float64  `json:"icms_xml"`
 	IcmsSPED  float64  `json:"icms_sped"`
@@ -46,12 +67,18 @@ type ICMSData struct {
 
 // IPISTData holds specific data for IPI/ST analysis.
 type IPISTData struct {
+	XMLFile      string  `json:"xml_file,omitempty"`
 	STValueXML   float64 `json:"st_value_xml"`
 	IPIValueXML  float64 `json:"ipi_value_xml"`
 	STValueSPED  float64 `json:"st_value_sped"`
 	IPIValueSPED float64 `json:"ipi_value_sped"`
 }
 
+// SkippedFileData identifies an archive entry that was not analyzed; the reason is in the alerts.
+type SkippedFileData struct {
+	XMLFile string `json:"xml_file"`
+}
+
 // SpedInfo contains information extracted from the SPED file for a specific NFe.
 type SpedInfo struct {
 	Icms            float64
@@ -70,11 +97,14 @@ type SpedTaxContext struct {
 
 // XMLTaxData stores tax values extracted from a single XML.
 type XMLTaxData struct {
-	STValue  float64
-	IPIValue float64
+	XMLFile      string
+	DocumentType DocumentType
+	Authorized   bool
+	STValue      float64
+	IPIValue     float64
 }
 
-// NFeProc represents the root structure of a processed NFe XML.
+// NFeProc represents the root structure of a processed NFe XML. NF-e and NFC-e share it.
 type NFeProc struct {
 	XMLName xml.Name `xml:"nfeProc"`
 	NFe     NFeXML   `xml:"NFe"`
@@ -85,9 +115,11 @@ type NFeProc struct {
 	} `xml:"protNFe"`
 }
 
-// NFeXML represents the <NFe> node in the XML.
+// NFeXML represents the <NFe> node in the XML, which is also the root of an NF-e without
+// its authorization protocol.
 type NFeXML struct {
-	InfNFe struct {
+	XMLName xml.Name `xml:"NFe"`
+	InfNFe  struct {
 		ID    string   `xml:"Id,attr"`
 		Ide   IdeXML   `xml:"ide"`
 		Det   []DetXML `xml:"det"`
@@ -97,6 +129,7 @@ type NFeXML struct {
 
 // IdeXML represents the <ide> node (NFe identification).
 type IdeXML struct {
+	Mod string `xml:"mod"`
 	NNF string `xml:"nNF"`
 }
 
@@ -137,6 +170,49 @@ type DetXML struct {
 	} `xml:"imposto"`
 }
 
+// CTeProc represents the root structure of a processed CT-e XML.
+type CTeProc struct {
+	XMLName xml.Name `xml:"cteProc"`
+	CTe     CTeXML   `xml:"CTe"`
+	ProtCTe struct {
+		InfProt struct {
+			ChCTe string `xml:"chCTe"`
+		} `xml:"infProt"`
+	} `xml:"protCTe"`
+}
+
+// CTeXML represents the <CTe> node, also the root of a CT-e without its protocol.
+type CTeXML struct {
+	XMLName xml.Name `xml:"CTe"`
+	InfCte  struct {
+		ID  string `xml:"Id,attr"`
+		Ide struct {
+			Mod string `xml:"mod"`
+			NCT string `xml:"nCT"`
+		} `xml:"ide"`
+		Imp struct {
+			ICMS CTeICMSXML `xml:"ICMS"`
+		} `xml:"imp"`
+	} `xml:"infCte"`
+}
+
+// CTeICMSXML represents the <ICMS> node of a CT-e; only the groups with the carrier's own
+// ICMS are mapped.
+type CTeICMSXML struct {
+	ICMS00 struct {
+		VICMS string `xml:"vICMS"`
+	} `xml:"ICMS00"`
+	ICMS20 struct {
+		VICMS string `xml:"vICMS"`
+	} `xml:"ICMS20"`
+	ICMS90 struct {
+		VICMS string `xml:"vICMS"`
+	} `xml:"ICMS90"`
+	ICMSOutraUF struct {
+		VICMSOutraUF string `xml:"vICMSOutraUF"`
+	} `xml:"ICMSOutraUF"`
+}
+
 // --- Modelos de Conversor Francesinha ---
 
 // ContaSicredi representa uma entrada do arquivo Contas.csv para o conversor Sicredi.
@@ -0,0 +1,68 @@
+// internal/sped/parser.go
+
+// Package sped reads EFD ICMS/IPI (SPED Fiscal) files into typed records with named
+// fields, so analyses share one model instead of picking positional indexes. Reader streams
+// the records with bounded memory; Parse loads a whole file.
+package sped
+
+import (
+	"errors"
+	"io"
+)
+
+// File is the parsed content of an EFD ICMS/IPI file. Registers without a typed model are
+// only counted in RegisterCounts.
+type File struct {
+	Header       *Reg0000
+	Participants map[string]*Reg0150 // by COD_PART
+	Items        map[string]*Reg0200 // by COD_ITEM
+	Documents    []*RegC100
+	Transports   []*RegD100
+	Assessments  []*RegE100
+	Declared     []*Reg9900
+	// RegisterCounts holds how many lines of each register were read, to compare with Declared.
+	RegisterCounts map[string]int
+	// Skipped lists the first lines that could not be read as a record, up to 100;
+	// SkippedCount counts all of them.
+	Skipped      []*ParseError
+	SkippedCount int
+}
+
+// Parse reads a whole EFD file into memory with a Reader. Large files should be consumed
+// with Reader directly, keeping only what the analysis needs.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{
+		Participants: make(map[string]*Reg0150),
+		Items:        make(map[string]*Reg0200),
+	}
+	reader := NewReader(r)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch record := record.(type) {
+		case *Reg0000:
+			file.Header = record
+		case *Reg0150:
+			file.Participants[record.Code] = record
+		case *Reg0200:
+			file.Items[record.Code] = record
+		case *RegC100:
+			file.Documents = append(file.Documents, record)
+		case *RegD100:
+			file.Transports = append(file.Transports, record)
+		case *RegE100:
+			file.Assessments = append(file.Assessments, record)
+		case *Reg9900:
+			file.Declared = append(file.Declared, record)
+		}
+	}
+	file.RegisterCounts = reader.RegisterCounts()
+	file.Skipped = reader.Skipped()
+	file.SkippedCount = reader.SkippedCount()
+	return file, nil
+}
@@ -0,0 +1,206 @@
+// internal/sped/reader.go
+package sped
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// maxSkipped bounds how many skipped lines a Reader keeps; a file that is not EFD at all
+// would otherwise list every one of its lines. Further lines are only counted.
+const maxSkipped = 100
+
+// Reader streams the typed records of an EFD ICMS/IPI file encoded in ISO-8859-1, as
+// delivered by the PVA. Only the current line and the document being assembled are kept
+// in memory, so files of any size can be read; lines have no length limit.
+type Reader struct {
+	src     *bufio.Reader
+	line    int
+	done    bool
+	counts  map[string]int
+	skipped []*ParseError
+	// skippedCount includes the skipped lines beyond maxSkipped.
+	skippedCount int
+
+	// open is the C100, D100 or E100 still receiving its child records.
+	open  Record
+	ready []Record
+}
+
+// NewReader returns a Reader that decodes r from ISO-8859-1.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		src:    bufio.NewReaderSize(charmap.ISO8859_1.NewDecoder().Reader(r), 64*1024),
+		counts: make(map[string]int),
+	}
+}
+
+// Next returns the next typed record, or io.EOF after the 9999 record or the end of the
+// input. A C100 is returned once all its C170 and C190 have been read, a D100 with its D190
+// and an E100 with its E110. Registers without a typed model are skipped and only counted. Reading stops at
+// 9999, so the digital signature appended to transmitted files is ignored. Lines that cannot
+// be read as a record are skipped and listed by Skipped; fields that cannot be converted are
+// listed in the Problems of their record. Only read errors of the input are returned.
+func (r *Reader) Next() (Record, error) {
+	for len(r.ready) == 0 {
+		if r.done {
+			return nil, io.EOF
+		}
+		if err := r.advance(); err != nil {
+			r.done = true
+			return nil, err
+		}
+	}
+	record := r.ready[0]
+	r.ready = r.ready[1:]
+	return record, nil
+}
+
+// RegisterCounts returns how many lines of each register were read so far, typed or not,
+// to compare with the 9900 records.
+func (r *Reader) RegisterCounts() map[string]int {
+	return r.counts
+}
+
+// Skipped returns the first lines skipped so far, up to 100, such as lines not starting with
+// "|" or a C170 without its C100. SkippedCount tells how many there were in all.
+func (r *Reader) Skipped() []*ParseError {
+	return r.skipped
+}
+
+// SkippedCount returns how many lines were skipped so far, including those beyond the ones
+// kept by Skipped.
+func (r *Reader) SkippedCount() int {
+	return r.skippedCount
+}
+
+func (r *Reader) skip(register string, err error) {
+	r.skippedCount++
+	if len(r.skipped) < maxSkipped {
+		r.skipped = append(r.skipped, &ParseError{Line: r.line, Register: register, Err: err})
+	}
+}
+
+// advance reads one line, queueing the records it completes.
+func (r *Reader) advance() error {
+	text, err := r.readLine()
+	if errors.Is(err, io.EOF) {
+		r.closeOpen()
+		r.done = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		return nil
+	}
+	if !strings.HasPrefix(text, "|") {
+		r.skip("", errors.New("a linha não começa com \"|\""))
+		return nil
+	}
+
+	f := splitLine(r.line, text)
+	register := f.register
+	if len(register) != 4 {
+		r.skip("", fmt.Errorf("código de registro inválido: %q", register))
+		return nil
+	}
+	r.counts[register]++
+	if r.open != nil && r.closes(register) {
+		r.closeOpen()
+	}
+
+	switch register {
+	case "0000":
+		r.emit(decode0000(r.line, &f))
+	case "0150":
+		r.emit(decode0150(r.line, &f))
+	case "0200":
+		r.emit(decode0200(r.line, &f))
+	case "C100":
+		r.open = decodeC100(r.line, &f)
+	case "C170":
+		document, ok := r.open.(*RegC100)
+		if !ok {
+			r.skip(register, errors.New("C170 sem C100 anterior"))
+			break
+		}
+		item := decodeC170(r.line, &f)
+		item.Document = document
+		document.Items = append(document.Items, item)
+	case "C190":
+		document, ok := r.open.(*RegC100)
+		if !ok {
+			r.skip(register, errors.New("C190 sem C100 anterior"))
+			break
+		}
+		summary := decodeC190(r.line, &f)
+		summary.Document = document
+		document.Summaries = append(document.Summaries, summary)
+	case "D100":
+		r.open = decodeD100(r.line, &f)
+	case "D190":
+		document, ok := r.open.(*RegD100)
+		if !ok {
+			r.skip(register, errors.New("D190 sem D100 anterior"))
+			break
+		}
+		summary := decodeD190(r.line, &f)
+		summary.Document = document
+		document.Summaries = append(document.Summaries, summary)
+	case "E100":
+		r.open = decodeE100(r.line, &f)
+	case "E110":
+		period, ok := r.open.(*RegE100)
+		if !ok {
+			r.skip(register, errors.New("E110 sem E100 anterior"))
+			break
+		}
+		assessment := decodeE110(r.line, &f)
+		assessment.Period = period
+		period.Assessment = assessment
+	case "9900":
+		r.emit(decode9900(r.line, &f))
+	case "9999":
+		r.done = true
+	}
+	return nil
+}
+
+// closes reports whether register ends the open C100, D100 or E100: a new document or
+// period, the block closing record (C990, D990, E990) or any record of another block. Other
+// registers of the same block, such as C110 or D101, may appear among the children.
+func (r *Reader) closes(register string) bool {
+	open := r.open.Register()
+	return register == open || register[0] != open[0] || strings.HasSuffix(register, "990")
+}
+
+func (r *Reader) closeOpen() {
+	if r.open != nil {
+		r.emit(r.open)
+		r.open = nil
+	}
+}
+
+func (r *Reader) emit(record Record) {
+	r.ready = append(r.ready, record)
+}
+
+// readLine returns the next line without the line break and surrounding spaces.
+func (r *Reader) readLine() (string, error) {
+	text, err := r.src.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("erro ao ler arquivo SPED na linha %d: %w", r.line+1, err)
+	}
+	if errors.Is(err, io.EOF) && text == "" {
+		return "", io.EOF
+	}
+	r.line++
+	return strings.TrimSpace(text), nil
+}
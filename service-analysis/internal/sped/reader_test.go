@@ -0,0 +1,250 @@
+package sped
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// efdFile joins lines into an EFD file with CRLF line breaks, as generated by the PVA.
+func efdFile(lines ...string) string {
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestReaderHierarchy(t *testing.T) {
+	input := efdFile(
+		efdLine("0000", "017", "0", "01012024", "31012024", "EMPRESA LTDA", "12345678000190", "", "SP", "123456789", "3550308", "", "", "A", "1"),
+		efdLine("0001", "0"),
+		efdLine("0150", "F001", "FORNECEDOR SA", "1058", "98765432000110", "", "987654321", "3304557", "", "RUA A", "10", "", "CENTRO"),
+		efdLine("0200", "P001", "PARAFUSO", "", "", "UN", "00", "73181500", "", "", "", "18,00", ""),
+		efdLine("0990", "5"),
+		efdLine("C001", "0"),
+		efdLine("C100", "0", "1", "F001", "55", "00", "1", "1", "", "05012024", "06012024", "100,00"),
+		efdLine("C170", "1", "P001", "", "10", "UN", "100,00"),
+		efdLine("C170", "2", "P001", "", "5", "UN", "50,00"),
+		efdLine("C190", "000", "1102", "18,00", "100,00"),
+		efdLine("C100", "1", "0", "C001", "55", "00", "1", "2", "", "07012024", "07012024", "200,00"),
+		efdLine("C110", "1", "INFORMAÇÃO COMPLEMENTAR"),
+		efdLine("C190", "000", "5102", "18,00", "200,00"),
+		efdLine("C990", "8"),
+		efdLine("D001", "0"),
+		efdLine("D100", "0", "1", "T001", "57", "00", "1", "", "3"),
+		efdLine("D190", "000", "1352", "12,00", "300,00"),
+		efdLine("D990", "3"),
+		efdLine("E001", "0"),
+		efdLine("E100", "01012024", "31012024"),
+		efdLine("E110", "1000,00"),
+		efdLine("E990", "3"),
+		efdLine("9001", "0"),
+		efdLine("9900", "C100", "2"),
+		efdLine("9990", "3"),
+		efdLine("9999", "26"),
+		"SBRCAAEPDR0000 assinatura digital",
+	)
+	file, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(file.Skipped) != 0 {
+		t.Errorf("linhas ignoradas = %v, esperado nenhuma", file.Skipped)
+	}
+	if file.Header == nil || file.Header.Line != 1 || file.Participants["F001"] == nil || file.Items["P001"] == nil {
+		t.Fatalf("registros do bloco 0 = %+v, %v, %v", file.Header, file.Participants, file.Items)
+	}
+
+	if len(file.Documents) != 2 {
+		t.Fatalf("C100 = %d, esperado 2", len(file.Documents))
+	}
+	first, second := file.Documents[0], file.Documents[1]
+	if first.Line != 7 || len(first.Items) != 2 || len(first.Summaries) != 1 {
+		t.Errorf("primeiro C100 = linha %d, %d C170, %d C190; esperado linha 7, 2 C170, 1 C190", first.Line, len(first.Items), len(first.Summaries))
+	}
+	if second.Line != 11 || len(second.Items) != 0 || len(second.Summaries) != 1 {
+		t.Errorf("segundo C100 = linha %d, %d C170, %d C190; esperado linha 11, 0 C170, 1 C190", second.Line, len(second.Items), len(second.Summaries))
+	}
+	for _, item := range first.Items {
+		if item.Document != first {
+			t.Errorf("C170 da linha %d não aponta para o seu C100", item.Line)
+		}
+	}
+	if first.Items[1].Line != 9 || first.Items[1].Number != 2 {
+		t.Errorf("segundo C170 = linha %d, item %d; esperado linha 9, item 2", first.Items[1].Line, first.Items[1].Number)
+	}
+	if summary := second.Summaries[0]; summary.Document != second || summary.CFOP != "5102" || summary.Line != 13 {
+		t.Errorf("C190 do segundo C100 = %+v", summary)
+	}
+
+	if len(file.Transports) != 1 || len(file.Transports[0].Summaries) != 1 || file.Transports[0].Summaries[0].Document != file.Transports[0] {
+		t.Errorf("D100 = %+v, esperado um documento com o seu D190", file.Transports)
+	}
+	if len(file.Assessments) != 1 || file.Assessments[0].Assessment == nil || file.Assessments[0].Assessment.Period != file.Assessments[0] ||
+		file.Assessments[0].Assessment.TotalDebits != 1000 {
+		t.Errorf("E100 = %+v, esperado um período com o seu E110", file.Assessments)
+	}
+	if len(file.Declared) != 1 || file.Declared[0].Code != "C100" || file.Declared[0].Count != 2 {
+		t.Errorf("9900 = %+v", file.Declared)
+	}
+
+	// Registers without a typed model, such as C110, are counted; lines after 9999 are not read.
+	counts := map[string]int{"C100": 2, "C170": 2, "C190": 2, "C110": 1, "D190": 1, "9999": 1}
+	for register, want := range counts {
+		if got := file.RegisterCounts[register]; got != want {
+			t.Errorf("RegisterCounts[%s] = %d, esperado %d", register, got, want)
+		}
+	}
+	total := 0
+	for _, count := range file.RegisterCounts {
+		total += count
+	}
+	if total != 26 {
+		t.Errorf("RegisterCounts = %v, esperadas as 26 linhas até o 9999", file.RegisterCounts)
+	}
+}
+
+func TestReaderEmitsDocumentAfterChildren(t *testing.T) {
+	// The C100 is returned only when the next C100 starts, with its C170 already attached,
+	// and the last open document is returned at the end of the input.
+	reader := NewReader(strings.NewReader(efdFile(
+		efdLine("C100", "0", "1", "F001", "55", "00", "1", "1"),
+		efdLine("C170", "1", "P001"),
+		efdLine("C100", "0", "1", "F001", "55", "00", "1", "2"),
+	)))
+	var numbers []string
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		document := record.(*RegC100)
+		numbers = append(numbers, document.Number)
+		if document.Number == "1" && len(document.Items) != 1 {
+			t.Errorf("C100 1 retornado com %d C170, esperado 1", len(document.Items))
+		}
+	}
+	if strings.Join(numbers, ",") != "1,2" {
+		t.Errorf("documentos = %v, esperado [1 2]", numbers)
+	}
+	if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next após o fim = %v, esperado io.EOF", err)
+	}
+}
+
+func TestReaderSkippedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		line     int
+		register string
+		message  string
+	}{
+		{"sem barra inicial", []string{efdLine("0001", "0"), "C100|0|1|"}, 2, "", `a linha não começa com "|"`},
+		{"código inválido", []string{efdLine("C1", "0")}, 1, "", `código de registro inválido: "C1"`},
+		{"C170 sem C100", []string{efdLine("C001", "0"), efdLine("C170", "1", "P001")}, 2, "C170", "C170 sem C100 anterior"},
+		{"C190 após o fechamento do bloco", []string{efdLine("C100", "0", "1"), efdLine("C990", "2"), efdLine("C190", "000")}, 3, "C190", "C190 sem C100 anterior"},
+		{"D190 sob um C100", []string{efdLine("C100", "0", "1"), efdLine("D190", "000")}, 2, "D190", "D190 sem D100 anterior"},
+		{"E110 sem E100", []string{"", efdLine("E001", "0"), efdLine("E110", "0")}, 3, "E110", "E110 sem E100 anterior"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse(strings.NewReader(efdFile(tt.lines...)))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(file.Skipped) != 1 {
+				t.Fatalf("linhas ignoradas = %v, esperado uma", file.Skipped)
+			}
+			skipped := file.Skipped[0]
+			if skipped.Line != tt.line || skipped.Register != tt.register || skipped.Err.Error() != tt.message {
+				t.Errorf("linha ignorada = %+v (%v), esperado linha %d, registro %q: %s", skipped, skipped.Err, tt.line, tt.register, tt.message)
+			}
+		})
+	}
+}
+
+func TestReaderProblemLines(t *testing.T) {
+	// Problems carry the line of their record, counting blank lines, and do not skip it.
+	input := efdFile(
+		efdLine("C001", "0"),
+		"",
+		efdLine("C100", "0", "1", "F001", "55", "00", "1", "1", "", "32012024", "", "100,00"),
+		efdLine("C190", "000", "1102", "18,00", "100,00", "100,00", "dezoito"),
+	)
+	file, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(file.Documents) != 1 || len(file.Documents[0].Summaries) != 1 {
+		t.Fatalf("documentos = %+v, esperado um C100 com o seu C190", file.Documents)
+	}
+	document := file.Documents[0]
+	err = document.Problems.Check("DT_DOC")
+	if err == nil || err.Error() != `linha 3 (registro C100, campo DT_DOC): data inválida "32012024"` {
+		t.Errorf("Check(DT_DOC) = %v", err)
+	}
+	err = document.Summaries[0].Problems.Check("VL_ICMS")
+	if err == nil || err.Error() != `linha 4 (registro C190, campo VL_ICMS): valor numérico inválido "dezoito"` {
+		t.Errorf("Check(VL_ICMS) = %v", err)
+	}
+}
+
+func TestReaderDecodesLatin1(t *testing.T) {
+	input := "|0150|F001|JOS\xc9 DA CONCEI\xc7\xc3O|\n"
+	file, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := file.Participants["F001"].Name; got != "JOSÉ DA CONCEIÇÃO" {
+		t.Errorf("nome = %q, esperado JOSÉ DA CONCEIÇÃO", got)
+	}
+}
+
+func TestReaderLongLines(t *testing.T) {
+	// Lines are not limited by the 64 KB buffer: a long C110 text among the children keeps
+	// the document intact, and a long field is read whole.
+	long := strings.Repeat("A", 200*1024)
+	input := efdFile(
+		efdLine("C100", "0", "1", "F001", "55", "00", "1", "1"),
+		efdLine("C110", "1", long),
+		efdLine("C190", "000", "1102", "18,00", "100,00"),
+		efdLine("0200", "P001", long),
+		efdLine("9999", "4"),
+	)
+	file, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(file.Skipped) != 0 {
+		t.Errorf("linhas ignoradas = %v, esperado nenhuma", file.Skipped)
+	}
+	if len(file.Documents) != 1 || len(file.Documents[0].Summaries) != 1 || file.Documents[0].Summaries[0].Line != 3 {
+		t.Fatalf("documentos = %+v, esperado um C100 com o C190 da linha 3", file.Documents)
+	}
+	if item := file.Items["P001"]; item == nil || item.Description != long || item.Line != 4 {
+		t.Errorf("0200 da linha 4 não foi lido por inteiro")
+	}
+}
+
+func TestReaderBoundsSkippedLines(t *testing.T) {
+	// A file that is not EFD at all skips every line, but only the first ones are kept.
+	lines := make([]string, maxSkipped+50)
+	for i := range lines {
+		lines[i] = "não é um registro"
+	}
+	lines = append(lines, efdLine("C170", "1"))
+	file, err := Parse(strings.NewReader(efdFile(lines...)))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(file.Skipped) != maxSkipped || file.SkippedCount != maxSkipped+51 {
+		t.Fatalf("Skipped = %d, SkippedCount = %d; esperado %d e %d", len(file.Skipped), file.SkippedCount, maxSkipped, maxSkipped+51)
+	}
+	for i, skipped := range file.Skipped {
+		if skipped.Line != i+1 {
+			t.Fatalf("Skipped[%d] na linha %d, esperado %d", i, skipped.Line, i+1)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// internal/sped/record.go
+package sped
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError reports a malformed line or field of an EFD file. Field is the layout code of
+// the field, such as "VL_ICMS", or empty when the whole line is affected.
+type ParseError struct {
+	Line     int
+	Register string
+	Field    string
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Register == "":
+		return fmt.Sprintf("linha %d: %v", e.Line, e.Err)
+	case e.Field == "":
+		return fmt.Sprintf("linha %d (registro %s): %v", e.Line, e.Register, e.Err)
+	default:
+		return fmt.Sprintf("linha %d (registro %s, campo %s): %v", e.Line, e.Register, e.Field, e.Err)
+	}
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Problems lists the fields of a record that are missing or could not be converted; they
+// are left at their zero value. Decoding never fails because of them: a real-world file
+// often has odd values in fields no analysis reads, so each caller checks only the fields
+// it uses with Check.
+type Problems []*ParseError
+
+// Check returns the problem of the first named field (a layout code such as "VL_ICMS")
+// that is missing or invalid, or nil when all of them were read.
+func (p Problems) Check(names ...string) error {
+	for _, problem := range p {
+		if slices.Contains(names, problem.Field) {
+			return problem
+		}
+	}
+	return nil
+}
+
+// fields reads the pipe-separated fields of one line using the 1-based numbering of the
+// EFD layout guide, where field 1 is REG. Conversion problems are collected in problems,
+// named after the register layout, instead of failing the line.
+type fields struct {
+	line     int
+	register string
+	values   []string
+	problems Problems
+}
+
+// splitLine splits "|C100|0|1|...|" into fields; values[0] is always empty.
+func splitLine(line int, text string) fields {
+	f := fields{line: line, values: strings.Split(strings.TrimSuffix(text, "|"), "|")}
+	f.register = f.text(1)
+	return f
+}
+
+// invalid records a problem with field n. Fields past the end of the line are reported as
+// missing: later fields are optional so that older layout versions, which lack the fields
+// added since, are still read.
+func (f *fields) invalid(n int, err error) {
+	name := strconv.Itoa(n)
+	if layout := layouts[f.register]; n < len(layout) {
+		name = layout[n]
+	}
+	f.problems = append(f.problems, &ParseError{Line: f.line, Register: f.register, Field: name, Err: err})
+}
+
+// text returns field n, or "" when the line is shorter.
+func (f *fields) text(n int) string {
+	if n >= len(f.values) {
+		return ""
+	}
+	return f.values[n]
+}
+
+// value returns field n for conversion, recording a problem when the line is shorter.
+func (f *fields) value(n int) (string, bool) {
+	if n >= len(f.values) {
+		f.invalid(n, errors.New("campo ausente"))
+		return "", false
+	}
+	return f.values[n], f.values[n] != ""
+}
+
+// number parses a decimal field written with a comma ("1234,56"); empty is zero.
+func (f *fields) number(n int) float64 {
+	value, ok := f.value(n)
+	if !ok {
+		return 0
+	}
+	parsed, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		f.invalid(n, fmt.Errorf("valor numérico inválido %q", value))
+		return 0
+	}
+	return parsed
+}
+
+// integer parses an integer field; empty is zero.
+func (f *fields) integer(n int) int {
+	value, ok := f.value(n)
+	if !ok {
+		return 0
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		f.invalid(n, fmt.Errorf("inteiro inválido %q", value))
+		return 0
+	}
+	return parsed
+}
+
+// date parses a DDMMYYYY field; empty is the zero time.
+func (f *fields) date(n int) time.Time {
+	value, ok := f.value(n)
+	if !ok {
+		return time.Time{}
+	}
+	parsed, err := time.Parse("02012006", value)
+	if err != nil {
+		f.invalid(n, fmt.Errorf("data inválida %q", value))
+		return time.Time{}
+	}
+	return parsed
+}
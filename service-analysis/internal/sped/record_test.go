@@ -0,0 +1,332 @@
+package sped
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// efdLine builds a pipe-separated EFD line from REG and the following fields, such as
+// "|C190|000|5102|18,00|".
+func efdLine(fields ...string) string {
+	return "|" + strings.Join(fields, "|") + "|"
+}
+
+// decoders decodes one line of each typed register outside a Reader, so child records can
+// be checked without their parent.
+var decoders = map[string]func(line int, f *fields) Record{
+	"0000": func(line int, f *fields) Record { return decode0000(line, f) },
+	"0150": func(line int, f *fields) Record { return decode0150(line, f) },
+	"0200": func(line int, f *fields) Record { return decode0200(line, f) },
+	"C100": func(line int, f *fields) Record { return decodeC100(line, f) },
+	"C170": func(line int, f *fields) Record { return decodeC170(line, f) },
+	"C190": func(line int, f *fields) Record { return decodeC190(line, f) },
+	"D100": func(line int, f *fields) Record { return decodeD100(line, f) },
+	"D190": func(line int, f *fields) Record { return decodeD190(line, f) },
+	"E100": func(line int, f *fields) Record { return decodeE100(line, f) },
+	"E110": func(line int, f *fields) Record { return decodeE110(line, f) },
+	"9900": func(line int, f *fields) Record { return decode9900(line, f) },
+}
+
+// problemsOf returns the Problems of a decoded record.
+func problemsOf(record Record) Problems {
+	switch record := record.(type) {
+	case *Reg0000:
+		return record.Problems
+	case *Reg0150:
+		return record.Problems
+	case *Reg0200:
+		return record.Problems
+	case *RegC100:
+		return record.Problems
+	case *RegC170:
+		return record.Problems
+	case *RegC190:
+		return record.Problems
+	case *RegD100:
+		return record.Problems
+	case *RegD190:
+		return record.Problems
+	case *RegE100:
+		return record.Problems
+	case *RegE110:
+		return record.Problems
+	case *Reg9900:
+		return record.Problems
+	}
+	return nil
+}
+
+func date(day, month, year int) time.Time {
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDecodeRecords(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		check func(t *testing.T, record Record)
+	}{
+		{
+			name: "0000",
+			line: efdLine("0000", "017", "0", "01012024", "31012024", "EMPRESA LTDA", "12345678000190", "", "SP", "123456789", "3550308", "", "", "A", "1"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*Reg0000)
+				if r.LayoutVersion != "017" || r.Purpose != "0" || !r.Start.Equal(date(1, 1, 2024)) || !r.End.Equal(date(31, 1, 2024)) ||
+					r.Name != "EMPRESA LTDA" || r.CNPJ != "12345678000190" || r.UF != "SP" || r.IE != "123456789" ||
+					r.CityCode != "3550308" || r.Profile != "A" || r.Activity != "1" {
+					t.Errorf("0000 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "0150",
+			line: efdLine("0150", "F001", "FORNECEDOR SA", "1058", "98765432000110", "", "987654321", "3304557", "", "RUA A", "10", "", "CENTRO"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*Reg0150)
+				if r.Code != "F001" || r.Name != "FORNECEDOR SA" || r.CNPJ != "98765432000110" || r.IE != "987654321" ||
+					r.CityCode != "3304557" || r.District != "CENTRO" {
+					t.Errorf("0150 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "0200",
+			line: efdLine("0200", "P001", "PARAFUSO", "7891234567890", "", "UN", "00", "73181500", "", "73", "", "18,00", "1001500"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*Reg0200)
+				if r.Code != "P001" || r.Description != "PARAFUSO" || r.Unit != "UN" || r.ItemType != "00" ||
+					r.NCM != "73181500" || r.ICMSRate != 18 || r.CEST != "1001500" {
+					t.Errorf("0200 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "C100",
+			line: efdLine("C100", "1", "0", "F001", "55", "00", "1", "123", "35240112345678000190550010000001231000001230", "05012024", "06012024",
+				"1234,56", "0", "10,00", "0", "1200,00", "0", "44,56", "0", "0", "1100,00", "198,00", "100,00", "12,00", "5,50", "20,37", "93,83", "0", "0"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegC100)
+				if r.Operation != "1" || r.IssuerType != "0" || r.ParticipantCode != "F001" || r.Model != "55" || r.Situation != "00" ||
+					r.Series != "1" || r.Number != "123" || r.AccessKey != "35240112345678000190550010000001231000001230" ||
+					!r.IssueDate.Equal(date(5, 1, 2024)) || !r.EntryExitDate.Equal(date(6, 1, 2024)) {
+					t.Errorf("C100 identificação = %+v", r)
+				}
+				if r.Total != 1234.56 || r.Discount != 10 || r.Merchandise != 1200 || r.Freight != 44.56 || r.ICMSBase != 1100 ||
+					r.ICMS != 198 || r.STBase != 100 || r.ST != 12 || r.IPI != 5.5 || r.PIS != 20.37 || r.COFINS != 93.83 {
+					t.Errorf("C100 valores = %+v", r)
+				}
+			},
+		},
+		{
+			name: "C170",
+			line: efdLine("C170", "1", "P001", "PARAFUSO", "100", "UN", "1200,00", "0", "0", "000", "5102",
+				"", "1200,00", "18,00", "216,00", "0", "0", "0", "0", "50", "",
+				"0", "0", "0", "01", "1200,00", "1,65", "", "", "19,80", "01",
+				"1200,00", "7,60", "", "", "91,20", "", "0"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegC170)
+				if r.Number != 1 || r.ItemCode != "P001" || r.Quantity != 100 || r.Value != 1200 || r.CSTICMS != "000" ||
+					r.CFOP != "5102" || r.ICMSBase != 1200 || r.ICMSRate != 18 || r.ICMS != 216 || r.CSTIPI != "50" ||
+					r.PISRate != 1.65 || r.PIS != 19.8 || r.COFINSRate != 7.6 || r.COFINS != 91.2 {
+					t.Errorf("C170 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "C190",
+			line: efdLine("C190", "000", "5102", "18,00", "1234,56", "1100,00", "198,00", "0", "0", "134,56", "5,50", ""),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegC190)
+				if r.CSTICMS != "000" || r.CFOP != "5102" || r.ICMSRate != 18 || r.OperationValue != 1234.56 ||
+					r.ICMSBase != 1100 || r.ICMS != 198 || r.BaseReduction != 134.56 || r.IPI != 5.5 {
+					t.Errorf("C190 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "D100",
+			line: efdLine("D100", "0", "1", "T001", "57", "00", "1", "", "456", "35240198765432000110570010000004561000004560", "10012024", "11012024",
+				"0", "", "300,00", "0", "1", "300,00", "300,00", "36,00", "0", "", "", "3550308", "3304557"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegD100)
+				if r.Operation != "0" || r.IssuerType != "1" || r.Model != "57" || r.Number != "456" ||
+					r.AccessKey != "35240198765432000110570010000004561000004560" || !r.IssueDate.Equal(date(10, 1, 2024)) ||
+					!r.AcquisitionDate.Equal(date(11, 1, 2024)) || r.Total != 300 || r.Service != 300 || r.ICMSBase != 300 ||
+					r.ICMS != 36 || r.OriginCityCode != "3550308" || r.DestinationCityCode != "3304557" {
+					t.Errorf("D100 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "D190",
+			line: efdLine("D190", "000", "1352", "12,00", "300,00", "300,00", "36,00", "0", ""),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegD190)
+				if r.CSTICMS != "000" || r.CFOP != "1352" || r.ICMSRate != 12 || r.OperationValue != 300 || r.ICMS != 36 {
+					t.Errorf("D190 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "E100",
+			line: efdLine("E100", "01012024", "31012024"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegE100)
+				if !r.Start.Equal(date(1, 1, 2024)) || !r.End.Equal(date(31, 1, 2024)) {
+					t.Errorf("E100 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "E110",
+			line: efdLine("E110", "1000,00", "0", "0", "0", "800,00", "0", "0", "0", "50,00", "150,00", "0", "150,00", "0", "0"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*RegE110)
+				if r.TotalDebits != 1000 || r.TotalCredits != 800 || r.PreviousCreditBalance != 50 || r.AssessedBalance != 150 || r.ICMSDue != 150 {
+					t.Errorf("E110 = %+v", r)
+				}
+			},
+		},
+		{
+			name: "9900",
+			line: efdLine("9900", "C100", "2"),
+			check: func(t *testing.T, record Record) {
+				r := record.(*Reg9900)
+				if r.Code != "C100" || r.Count != 2 {
+					t.Errorf("9900 = %+v", r)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := splitLine(7, tt.line)
+			record := decoders[tt.name](7, &f)
+			if record.Register() != tt.name {
+				t.Fatalf("Register() = %q, esperado %q", record.Register(), tt.name)
+			}
+			if problems := problemsOf(record); len(problems) > 0 {
+				t.Fatalf("problemas inesperados: %v", problems)
+			}
+			tt.check(t, record)
+		})
+	}
+}
+
+func TestDecodeProblemsNameTheField(t *testing.T) {
+	// An invalid value at a field index must be reported under the layout name of that
+	// index, so decoders and layouts cannot drift apart unnoticed.
+	tests := []struct {
+		register string
+		index    int
+		want     string
+	}{
+		{"0000", 4, "DT_INI"},
+		{"0000", 5, "DT_FIN"},
+		{"0200", 12, "ALIQ_ICMS"},
+		{"C100", 10, "DT_DOC"},
+		{"C100", 12, "VL_DOC"},
+		{"C100", 22, "VL_ICMS"},
+		{"C100", 24, "VL_ICMS_ST"},
+		{"C170", 2, "NUM_ITEM"},
+		{"C170", 15, "VL_ICMS"},
+		{"C170", 38, "VL_ABAT_NT"},
+		{"C190", 4, "ALIQ_ICMS"},
+		{"C190", 7, "VL_ICMS"},
+		{"D100", 11, "DT_DOC"},
+		{"D100", 15, "VL_DOC"},
+		{"D100", 20, "VL_ICMS"},
+		{"D190", 7, "VL_ICMS"},
+		{"E100", 3, "DT_FIN"},
+		{"E110", 13, "VL_ICMS_RECOLHER"},
+		{"9900", 3, "QTD_REG_BLC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.register+"/"+tt.want, func(t *testing.T) {
+			// values[i] holds field i+1; every other field is empty, which is valid.
+			values := make([]string, len(layouts[tt.register])-1)
+			values[0] = tt.register
+			values[tt.index-1] = "x"
+			f := splitLine(12, efdLine(values...))
+			problems := problemsOf(decoders[tt.register](12, &f))
+			if len(problems) != 1 {
+				t.Fatalf("problemas = %v, esperado um em %s", problems, tt.want)
+			}
+			if p := problems[0]; p.Field != tt.want || p.Register != tt.register || p.Line != 12 {
+				t.Errorf("problema = %+v, esperado %s do registro %s na linha 12", p, tt.want, tt.register)
+			}
+		})
+	}
+}
+
+func TestDecodeMissingFields(t *testing.T) {
+	// An older layout version stops before the last fields: converted fields past the end are
+	// reported as missing, text fields are left empty.
+	f := splitLine(3, efdLine("C190", "000", "5102", "18,00", "1234,56"))
+	record := decodeC190(3, &f)
+	if record.OperationValue != 1234.56 || record.ObservationCode != "" {
+		t.Errorf("C190 = %+v", record)
+	}
+	var missing []string
+	for _, problem := range record.Problems {
+		missing = append(missing, problem.Field)
+	}
+	want := "VL_BC_ICMS VL_ICMS VL_BC_ICMS_ST VL_ICMS_ST VL_RED_BC VL_IPI"
+	if strings.Join(missing, " ") != want {
+		t.Errorf("campos ausentes = %v, esperado %s", missing, want)
+	}
+}
+
+func TestProblemsCheck(t *testing.T) {
+	f := splitLine(3, efdLine("C190", "000", "5102", "dezoito", "1234,56", "1100,00", "abc"))
+	problems := decodeC190(3, &f).Problems
+
+	tests := []struct {
+		name  string
+		names []string
+		want  string
+	}{
+		{"campos válidos", []string{"CFOP", "VL_OPR"}, ""},
+		{"sem campos", nil, ""},
+		{"campo inválido", []string{"VL_ICMS"}, "VL_ICMS"},
+		{"primeiro problema na ordem da linha", []string{"VL_ICMS", "ALIQ_ICMS"}, "ALIQ_ICMS"},
+		{"campo ausente", []string{"VL_IPI"}, "VL_IPI"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := problems.Check(tt.names...)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Check(%v) = %v, esperado nil", tt.names, err)
+				}
+				return
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || parseErr.Field != tt.want || parseErr.Line != 3 {
+				t.Errorf("Check(%v) = %v, esperado problema em %s na linha 3", tt.names, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	cause := errors.New("valor inválido")
+	tests := []struct {
+		err  *ParseError
+		want string
+	}{
+		{&ParseError{Line: 4, Err: cause}, "linha 4: valor inválido"},
+		{&ParseError{Line: 5, Register: "C170", Err: cause}, "linha 5 (registro C170): valor inválido"},
+		{&ParseError{Line: 6, Register: "C190", Field: "VL_ICMS", Err: cause}, "linha 6 (registro C190, campo VL_ICMS): valor inválido"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, esperado %q", got, tt.want)
+		}
+		if !errors.Is(tt.err, cause) {
+			t.Errorf("%v não desembrulha a causa", tt.err)
+		}
+	}
+}
@@ -0,0 +1,542 @@
+// internal/sped/registers.go
+package sped
+
+import "time"
+
+// Field names follow the EFD ICMS/IPI layout guide; the original field code is noted
+// where the Go name is not an obvious translation. Monetary values are in reais.
+
+// Record is one typed register returned by Reader.Next: *Reg0000, *Reg0150, *Reg0200,
+// *RegC100 (with its C170 and C190), *RegD100 (with its D190), *RegE100 (with its E110)
+// or *Reg9900.
+type Record interface {
+	// Register returns the register code, such as "C100".
+	Register() string
+}
+
+func (*Reg0000) Register() string { return "0000" }
+func (*Reg0150) Register() string { return "0150" }
+func (*Reg0200) Register() string { return "0200" }
+func (*RegC100) Register() string { return "C100" }
+func (*RegC170) Register() string { return "C170" }
+func (*RegC190) Register() string { return "C190" }
+func (*RegD100) Register() string { return "D100" }
+func (*RegD190) Register() string { return "D190" }
+func (*RegE100) Register() string { return "E100" }
+func (*RegE110) Register() string { return "E110" }
+func (*Reg9900) Register() string { return "9900" }
+
+// Reg0000 is the file opening record (Abertura do Arquivo Digital).
+type Reg0000 struct {
+	Line          int
+	Problems      Problems
+	LayoutVersion string    // COD_VER
+	Purpose       string    // COD_FIN: 0 original, 1 rectifying
+	Start         time.Time // DT_INI
+	End           time.Time // DT_FIN
+	Name          string
+	CNPJ          string
+	CPF           string
+	UF            string
+	IE            string
+	CityCode      string // COD_MUN (IBGE)
+	IM            string
+	SUFRAMA       string
+	Profile       string // IND_PERFIL: A, B or C
+	Activity      string // IND_ATIV: 0 industrial, 1 other
+}
+
+// Reg0150 is a participant (customer, supplier or carrier) referenced by documents.
+type Reg0150 struct {
+	Line        int
+	Problems    Problems
+	Code        string // COD_PART
+	Name        string
+	CountryCode string
+	CNPJ        string
+	CPF         string
+	IE          string
+	CityCode    string
+	SUFRAMA     string
+	Address     string
+	Number      string
+	Complement  string
+	District    string
+}
+
+// Reg0200 is an item (product or service) referenced by document items.
+type Reg0200 struct {
+	Line         int
+	Problems     Problems
+	Code         string // COD_ITEM
+	Description  string
+	Barcode      string
+	PreviousCode string // COD_ANT_ITEM
+	Unit         string // UNID_INV
+	ItemType     string // TIPO_ITEM
+	NCM          string
+	ExIPI        string
+	GenreCode    string // COD_GEN
+	ServiceCode  string // COD_LST
+	ICMSRate     float64
+	CEST         string
+}
+
+// RegC100 is an NF-e, NF or NFC-e document (models 01, 1B, 04, 55 and 65) with its items
+// (C170) and its summary by CST, CFOP and rate (C190).
+type RegC100 struct {
+	Line            int
+	Problems        Problems
+	Operation       string // IND_OPER: 0 entry, 1 exit
+	IssuerType      string // IND_EMIT: 0 own issue, 1 third party
+	ParticipantCode string
+	Model           string // COD_MOD
+	Situation       string // COD_SIT
+	Series          string
+	Number          string
+	AccessKey       string // CHV_NFE
+	IssueDate       time.Time
+	EntryExitDate   time.Time
+	Total           float64 // VL_DOC
+	PaymentType     string  // IND_PGTO
+	Discount        float64
+	NonTaxedRebate  float64 // VL_ABAT_NT
+	Merchandise     float64 // VL_MERC
+	FreightType     string  // IND_FRT
+	Freight         float64
+	Insurance       float64
+	OtherExpenses   float64 // VL_OUT_DA
+	ICMSBase        float64
+	ICMS            float64
+	STBase          float64
+	ST              float64 // VL_ICMS_ST
+	IPI             float64
+	PIS             float64
+	COFINS          float64
+	PISST           float64
+	COFINSST        float64
+
+	Items     []*RegC170
+	Summaries []*RegC190
+}
+
+// RegC170 is an item of a C100 document. Third-party NF-e usually have no C170.
+type RegC170 struct {
+	Line               int
+	Problems           Problems
+	Number             int // NUM_ITEM
+	ItemCode           string
+	Description        string // DESCR_COMPL
+	Quantity           float64
+	Unit               string
+	Value              float64 // VL_ITEM
+	Discount           float64
+	Movement           string // IND_MOV: 0 yes, 1 no
+	CSTICMS            string
+	CFOP               string
+	NatureCode         string // COD_NAT
+	ICMSBase           float64
+	ICMSRate           float64
+	ICMS               float64
+	STBase             float64
+	STRate             float64
+	ST                 float64
+	IPIPeriod          string // IND_APUR: 0 monthly, 1 ten-day
+	CSTIPI             string
+	IPIFramework       string // COD_ENQ
+	IPIBase            float64
+	IPIRate            float64
+	IPI                float64
+	CSTPIS             string
+	PISBase            float64
+	PISRate            float64
+	PISQuantityBase    float64
+	PISRateQuantity    float64 // ALIQ_PIS in reais
+	PIS                float64
+	CSTCOFINS          string
+	COFINSBase         float64
+	COFINSRate         float64
+	COFINSQuantityBase float64
+	COFINSRateQuantity float64 // ALIQ_COFINS in reais
+	COFINS             float64
+	AccountCode        string  // COD_CTA
+	NonTaxedRebate     float64 // VL_ABAT_NT
+
+	Document *RegC100
+}
+
+// RegC190 totals a C100 document by CST, CFOP and ICMS rate (Registro Analítico).
+type RegC190 struct {
+	Line            int
+	Problems        Problems
+	CSTICMS         string
+	CFOP            string
+	ICMSRate        float64
+	OperationValue  float64 // VL_OPR
+	ICMSBase        float64
+	ICMS            float64
+	STBase          float64
+	ST              float64
+	BaseReduction   float64 // VL_RED_BC
+	IPI             float64
+	ObservationCode string // COD_OBS
+
+	Document *RegC100
+}
+
+// RegD100 is a transport document, such as a CT-e (model 57) or CT-e OS (model 67), with
+// its summary by CST, CFOP and rate (D190).
+type RegD100 struct {
+	Line                int
+	Problems            Problems
+	Operation           string // IND_OPER: 0 acquisition, 1 provision
+	IssuerType          string // IND_EMIT: 0 own issue, 1 third party
+	ParticipantCode     string
+	Model               string // COD_MOD
+	Situation           string // COD_SIT
+	Series              string
+	Subseries           string
+	Number              string
+	AccessKey           string // CHV_CTE
+	IssueDate           time.Time
+	AcquisitionDate     time.Time // DT_A_P
+	CTeType             string    // TP_CT-e
+	ReferencedKey       string    // CHV_CTE_REF
+	Total               float64   // VL_DOC
+	Discount            float64
+	FreightType         string  // IND_FRT
+	Service             float64 // VL_SERV
+	ICMSBase            float64
+	ICMS                float64
+	NonTaxed            float64 // VL_NT
+	InformationCode     string  // COD_INF
+	AccountCode         string  // COD_CTA
+	OriginCityCode      string  // COD_MUN_ORIG (IBGE)
+	DestinationCityCode string  // COD_MUN_DEST (IBGE)
+
+	Summaries []*RegD190
+}
+
+// RegD190 totals a D100 document by CST, CFOP and ICMS rate (Registro Analítico).
+type RegD190 struct {
+	Line            int
+	Problems        Problems
+	CSTICMS         string
+	CFOP            string
+	ICMSRate        float64
+	OperationValue  float64 // VL_OPR
+	ICMSBase        float64
+	ICMS            float64
+	BaseReduction   float64 // VL_RED_BC
+	ObservationCode string  // COD_OBS
+
+	Document *RegD100
+}
+
+// RegE100 is an ICMS assessment period, detailed by its E110.
+type RegE100 struct {
+	Line     int
+	Problems Problems
+	Start    time.Time
+	End      time.Time
+
+	Assessment *RegE110
+}
+
+// RegE110 is the ICMS assessment (Apuração do ICMS - Operações Próprias) of a period.
+type RegE110 struct {
+	Line                   int
+	Problems               Problems
+	TotalDebits            float64 // VL_TOT_DEBITOS
+	DebitAdjustments       float64 // VL_AJ_DEBITOS
+	TotalDebitAdjustments  float64 // VL_TOT_AJ_DEBITOS
+	CreditReversals        float64 // VL_ESTORNOS_CRED
+	TotalCredits           float64 // VL_TOT_CREDITOS
+	CreditAdjustments      float64 // VL_AJ_CREDITOS
+	TotalCreditAdjustments float64 // VL_TOT_AJ_CREDITOS
+	DebitReversals         float64 // VL_ESTORNOS_DEB
+	PreviousCreditBalance  float64 // VL_SLD_CREDOR_ANT
+	AssessedBalance        float64 // VL_SLD_APURADO
+	TotalDeductions        float64 // VL_TOT_DED
+	ICMSDue                float64 // VL_ICMS_RECOLHER
+	CreditBalanceCarried   float64 // VL_SLD_CREDOR_TRANSPORTAR
+	SpecialDebits          float64 // DEB_ESP
+
+	Period *RegE100
+}
+
+// Reg9900 declares how many lines of a register the file contains.
+type Reg9900 struct {
+	Line     int
+	Problems Problems
+	Code     string // REG_BLC
+	Count    int    // QTD_REG_BLC
+}
+
+func decode0000(line int, f *fields) *Reg0000 {
+	record := &Reg0000{
+		Line:          line,
+		LayoutVersion: f.text(2),
+		Purpose:       f.text(3),
+		Start:         f.date(4),
+		End:           f.date(5),
+		Name:          f.text(6),
+		CNPJ:          f.text(7),
+		CPF:           f.text(8),
+		UF:            f.text(9),
+		IE:            f.text(10),
+		CityCode:      f.text(11),
+		IM:            f.text(12),
+		SUFRAMA:       f.text(13),
+		Profile:       f.text(14),
+		Activity:      f.text(15),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decode0150(line int, f *fields) *Reg0150 {
+	record := &Reg0150{
+		Line:        line,
+		Code:        f.text(2),
+		Name:        f.text(3),
+		CountryCode: f.text(4),
+		CNPJ:        f.text(5),
+		CPF:         f.text(6),
+		IE:          f.text(7),
+		CityCode:    f.text(8),
+		SUFRAMA:     f.text(9),
+		Address:     f.text(10),
+		Number:      f.text(11),
+		Complement:  f.text(12),
+		District:    f.text(13),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decode0200(line int, f *fields) *Reg0200 {
+	record := &Reg0200{
+		Line:         line,
+		Code:         f.text(2),
+		Description:  f.text(3),
+		Barcode:      f.text(4),
+		PreviousCode: f.text(5),
+		Unit:         f.text(6),
+		ItemType:     f.text(7),
+		NCM:          f.text(8),
+		ExIPI:        f.text(9),
+		GenreCode:    f.text(10),
+		ServiceCode:  f.text(11),
+		ICMSRate:     f.number(12),
+		CEST:         f.text(13),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeC100(line int, f *fields) *RegC100 {
+	record := &RegC100{
+		Line:            line,
+		Operation:       f.text(2),
+		IssuerType:      f.text(3),
+		ParticipantCode: f.text(4),
+		Model:           f.text(5),
+		Situation:       f.text(6),
+		Series:          f.text(7),
+		Number:          f.text(8),
+		AccessKey:       f.text(9),
+		IssueDate:       f.date(10),
+		EntryExitDate:   f.date(11),
+		Total:           f.number(12),
+		PaymentType:     f.text(13),
+		Discount:        f.number(14),
+		NonTaxedRebate:  f.number(15),
+		Merchandise:     f.number(16),
+		FreightType:     f.text(17),
+		Freight:         f.number(18),
+		Insurance:       f.number(19),
+		OtherExpenses:   f.number(20),
+		ICMSBase:        f.number(21),
+		ICMS:            f.number(22),
+		STBase:          f.number(23),
+		ST:              f.number(24),
+		IPI:             f.number(25),
+		PIS:             f.number(26),
+		COFINS:          f.number(27),
+		PISST:           f.number(28),
+		COFINSST:        f.number(29),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeC170(line int, f *fields) *RegC170 {
+	record := &RegC170{
+		Line:               line,
+		Number:             f.integer(2),
+		ItemCode:           f.text(3),
+		Description:        f.text(4),
+		Quantity:           f.number(5),
+		Unit:               f.text(6),
+		Value:              f.number(7),
+		Discount:           f.number(8),
+		Movement:           f.text(9),
+		CSTICMS:            f.text(10),
+		CFOP:               f.text(11),
+		NatureCode:         f.text(12),
+		ICMSBase:           f.number(13),
+		ICMSRate:           f.number(14),
+		ICMS:               f.number(15),
+		STBase:             f.number(16),
+		STRate:             f.number(17),
+		ST:                 f.number(18),
+		IPIPeriod:          f.text(19),
+		CSTIPI:             f.text(20),
+		IPIFramework:       f.text(21),
+		IPIBase:            f.number(22),
+		IPIRate:            f.number(23),
+		IPI:                f.number(24),
+		CSTPIS:             f.text(25),
+		PISBase:            f.number(26),
+		PISRate:            f.number(27),
+		PISQuantityBase:    f.number(28),
+		PISRateQuantity:    f.number(29),
+		PIS:                f.number(30),
+		CSTCOFINS:          f.text(31),
+		COFINSBase:         f.number(32),
+		COFINSRate:         f.number(33),
+		COFINSQuantityBase: f.number(34),
+		COFINSRateQuantity: f.number(35),
+		COFINS:             f.number(36),
+		AccountCode:        f.text(37),
+		NonTaxedRebate:     f.number(38),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeC190(line int, f *fields) *RegC190 {
+	record := &RegC190{
+		Line:            line,
+		CSTICMS:         f.text(2),
+		CFOP:            f.text(3),
+		ICMSRate:        f.number(4),
+		OperationValue:  f.number(5),
+		ICMSBase:        f.number(6),
+		ICMS:            f.number(7),
+		STBase:          f.number(8),
+		ST:              f.number(9),
+		BaseReduction:   f.number(10),
+		IPI:             f.number(11),
+		ObservationCode: f.text(12),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeD100(line int, f *fields) *RegD100 {
+	record := &RegD100{
+		Line:                line,
+		Operation:           f.text(2),
+		IssuerType:          f.text(3),
+		ParticipantCode:     f.text(4),
+		Model:               f.text(5),
+		Situation:           f.text(6),
+		Series:              f.text(7),
+		Subseries:           f.text(8),
+		Number:              f.text(9),
+		AccessKey:           f.text(10),
+		IssueDate:           f.date(11),
+		AcquisitionDate:     f.date(12),
+		CTeType:             f.text(13),
+		ReferencedKey:       f.text(14),
+		Total:               f.number(15),
+		Discount:            f.number(16),
+		FreightType:         f.text(17),
+		Service:             f.number(18),
+		ICMSBase:            f.number(19),
+		ICMS:                f.number(20),
+		NonTaxed:            f.number(21),
+		InformationCode:     f.text(22),
+		AccountCode:         f.text(23),
+		OriginCityCode:      f.text(24),
+		DestinationCityCode: f.text(25),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeD190(line int, f *fields) *RegD190 {
+	record := &RegD190{
+		Line:            line,
+		CSTICMS:         f.text(2),
+		CFOP:            f.text(3),
+		ICMSRate:        f.number(4),
+		OperationValue:  f.number(5),
+		ICMSBase:        f.number(6),
+		ICMS:            f.number(7),
+		BaseReduction:   f.number(8),
+		ObservationCode: f.text(9),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeE100(line int, f *fields) *RegE100 {
+	record := &RegE100{
+		Line:  line,
+		Start: f.date(2),
+		End:   f.date(3),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decodeE110(line int, f *fields) *RegE110 {
+	record := &RegE110{
+		Line:                   line,
+		TotalDebits:            f.number(2),
+		DebitAdjustments:       f.number(3),
+		TotalDebitAdjustments:  f.number(4),
+		CreditReversals:        f.number(5),
+		TotalCredits:           f.number(6),
+		CreditAdjustments:      f.number(7),
+		TotalCreditAdjustments: f.number(8),
+		DebitReversals:         f.number(9),
+		PreviousCreditBalance:  f.number(10),
+		AssessedBalance:        f.number(11),
+		TotalDeductions:        f.number(12),
+		ICMSDue:                f.number(13),
+		CreditBalanceCarried:   f.number(14),
+		SpecialDebits:          f.number(15),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+func decode9900(line int, f *fields) *Reg9900 {
+	record := &Reg9900{
+		Line:  line,
+		Code:  f.text(2),
+		Count: f.integer(3),
+	}
+	record.Problems = f.problems
+	return record
+}
+
+// layouts names the fields of each typed register after the EFD layout guide, indexed by
+// field number (REG is 1), to label conversion problems.
+var layouts = map[string][]string{
+	"0000": {"", "REG", "COD_VER", "COD_FIN", "DT_INI", "DT_FIN", "NOME", "CNPJ", "CPF", "UF", "IE", "COD_MUN", "IM", "SUFRAMA", "IND_PERFIL", "IND_ATIV"},
+	"0150": {"", "REG", "COD_PART", "NOME", "COD_PAIS", "CNPJ", "CPF", "IE", "COD_MUN", "SUFRAMA", "END", "NUM", "COMPL", "BAIRRO"},
+	"0200": {"", "REG", "COD_ITEM", "DESCR_ITEM", "COD_BARRA", "COD_ANT_ITEM", "UNID_INV", "TIPO_ITEM", "COD_NCM", "EX_IPI", "COD_GEN", "COD_LST", "ALIQ_ICMS", "CEST"},
+	"C100": {"", "REG", "IND_OPER", "IND_EMIT", "COD_PART", "COD_MOD", "COD_SIT", "SER", "NUM_DOC", "CHV_NFE", "DT_DOC", "DT_E_S", "VL_DOC", "IND_PGTO", "VL_DESC", "VL_ABAT_NT", "VL_MERC", "IND_FRT", "VL_FRT", "VL_SEG", "VL_OUT_DA", "VL_BC_ICMS", "VL_ICMS", "VL_BC_ICMS_ST", "VL_ICMS_ST", "VL_IPI", "VL_PIS", "VL_COFINS", "VL_PIS_ST", "VL_COFINS_ST"},
+	"C170": {"", "REG", "NUM_ITEM", "COD_ITEM", "DESCR_COMPL", "QTD", "UNID", "VL_ITEM", "VL_DESC", "IND_MOV", "CST_ICMS", "CFOP", "COD_NAT", "VL_BC_ICMS", "ALIQ_ICMS", "VL_ICMS", "VL_BC_ICMS_ST", "ALIQ_ST", "VL_ICMS_ST", "IND_APUR", "CST_IPI", "COD_ENQ", "VL_BC_IPI", "ALIQ_IPI", "VL_IPI", "CST_PIS", "VL_BC_PIS", "ALIQ_PIS (%)", "QUANT_BC_PIS", "ALIQ_PIS (R$)", "VL_PIS", "CST_COFINS", "VL_BC_COFINS", "ALIQ_COFINS (%)", "QUANT_BC_COFINS", "ALIQ_COFINS (R$)", "VL_COFINS", "COD_CTA", "VL_ABAT_NT"},
+	"C190": {"", "REG", "CST_ICMS", "CFOP", "ALIQ_ICMS", "VL_OPR", "VL_BC_ICMS", "VL_ICMS", "VL_BC_ICMS_ST", "VL_ICMS_ST", "VL_RED_BC", "VL_IPI", "COD_OBS"},
+	"D100": {"", "REG", "IND_OPER", "IND_EMIT", "COD_PART", "COD_MOD", "COD_SIT", "SER", "SUB", "NUM_DOC", "CHV_CTE", "DT_DOC", "DT_A_P", "TP_CT-e", "CHV_CTE_REF", "VL_DOC", "VL_DESC", "IND_FRT", "VL_SERV", "VL_BC_ICMS", "VL_ICMS", "VL_NT", "COD_INF", "COD_CTA", "COD_MUN_ORIG", "COD_MUN_DEST"},
+	"D190": {"", "REG", "CST_ICMS", "CFOP", "ALIQ_ICMS", "VL_OPR", "VL_BC_ICMS", "VL_ICMS", "VL_RED_BC", "COD_OBS"},
+	"E100": {"", "REG", "DT_INI", "DT_FIN"},
+	"E110": {"", "REG", "VL_TOT_DEBITOS", "VL_AJ_DEBITOS", "VL_TOT_AJ_DEBITOS", "VL_ESTORNOS_CRED", "VL_TOT_CREDITOS", "VL_AJ_CREDITOS", "VL_TOT_AJ_CREDITOS", "VL_ESTORNOS_DEB", "VL_SLD_CREDOR_ANT", "VL_SLD_APURADO", "VL_TOT_DED", "VL_ICMS_RECOLHER", "VL_SLD_CREDOR_TRANSPORTAR", "DEB_ESP"},
+	"9900": {"", "REG", "REG_BLC", "QTD_REG_BLC"},
+}
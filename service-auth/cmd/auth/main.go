@@ -2,98 +2,227 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
-	"strings"
 
 	"auth-service/internal/api/handlers"
+	"auth-service/internal/api/middleware"
 	"auth-service/internal/api/responses"
+	"auth-service/internal/bootstrap"
+	"auth-service/internal/config"
 	"auth-service/internal/core/auth"
+	"auth-service/internal/core/notify"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
 )
 
 // --- Helper Functions ---
 
-func initFirestoreClient(ctx context.Context) *firestore.Client {
-	projectID := "analise-sped-db"
-	databaseID := "analise-sped-db"
-	client, err := firestore.NewClientWithDatabase(ctx, projectID, databaseID)
-	if err != nil {
-		log.Fatalf("Erro ao inicializar cliente Firestore: %v\n", err)
+// initNotifier escolhe o canal de entrega de notificações conforme NOTIFIER:
+// "log" (padrão, apenas para desenvolvimento) ou "smtp", configurado pelas variáveis SMTP_*.
+func initNotifier(cfg config.Notifier) notify.Notifier {
+	if cfg.Kind == "smtp" {
+		return notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
 	}
-	log.Printf("Conectado com sucesso ao Firestore")
-	return client
+	log.Print("Notificações serão apenas registradas no log (NOTIFIER=log)")
+	return notify.NewLogNotifier()
 }
 
-func loadEnv() {
-	file, err := os.Open(".env")
+// --- Main Service Runner ---
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
 	if err != nil {
-		if os.IsNotExist(err) {
-			log.Print("Arquivo .env não encontrado, prosseguindo com variáveis de ambiente")
-		} else {
-			log.Printf("Erro ao carregar .env: %v", err)
-		}
-		return
+		log.Fatalf("FATAL: configuração inválida: %v", err)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+	responses.InitLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stores, closeStores, err := bootstrap.OpenStores(ctx, cfg)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	defer closeStores()
 
-		if _, exists := os.LookupEnv(key); !exists {
-			os.Setenv(key, value)
-		}
+	passwordPolicy, err := bootstrap.PasswordPolicy(cfg)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	roleGroups, err := bootstrap.RoleGroups(cfg)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
 	}
-	log.Print("Variáveis de ambiente carregadas de .env")
-}
 
-// --- Main Service Runner ---
-func main() {
-	loadEnv()
+	tokenConfig := auth.TokenConfig{
+		AccessTokenTTL:  cfg.Tokens.AccessTTL,
+		RefreshTokenTTL: cfg.Tokens.RefreshTTL,
+		MFAChallengeTTL: cfg.Tokens.MFAChallengeTTL,
+		Issuer:          cfg.Tokens.Issuer,
+		Audience:        cfg.Tokens.Audience,
+	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("FATAL: Variável de ambiente JWT_SECRET não está configurada.")
+	keyManager, err := auth.NewKeyManager(ctx, stores.SigningKeys, bootstrap.KeyConfig(cfg))
+	if err != nil {
+		log.Fatalf("Erro ao inicializar chaves de assinatura: %v\n", err)
+	}
+	keyManager.Start(ctx)
+
+	throttleConfig := auth.ThrottleConfig{
+		MaxUserFailures: cfg.Login.MaxUserFailures,
+		MaxIPFailures:   cfg.Login.MaxIPFailures,
+		LockoutDuration: cfg.Login.LockoutDuration,
+		BaseDelay:       cfg.Login.BackoffBase,
+		MaxDelay:        cfg.Login.BackoffMax,
+		FailureWindow:   cfg.Login.FailureWindow,
 	}
 
-	responses.InitLogger()
-	ctx := context.Background()
-	firestoreClient := initFirestoreClient(ctx)
-	defer firestoreClient.Close()
+	oidcConfig := auth.OIDCConfig{
+		StateTTL: cfg.OIDC.StateTTL,
+	}
+	if path := cfg.OIDC.ProvidersFile; path != "" {
+		providers, err := auth.LoadOIDCProviders(path, roleGroups)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		oidcConfig.Providers = providers
+		log.Printf("%d provedores OIDC carregados de %s", len(providers), path)
+	}
+
+	var ldapConfig *auth.LDAPConfig
+	if path := cfg.LDAP.ConfigFile; path != "" {
+		ldapConfig, err = auth.LoadLDAPConfig(path, roleGroups)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		log.Printf("Autenticação no diretório LDAP %s habilitada", ldapConfig.URL)
+	}
 
-	authService := auth.NewService(firestoreClient, []byte(jwtSecret))
+	authService := auth.NewService(stores, keyManager, tokenConfig, throttleConfig, oidcConfig, ldapConfig, roleGroups, passwordPolicy)
 	authHandler := handlers.NewAuthHandler(authService)
+	sessionService := authService.Sessions()
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	adminHandler := handlers.NewAdminHandler(auth.NewAdminService(stores.Users, stores.LoginAttempts, stores.Tenants, sessionService, stores.AuditEvents, roleGroups, passwordPolicy))
+	passwordHandler := handlers.NewPasswordHandler(auth.NewPasswordService(stores.Users, stores.PasswordResets, stores.AuditEvents, initNotifier(cfg.Notifier), auth.PasswordConfig{
+		ResetTTL: cfg.Passwords.ResetTTL,
+		ResetURL: cfg.Passwords.ResetURL,
+		Policy:   passwordPolicy,
+	}))
+	serviceAccountHandler := handlers.NewServiceAccountHandler(auth.NewServiceAccountService(stores.ServiceAccounts, stores.AuditEvents, roleGroups))
+	oidcHandler := handlers.NewOIDCHandler(authService, cfg.OIDC.FrontendURL)
+	tenantHandler := handlers.NewTenantHandler(auth.NewTenantService(stores.Tenants, stores.Users, stores.AuditEvents))
+	auditHandler := handlers.NewAuditHandler(auth.NewAuditService(stores.AuditEvents))
+	mfaHandler := handlers.NewMFAHandler(auth.NewMFAService(stores.Users, authService, stores.AuditEvents, auth.MFAConfig{
+		Issuer: cfg.MFA.TOTPIssuer,
+	}))
 
 	router := gin.Default()
 
+	// O IP do cliente vem do X-Forwarded-For enviado pelo gateway; só proxies da rede interna são confiáveis.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("FATAL: TRUSTED_PROXIES inválido: %v", err)
+	}
+	router.Use(middleware.RequestInfo())
+
 	apiV1 := router.Group("/api/v1")
 	{
 		apiV1.POST("/login", authHandler.Login)
+		apiV1.POST("/token/refresh", authHandler.Refresh)
+		apiV1.POST("/token/api-key", authHandler.ExchangeAPIKey)
+		apiV1.POST("/logout", middleware.RequireAuth(authService), authHandler.Logout)
+		apiV1.GET("/me", middleware.RequireAuth(authService), authHandler.Me)
+		apiV1.GET("/tenants", middleware.RequireAuth(authService), tenantHandler.Mine)
+		apiV1.POST("/tenants/switch", middleware.RequireAuth(authService), authHandler.SwitchTenant)
+		apiV1.GET("/sessions", middleware.RequireAuth(authService), sessionHandler.Mine)
+		apiV1.DELETE("/sessions", middleware.RequireAuth(authService), sessionHandler.RevokeAllMine)
+		apiV1.DELETE("/sessions/:id", middleware.RequireAuth(authService), sessionHandler.RevokeMine)
+		apiV1.POST("/password/change", middleware.RequireAuth(authService), passwordHandler.Change)
+		apiV1.POST("/password/reset/request", passwordHandler.RequestReset)
+		apiV1.POST("/password/reset/confirm", passwordHandler.ConfirmReset)
+		apiV1.POST("/mfa/verify", authHandler.VerifyMFA)
+
+		apiV1.GET("/oidc/providers", oidcHandler.Providers)
+		apiV1.GET("/oidc/:provider/authorize", oidcHandler.Authorize)
+		apiV1.GET("/oidc/:provider/callback", oidcHandler.Callback)
+
+		mfa := apiV1.Group("/mfa", middleware.RequireAuth(authService))
+		{
+			mfa.POST("/totp/enroll", mfaHandler.Enroll)
+			mfa.POST("/totp/confirm", mfaHandler.Confirm)
+			mfa.POST("/totp/disable", mfaHandler.Disable)
+			mfa.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		}
+
+		// Interno: consultado pelo gateway e pelos serviços Go, não exposto externamente. Exige o
+		// token de uma conta de serviço com a role token-introspect (RFC 7662, seção 2.1).
+		internal := apiV1.Group("", middleware.RequireAuth(authService), middleware.RequireServiceAccount(), middleware.RequireRole(auth.RoleIntrospect))
+		{
+			internal.GET("/revoked/:jti", authHandler.CheckRevoked)
+			internal.POST("/introspect", authHandler.Introspect)
+		}
+
+		admin := apiV1.Group("/admin", middleware.RequireAuth(authService), middleware.RequireRole(auth.RoleAdmin))
+		{
+			admin.GET("/roles", adminHandler.ListRoles)
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.POST("/users", adminHandler.CreateUser)
+			admin.GET("/users/:username", adminHandler.GetUser)
+			admin.PATCH("/users/:username", adminHandler.UpdateUser)
+			admin.DELETE("/users/:username", adminHandler.DeleteUser)
+			admin.POST("/users/:username/disable", adminHandler.DisableUser)
+			admin.POST("/users/:username/enable", adminHandler.EnableUser)
+			admin.POST("/users/:username/roles", adminHandler.AssignRole)
+			admin.DELETE("/users/:username/roles/:role", adminHandler.RevokeRole)
+			admin.POST("/users/:username/tenants", adminHandler.AssignTenant)
+			admin.DELETE("/users/:username/tenants/:tenant", adminHandler.RevokeTenant)
+			admin.POST("/users/:username/password", adminHandler.ResetPassword)
+			admin.POST("/users/:username/unlock", adminHandler.UnlockUser)
+			admin.DELETE("/users/:username/mfa", adminHandler.ResetMFA)
+			admin.DELETE("/users/:username/identities/:id", adminHandler.UnlinkIdentity)
+			admin.GET("/users/:username/sessions", sessionHandler.ListForUser)
+			admin.DELETE("/users/:username/sessions", sessionHandler.RevokeAllForUser)
+			admin.DELETE("/users/:username/sessions/:id", sessionHandler.RevokeForUser)
+			admin.POST("/ips/:ip/unlock", adminHandler.UnlockIP)
+			admin.GET("/audit", auditHandler.Query)
+
+			admin.GET("/tenants", tenantHandler.List)
+			admin.POST("/tenants", tenantHandler.Create)
+			admin.GET("/tenants/:id", tenantHandler.Get)
+			admin.PATCH("/tenants/:id", tenantHandler.Update)
+			admin.DELETE("/tenants/:id", tenantHandler.Delete)
+
+			admin.GET("/service-accounts", serviceAccountHandler.ListAccounts)
+			admin.POST("/service-accounts", serviceAccountHandler.CreateAccount)
+			admin.GET("/service-accounts/:name", serviceAccountHandler.GetAccount)
+			admin.PATCH("/service-accounts/:name", serviceAccountHandler.UpdateAccount)
+			admin.DELETE("/service-accounts/:name", serviceAccountHandler.DeleteAccount)
+			admin.GET("/service-accounts/:name/keys", serviceAccountHandler.ListKeys)
+			admin.POST("/service-accounts/:name/keys", serviceAccountHandler.CreateKey)
+			admin.DELETE("/service-accounts/:name/keys/:id", serviceAccountHandler.RevokeKey)
+		}
 	}
 
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "UP", "service": "auth-service"})
 	})
 
-	// Run on different port
-	const port = "8081"
-	log.Printf("🚀 Auth Service (Go) iniciado e escutando na porta %s", port)
-	if err := router.Run(":" + port); err != nil {
+	log.Printf("🚀 Auth Service (Go) iniciado e escutando na porta %d", cfg.Port)
+	if err := router.Run(fmt.Sprintf(":%d", cfg.Port)); err != nil {
 		log.Fatal("Falha ao iniciar o servidor de autenticação: ", err)
 	}
 }
@@ -0,0 +1,163 @@
+// cmd/authctl/import.go
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"auth-service/internal/core/auth"
+)
+
+// importColumns são as colunas aceitas no CSV; username e password são obrigatórias.
+// Roles e empresas são separadas por ";" dentro da célula.
+var importColumns = []string{"username", "password", "roles", "email", "tenants"}
+
+// importUsers cria os usuários de um CSV com cabeçalho. Usuários existentes são ignorados,
+// ou atualizados com -update: as colunas presentes no CSV substituem roles, e-mail e empresas,
+// e a senha é redefinida quando informada (pode ficar vazia na atualização). Cada linha é
+// processada de forma independente; o comando falha se alguma linha falhar.
+func (c *cli) importUsers(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("user import", flag.ContinueOnError)
+	update := flags.Bool("update", false, "atualiza os usuários já existentes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("informe o arquivo CSV")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("erro ao ler o cabeçalho do CSV: %w", err)
+	}
+	index, err := importHeader(header)
+	if err != nil {
+		return err
+	}
+
+	var created, updated, skipped, failed int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "linha %d: %v\n", line, err)
+			failed++
+			continue
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		input := auth.CreateUserInput{
+			Username: field("username"),
+			Password: field("password"),
+			Roles:    splitCell(field("roles")),
+			Tenants:  splitCell(field("tenants")),
+			Email:    field("email"),
+		}
+		if *update {
+			if _, err := c.admin.GetUser(ctx, input.Username); err == nil {
+				if err := c.updateImported(ctx, input, index); err != nil {
+					fmt.Fprintf(os.Stderr, "linha %d: %s: %v\n", line, input.Username, err)
+					failed++
+					continue
+				}
+				updated++
+				continue
+			}
+		}
+
+		_, err = c.admin.CreateUser(ctx, input)
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, auth.ErrUserExists):
+			fmt.Fprintf(os.Stderr, "linha %d: %s já existe, ignorado\n", line, input.Username)
+			skipped++
+		default:
+			fmt.Fprintf(os.Stderr, "linha %d: %s: %v\n", line, input.Username, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("Importação concluída: %d criados, %d atualizados, %d ignorados, %d com erro\n", created, updated, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d linha(s) com erro", failed)
+	}
+	return nil
+}
+
+// updateImported aplica a linha do CSV a um usuário existente, apenas nas colunas presentes.
+func (c *cli) updateImported(ctx context.Context, input auth.CreateUserInput, index map[string]int) error {
+	var change auth.UpdateUserInput
+	if _, ok := index["roles"]; ok {
+		change.Roles = &input.Roles
+	}
+	if _, ok := index["tenants"]; ok {
+		change.Tenants = &input.Tenants
+	}
+	if _, ok := index["email"]; ok {
+		change.Email = &input.Email
+	}
+	if _, err := c.admin.UpdateUser(ctx, input.Username, change); err != nil {
+		return err
+	}
+	if input.Password == "" {
+		return nil
+	}
+	return c.admin.ResetPassword(ctx, input.Username, input.Password)
+}
+
+// importHeader mapeia o nome de cada coluna para sua posição.
+func importHeader(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, column := range importColumns {
+			known = known || column == name
+		}
+		if !known {
+			return nil, fmt.Errorf("coluna desconhecida no CSV: %q (use %s)", name, strings.Join(importColumns, ", "))
+		}
+		index[name] = i
+	}
+	if _, ok := index["username"]; !ok {
+		return nil, errors.New("o CSV deve ter a coluna username")
+	}
+	if _, ok := index["password"]; !ok {
+		return nil, errors.New("o CSV deve ter a coluna password")
+	}
+	return index, nil
+}
+
+// splitCell separa os itens de uma célula por ";".
+func splitCell(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
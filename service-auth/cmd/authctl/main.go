@@ -0,0 +1,351 @@
+// cmd/authctl/main.go
+
+// authctl administra usuários, contas de serviço e chaves de assinatura diretamente no
+// armazenamento configurado para o serviço de autenticação (USER_STORE e variáveis
+// relacionadas, inclusive via .env ou CONFIG_FILE), sem passar pela API. As alterações passam pelas
+// mesmas validações da API de administração e são auditadas com o ator "authctl:<usuário>".
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"auth-service/internal/bootstrap"
+	"auth-service/internal/config"
+	"auth-service/internal/core/auth"
+)
+
+const usage = `Uso: authctl <comando> [opções]
+
+Usuários:
+  user list
+  user create -username <u> [-roles a,b] [-tenants x,y] [-email e]   (senha lida da entrada padrão)
+  user disable <u>
+  user enable <u>
+  user set-password <u>                                              (senha lida da entrada padrão)
+  user grant <u> <role>...
+  user revoke <u> <role>...
+  user import [-update] <arquivo.csv>
+
+Contas de serviço e chaves de API:
+  account create -name <n> [-roles a,b] [-description d]
+  apikey create -account <n> [-roles a,b] [-expires 720h]
+  apikey revoke <conta> <id>
+
+Chaves de assinatura:
+  keys list
+  keys rotate
+`
+
+// cli agrupa os serviços usados pelos comandos.
+type cli struct {
+	config   *config.Config
+	stores   auth.Stores
+	admin    auth.AdminService
+	accounts auth.ServiceAccountService
+	stdin    *bufio.Reader
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("authctl: ")
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// As flags ficam para os subcomandos; o arquivo YAML vem de CONFIG_FILE.
+	cfg, err := config.Load(nil)
+	if err != nil {
+		log.Fatalf("configuração inválida: %v", err)
+	}
+	ctx := auth.WithActor(context.Background(), "authctl:"+operator())
+	stores, closeStores, err := bootstrap.OpenStores(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeStores()
+	passwordPolicy, err := bootstrap.PasswordPolicy(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	roleGroups, err := bootstrap.RoleGroups(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Desativar um usuário encerra as suas sessões, como na API de administração.
+	sessions := auth.NewSessionService(stores.Sessions, stores.RefreshTokens, stores.Revocations, stores.AuditEvents, cfg.Tokens.AccessTTL)
+	c := &cli{
+		config:   cfg,
+		stores:   stores,
+		admin:    auth.NewAdminService(stores.Users, stores.LoginAttempts, stores.Tenants, sessions, stores.AuditEvents, roleGroups, passwordPolicy),
+		accounts: auth.NewServiceAccountService(stores.ServiceAccounts, stores.AuditEvents, roleGroups),
+		stdin:    bufio.NewReader(os.Stdin),
+	}
+	if err := c.run(ctx, os.Args[1], os.Args[2], os.Args[3:]); err != nil {
+		closeStores()
+		log.Fatal(err)
+	}
+}
+
+func (c *cli) run(ctx context.Context, group, command string, args []string) error {
+	switch group + " " + command {
+	case "user list":
+		return c.listUsers(ctx)
+	case "user create":
+		return c.createUser(ctx, args)
+	case "user disable":
+		return c.setDisabled(ctx, args, true)
+	case "user enable":
+		return c.setDisabled(ctx, args, false)
+	case "user set-password":
+		return c.setPassword(ctx, args)
+	case "user grant":
+		return c.changeRoles(ctx, args, c.admin.AssignRole)
+	case "user revoke":
+		return c.changeRoles(ctx, args, c.admin.RevokeRole)
+	case "user import":
+		return c.importUsers(ctx, args)
+	case "account create":
+		return c.createAccount(ctx, args)
+	case "apikey create":
+		return c.createAPIKey(ctx, args)
+	case "apikey revoke":
+		return c.revokeAPIKey(ctx, args)
+	case "keys list":
+		return c.listKeys(ctx)
+	case "keys rotate":
+		return c.rotateKeys(ctx)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("comando desconhecido: %s %s", group, command)
+	}
+}
+
+func (c *cli) listUsers(ctx context.Context) error {
+	users, err := c.admin.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		status := "ativo"
+		if u.Disabled {
+			status = "desativado"
+		}
+		fmt.Printf("%s\t%s\troles=%s\ttenants=%s\t%s\n", u.Username, status, strings.Join(u.Roles, ","), strings.Join(u.Tenants, ","), u.Email)
+	}
+	return nil
+}
+
+func (c *cli) createUser(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	username := flags.String("username", "", "username do novo usuário")
+	roles := flags.String("roles", "", "roles separadas por vírgula")
+	tenants := flags.String("tenants", "", "empresas separadas por vírgula")
+	email := flags.String("email", "", "e-mail do usuário")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("informe -username")
+	}
+	password, err := c.readPassword()
+	if err != nil {
+		return err
+	}
+
+	info, err := c.admin.CreateUser(ctx, auth.CreateUserInput{
+		Username: *username,
+		Password: password,
+		Roles:    splitList(*roles),
+		Tenants:  splitList(*tenants),
+		Email:    *email,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Usuário %s criado (roles: %s)\n", info.Username, strings.Join(info.Roles, ","))
+	return nil
+}
+
+func (c *cli) setDisabled(ctx context.Context, args []string, disabled bool) error {
+	if len(args) != 1 {
+		return errors.New("informe o username")
+	}
+	if _, err := c.admin.UpdateUser(ctx, args[0], auth.UpdateUserInput{Disabled: &disabled}); err != nil {
+		return err
+	}
+	if disabled {
+		fmt.Printf("Usuário %s desativado\n", args[0])
+	} else {
+		fmt.Printf("Usuário %s reativado\n", args[0])
+	}
+	return nil
+}
+
+func (c *cli) setPassword(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("informe o username")
+	}
+	password, err := c.readPassword()
+	if err != nil {
+		return err
+	}
+	if err := c.admin.ResetPassword(ctx, args[0], password); err != nil {
+		return err
+	}
+	fmt.Printf("Senha de %s redefinida\n", args[0])
+	return nil
+}
+
+func (c *cli) changeRoles(ctx context.Context, args []string, change func(ctx context.Context, username, role string) (*auth.UserInfo, error)) error {
+	if len(args) < 2 {
+		return errors.New("informe o username e ao menos uma role")
+	}
+	var info *auth.UserInfo
+	for _, role := range args[1:] {
+		var err error
+		if info, err = change(ctx, args[0], role); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Roles de %s: %s\n", info.Username, strings.Join(info.Roles, ","))
+	return nil
+}
+
+func (c *cli) createAccount(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("account create", flag.ContinueOnError)
+	name := flags.String("name", "", "nome da conta de serviço")
+	roles := flags.String("roles", "", "roles separadas por vírgula")
+	description := flags.String("description", "", "descrição da conta")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	info, err := c.accounts.CreateAccount(ctx, auth.CreateServiceAccountInput{
+		Name:        *name,
+		Description: *description,
+		Roles:       splitList(*roles),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Conta de serviço %s criada (roles: %s)\n", info.Name, strings.Join(info.Roles, ","))
+	return nil
+}
+
+func (c *cli) createAPIKey(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	account := flags.String("account", "", "conta de serviço dona da chave")
+	roles := flags.String("roles", "", "roles da chave (padrão: todas as da conta)")
+	expires := flags.Duration("expires", 0, "validade da chave (ex.: 720h); 0 não expira")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *account == "" {
+		return errors.New("informe -account")
+	}
+
+	var input auth.CreateAPIKeyInput
+	if *roles != "" {
+		input.Roles = splitList(*roles)
+	}
+	if *expires > 0 {
+		expiresAt := time.Now().Add(*expires)
+		input.ExpiresAt = &expiresAt
+	}
+	key, err := c.accounts.CreateKey(ctx, *account, input)
+	if err != nil {
+		return err
+	}
+	// A chave vai sozinha para a saída padrão, para uso em scripts; o restante, para o log.
+	log.Printf("Chave %s criada para %s (roles: %s); ela não será exibida novamente", key.ID, key.Account, strings.Join(key.Roles, ","))
+	fmt.Println(key.Key)
+	return nil
+}
+
+func (c *cli) revokeAPIKey(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("informe a conta e o id da chave")
+	}
+	if err := c.accounts.RevokeKey(ctx, args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Chave %s revogada\n", args[1])
+	return nil
+}
+
+func (c *cli) listKeys(ctx context.Context) error {
+	keys, err := c.stores.SigningKeys.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		status := "ativa"
+		if !key.RetiredAt.IsZero() {
+			status = "aposentada em " + key.RetiredAt.Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%s\t%s\tcriada em %s\t%s\n", key.KID, key.Algorithm, key.CreatedAt.Format("2006-01-02 15:04"), status)
+	}
+	return nil
+}
+
+// rotateKeys cria uma nova chave ativa. As instâncias em execução passam a assinar com ela
+// na próxima verificação periódica das chaves.
+func (c *cli) rotateKeys(ctx context.Context) error {
+	keys, err := auth.NewKeyManager(ctx, c.stores.SigningKeys, bootstrap.KeyConfig(c.config))
+	if err != nil {
+		return err
+	}
+	if err := keys.Rotate(ctx); err != nil {
+		return err
+	}
+	jwks := keys.JWKS()
+	fmt.Printf("Chaves rotacionadas; %d chave(s) publicada(s) no JWKS\n", len(jwks.Keys))
+	return nil
+}
+
+// readPassword lê a senha da primeira linha da entrada padrão.
+func (c *cli) readPassword() (string, error) {
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Senha: ")
+	}
+	line, err := c.stdin.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", auth.ErrEmptyPassword
+	}
+	return password, nil
+}
+
+// operator identifica quem executa o authctl nos eventos de auditoria.
+func operator() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return "desconhecido"
+}
+
+// splitList separa uma lista por vírgulas, ignorando itens vazios.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
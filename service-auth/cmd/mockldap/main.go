@@ -0,0 +1,81 @@
+// cmd/mockldap/main.go
+
+// Servidor LDAP de teste para desenvolvimento da autenticação por diretório, usando o
+// servidor em processo de internal/ldaptest. Sem MOCK_LDAP_DIRECTORY, serve um diretório
+// no formato do Active Directory (base dc=exemplo,dc=local) com a conta de serviço
+// svc-auth e os usuários maria e joao, todos com a senha "segredo". Nunca deve ser exposto
+// em produção.
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"auth-service/internal/ldaptest"
+)
+
+// sampleDirectory imita um Active Directory pequeno: usuários com sAMAccountName, mail e
+// memberOf apontando para os grupos.
+var sampleDirectory = []ldaptest.Entry{
+	{DN: "dc=exemplo,dc=local", Attributes: map[string][]string{"objectClass": {"top", "domain"}}},
+	{DN: "ou=Usuarios,dc=exemplo,dc=local", Attributes: map[string][]string{"objectClass": {"top", "organizationalUnit"}}},
+	{DN: "ou=Grupos,dc=exemplo,dc=local", Attributes: map[string][]string{"objectClass": {"top", "organizationalUnit"}}},
+	{
+		DN:       "cn=svc-auth,ou=Usuarios,dc=exemplo,dc=local",
+		Password: "segredo",
+		Attributes: map[string][]string{
+			"objectClass":    {"top", "person", "organizationalPerson", "user"},
+			"sAMAccountName": {"svc-auth"},
+		},
+	},
+	{
+		DN:       "cn=Maria Souza,ou=Usuarios,dc=exemplo,dc=local",
+		Password: "segredo",
+		Attributes: map[string][]string{
+			"objectClass":    {"top", "person", "organizationalPerson", "user"},
+			"objectCategory": {"person"},
+			"sAMAccountName": {"maria"},
+			"mail":           {"maria@exemplo.local"},
+			"memberOf":       {"cn=Fiscal,ou=Grupos,dc=exemplo,dc=local", "cn=Financeiro,ou=Grupos,dc=exemplo,dc=local"},
+		},
+	},
+	{
+		DN:       "cn=Joao Lima,ou=Usuarios,dc=exemplo,dc=local",
+		Password: "segredo",
+		Attributes: map[string][]string{
+			"objectClass":    {"top", "person", "organizationalPerson", "user"},
+			"objectCategory": {"person"},
+			"sAMAccountName": {"joao"},
+			"mail":           {"joao@exemplo.local"},
+		},
+	},
+	{DN: "cn=Fiscal,ou=Grupos,dc=exemplo,dc=local", Attributes: map[string][]string{"objectClass": {"top", "group"}}},
+	{DN: "cn=Financeiro,ou=Grupos,dc=exemplo,dc=local", Attributes: map[string][]string{"objectClass": {"top", "group"}}},
+}
+
+func main() {
+	entries := sampleDirectory
+	if path := os.Getenv("MOCK_LDAP_DIRECTORY"); path != "" {
+		var err error
+		if entries, err = ldaptest.LoadEntries(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	addr := os.Getenv("MOCK_LDAP_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:10389"
+	}
+	server, err := ldaptest.Start(addr, entries)
+	if err != nil {
+		log.Fatalf("Erro ao iniciar o servidor LDAP de teste: %v", err)
+	}
+	log.Printf("Servidor LDAP de teste em %s (%d entradas)", server.URL(), len(entries))
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	server.Close()
+}
@@ -0,0 +1,44 @@
+// cmd/mockoidc/main.go
+
+// Provedor OIDC mínimo para desenvolvimento do login externo, usando o provedor em processo
+// de internal/oidctest. Aprova qualquer autorização sem tela de login: a identidade é o
+// e-mail em login_hint (ou MOCK_OIDC_EMAIL). login_hint=deny simula a recusa do usuário.
+// Nunca deve ser exposto em produção.
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"auth-service/internal/oidctest"
+)
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func main() {
+	config := oidctest.Config{
+		Issuer:       envOr("MOCK_OIDC_ISSUER", "http://localhost:9000"),
+		ClientID:     envOr("MOCK_OIDC_CLIENT_ID", "dev-client"),
+		ClientSecret: os.Getenv("MOCK_OIDC_CLIENT_SECRET"),
+		DefaultEmail: envOr("MOCK_OIDC_EMAIL", "dev@example.com"),
+	}
+
+	addr := envOr("MOCK_OIDC_ADDR", ":9000")
+	server, err := oidctest.Start(addr, config)
+	if err != nil {
+		log.Fatalf("Erro ao iniciar o provedor OIDC de teste: %v", err)
+	}
+	log.Printf("Provedor OIDC de teste em %s (issuer %s, client_id %s)", addr, server.URL(), config.ClientID)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	server.Close()
+}
@@ -0,0 +1,276 @@
+// internal/api/handlers/admin_handler.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"auth-service/internal/api/middleware"
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler expõe a API de administração de usuários e roles.
+// Todas as rotas devem ser registradas atrás de middleware.RequireAuth e
+// middleware.RequireRole(auth.RoleAdmin).
+type AdminHandler struct {
+	service auth.AdminService
+}
+
+func NewAdminHandler(service auth.AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+type CreateUserRequest struct {
+	Username string   `json:"username" binding:"required"`
+	Password string   `json:"password" binding:"required"`
+	Roles    []string `json:"roles"`
+	Tenants  []string `json:"tenants"`
+	Email    string   `json:"email"`
+}
+
+type UpdateUserRequest struct {
+	Roles    *[]string `json:"roles"`
+	Tenants  *[]string `json:"tenants"`
+	Email    *string   `json:"email"`
+	Disabled *bool     `json:"disabled"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type TenantRequest struct {
+	Tenant string `json:"tenant" binding:"required"`
+}
+
+type PasswordResetRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	users, err := h.service.ListUsers(c.Request.Context())
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, err := h.service.GetUser(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	user, err := h.service.CreateUser(c.Request.Context(), auth.CreateUserInput{
+		Username: req.Username,
+		Password: req.Password,
+		Roles:    req.Roles,
+		Tenants:  req.Tenants,
+		Email:    req.Email,
+	})
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+	if req.Disabled != nil && *req.Disabled && !h.allowSelfChange(c) {
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), c.Param("username"), auth.UpdateUserInput{
+		Roles:    req.Roles,
+		Tenants:  req.Tenants,
+		Email:    req.Email,
+		Disabled: req.Disabled,
+	})
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	if !h.allowSelfChange(c) {
+		return
+	}
+	h.setDisabled(c, true)
+}
+
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	if !h.allowSelfChange(c) {
+		return
+	}
+	if err := h.service.DeleteUser(c.Request.Context(), c.Param("username")); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	user, err := h.service.AssignRole(c.Request.Context(), c.Param("username"), req.Role)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) RevokeRole(c *gin.Context) {
+	user, err := h.service.RevokeRole(c.Request.Context(), c.Param("username"), c.Param("role"))
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) AssignTenant(c *gin.Context) {
+	var req TenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	user, err := h.service.AssignTenant(c.Request.Context(), c.Param("username"), req.Tenant)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) RevokeTenant(c *gin.Context) {
+	user, err := h.service.RevokeTenant(c.Request.Context(), c.Param("username"), c.Param("tenant"))
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) ResetPassword(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), c.Param("username"), req.Password); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	if err := h.service.UnlockUser(c.Request.Context(), c.Param("username")); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) UnlockIP(c *gin.Context) {
+	if err := h.service.UnlockIP(c.Request.Context(), c.Param("ip")); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) ResetMFA(c *gin.Context) {
+	if err := h.service.ResetMFA(c.Request.Context(), c.Param("username")); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UnlinkIdentity remove o vínculo do usuário com uma identidade OIDC.
+func (h *AdminHandler) UnlinkIdentity(c *gin.Context) {
+	if err := h.service.UnlinkIdentity(c.Request.Context(), c.Param("username"), c.Param("id")); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListRoles retorna as permissões e os grupos de roles aceitos pela API.
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"roles": auth.KnownRoles, "groups": h.service.RoleGroups()})
+}
+
+func (h *AdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	user, err := h.service.UpdateUser(c.Request.Context(), c.Param("username"), auth.UpdateUserInput{Disabled: &disabled})
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// allowSelfChange impede que um administrador desative ou remova a própria conta.
+func (h *AdminHandler) allowSelfChange(c *gin.Context) bool {
+	if c.Param("username") == middleware.Username(c) {
+		c.JSON(http.StatusConflict, gin.H{"error": "não é possível desativar ou remover o próprio usuário"})
+		return false
+	}
+	return true
+}
+
+// writeAdminError traduz os erros do AdminService em status HTTP.
+func writeAdminError(c *gin.Context, err error) {
+	var invalidRole *auth.InvalidRoleError
+	var weakPassword *auth.PasswordPolicyError
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrUserExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &invalidRole),
+		errors.As(err, &weakPassword),
+		errors.Is(err, auth.ErrInvalidUsername),
+		errors.Is(err, auth.ErrInvalidEmail),
+		errors.Is(err, auth.ErrTenantNotFound),
+		errors.Is(err, auth.ErrEmptyPassword),
+		errors.Is(err, auth.ErrDirectoryPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Erro na API de administração: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao processar a requisição"})
+	}
+}
@@ -0,0 +1,75 @@
+// internal/api/handlers/audit_handler.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler expõe a consulta ao histórico de auditoria. Deve ser registrado atrás de
+// middleware.RequireAuth e middleware.RequireRole(auth.RoleAdmin).
+type AuditHandler struct {
+	service auth.AuditService
+}
+
+func NewAuditHandler(service auth.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// Query aceita os filtros username, type, from, to (RFC 3339 ou AAAA-MM-DD; "to" com
+// apenas a data inclui o dia inteiro) e limit.
+func (h *AuditHandler) Query(c *gin.Context) {
+	filter := auth.AuditFilter{
+		Username: c.Query("username"),
+		Type:     c.Query("type"),
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetro from inválido"})
+		return
+	}
+	if filter.To, err = parseAuditTime(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetro to inválido"})
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetro limit inválido"})
+			return
+		}
+	}
+
+	events, err := h.service.Query(c.Request.Context(), filter)
+	if err != nil {
+		log.Printf("Erro ao consultar auditoria: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao consultar o histórico de auditoria"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// parseAuditTime interpreta um instante RFC 3339 ou uma data. Para o fim do intervalo,
+// uma data sem hora avança para o início do dia seguinte.
+func parseAuditTime(value string, endOfRange bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -2,8 +2,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"auth-service/internal/api/middleware"
 	"auth-service/internal/core/auth"
 
 	"github.com/gin-gonic/gin"
@@ -29,11 +33,226 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
+	result, err := h.service.Login(c.Request.Context(), req.Username, req.Password, clientInfo(c))
 	if err != nil {
+		writeLoginError(c, err)
+		return
+	}
+
+	// Usuários com 2FA recebem um desafio a ser concluído em /api/v1/mfa/verify.
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, result.Challenge)
+		return
+	}
+	c.JSON(http.StatusOK, result.Tokens)
+}
+
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// VerifyMFA conclui o login de um usuário com 2FA, trocando o desafio e o código
+// TOTP (ou um código de recuperação) pelo par de tokens.
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	tokens, err := h.service.CompleteMFA(c.Request.Context(), req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		writeLoginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// writeLoginError traduz os erros do login (primeiro e segundo passos) em status HTTP.
+func writeLoginError(c *gin.Context, err error) {
+	var tooMany *auth.TooManyAttemptsError
+	switch {
+	case errors.As(err, &tooMany):
+		c.Header("Retry-After", strconv.Itoa(tooMany.RetryAfterSeconds()))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrUserDisabled), errors.Is(err, auth.ErrDirectoryNoAccess):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrDirectoryUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	}
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+type APIKeyRequest struct {
+	APIKey string `json:"api_key" binding:"required"`
+}
+
+// ExchangeAPIKey troca a chave de API de uma conta de serviço por um access token.
+func (h *AuthHandler) ExchangeAPIKey(c *gin.Context) {
+	var req APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	tokens, err := h.service.ExchangeAPIKey(c.Request.Context(), req.APIKey)
+	if errors.Is(err, auth.ErrInvalidAPIKey) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout revoga o access token do header Authorization e, opcionalmente, o refresh token informado.
+// Deve ser registrado atrás de middleware.RequireAuth.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+			return
+		}
+	}
+
+	err := h.service.Logout(c.Request.Context(), c.GetString(middleware.ContextAccessToken), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type SwitchTenantRequest struct {
+	Tenant       string `json:"tenant" binding:"required"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SwitchTenant troca a empresa ativa, devolvendo um novo par de tokens. O access token
+// apresentado e, se informado, o refresh token anterior são revogados.
+// Deve ser registrado atrás de middleware.RequireAuth.
+func (h *AuthHandler) SwitchTenant(c *gin.Context) {
+	var req SwitchTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	tokens, err := h.service.SwitchTenant(c.Request.Context(), c.GetString(middleware.ContextAccessToken), req.RefreshToken, req.Tenant)
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken):
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
+	case errors.Is(err, auth.ErrTenantNotMember), errors.Is(err, auth.ErrTenantDisabled), errors.Is(err, auth.ErrUserDisabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CheckRevoked informa se um jti foi revogado ou, com ?sid=, se a sessão do token foi
+// encerrada. Endpoint interno, consultado pelo gateway e pelos demais serviços para
+// rejeitar tokens invalidados antes do exp. Deve ser registrado atrás de
+// middleware.RequireServiceAccount e middleware.RequireRole(auth.RoleIntrospect).
+func (h *AuthHandler) CheckRevoked(c *gin.Context) {
+	jti := c.Param("jti")
+
+	revoked, err := h.service.IsRevoked(c.Request.Context(), jti, c.Query("sid"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao consultar lista de revogação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jti": jti, "revoked": revoked})
+}
+
+type IntrospectRequest struct {
+	Token string `form:"token" json:"token" binding:"required"`
+	// TokenTypeHint é aceito por compatibilidade com a RFC 7662; apenas access tokens são descritos.
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+}
+
+// Introspect descreve um access token (RFC 7662), recebido como formulário
+// (application/x-www-form-urlencoded) ou JSON. Endpoint interno, consultado pelos serviços Go;
+// registrado atrás das mesmas restrições de CheckRevoked.
+func (h *AuthHandler) Introspect(c *gin.Context) {
+	var req IntrospectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	info, err := h.service.Introspect(c.Request.Context(), req.Token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, info)
+}
+
+// Me retorna a identidade, as permissões e a empresa ativa do token autenticado, usadas
+// pelo frontend para montar os menus. Deve ser registrado atrás de middleware.RequireAuth.
+func (h *AuthHandler) Me(c *gin.Context) {
+	info := auth.TokenInfoFromClaims(middleware.Claims(c))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"username":   info.Username,
+		"roles":      info.Roles,
+		"tenant":     info.Tenant,
+		"expires_at": time.Unix(info.ExpiresAt, 0).UTC(),
+	})
+}
+
+// JWKS publica as chaves públicas de verificação dos tokens (/.well-known/jwks.json).
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.service.JWKS())
+}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+// clientInfo extrai o IP (considerando X-Forwarded-For de proxies confiáveis) e o User-Agent.
+func clientInfo(c *gin.Context) auth.ClientInfo {
+	return auth.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
 }
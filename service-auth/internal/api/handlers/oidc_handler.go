@@ -0,0 +1,131 @@
+// internal/api/handlers/oidc_handler.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// oidcBindingCookie guarda, entre o Authorize e o Callback, o valor que vincula o state
+	// ao navegador que iniciou o login.
+	oidcBindingCookie = "oidc_binding"
+	oidcCookiePath    = "/api/v1/oidc"
+)
+
+// OIDCHandler expõe o login com provedores de identidade externos (authorization code + PKCE).
+type OIDCHandler struct {
+	service auth.Service
+	// frontendURL recebe o resultado do callback no fragmento (#token=...); vazio responde em JSON.
+	frontendURL string
+}
+
+func NewOIDCHandler(service auth.Service, frontendURL string) *OIDCHandler {
+	return &OIDCHandler{service: service, frontendURL: frontendURL}
+}
+
+// Providers lista os provedores disponíveis, para o frontend montar os botões de login.
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.service.OIDCProviders()})
+}
+
+// Authorize redireciona o navegador ao endpoint de autorização do provedor. O parâmetro
+// login_hint, se informado, é repassado ao provedor. O vínculo do state vai em um cookie
+// HttpOnly e SameSite=Lax, que o navegador reenvia no redirecionamento de volta ao Callback.
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authorizationURL, binding, err := h.service.StartOIDC(c.Request.Context(), c.Param("provider"), c.Query("login_hint"))
+	if err != nil {
+		c.JSON(oidcErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, binding, 0, oidcCookiePath, "", isHTTPS(c), true)
+	c.Redirect(http.StatusFound, authorizationURL)
+}
+
+// Callback recebe o retorno do provedor e conclui o login, desde que o navegador apresente
+// o cookie definido em Authorize. Com frontendURL configurada, o navegador é redirecionado
+// com os tokens (ou o desafio de 2FA, ou o erro) no fragmento, que não é enviado a
+// servidores nem registrado em logs de acesso.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	// O cookie só serve a um login: é removido já na primeira chegada ao callback.
+	binding, _ := c.Cookie(oidcBindingCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, "", -1, oidcCookiePath, "", isHTTPS(c), true)
+
+	if idpError := c.Query("error"); idpError != "" {
+		h.fail(c, http.StatusUnauthorized, "O provedor de identidade recusou o login: "+idpError)
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		h.fail(c, http.StatusBadRequest, "Requisição inválida")
+		return
+	}
+
+	result, err := h.service.CompleteOIDC(c.Request.Context(), c.Param("provider"), code, state, binding, clientInfo(c))
+	if err != nil {
+		h.fail(c, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	if h.frontendURL == "" {
+		if result.Challenge != nil {
+			c.JSON(http.StatusOK, result.Challenge)
+			return
+		}
+		c.JSON(http.StatusOK, result.Tokens)
+		return
+	}
+
+	fragment := url.Values{}
+	if result.Challenge != nil {
+		fragment.Set("mfa_required", "true")
+		fragment.Set("challenge_token", result.Challenge.ChallengeToken)
+		fragment.Set("expires_in", strconv.FormatInt(result.Challenge.ExpiresIn, 10))
+	} else {
+		fragment.Set("token", result.Tokens.AccessToken)
+		fragment.Set("refresh_token", result.Tokens.RefreshToken)
+		fragment.Set("expires_in", strconv.FormatInt(result.Tokens.ExpiresIn, 10))
+	}
+	c.Redirect(http.StatusFound, h.frontendURL+"#"+fragment.Encode())
+}
+
+// isHTTPS informa se o navegador acessou o serviço por HTTPS, direto ou pelo gateway, para
+// marcar o cookie como Secure.
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+// fail responde o erro em JSON ou o repassa ao frontend no fragmento.
+func (h *OIDCHandler) fail(c *gin.Context, status int, message string) {
+	if h.frontendURL == "" {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+	c.Redirect(http.StatusFound, h.frontendURL+"#"+url.Values{"error": {message}}.Encode())
+}
+
+// oidcErrorStatus traduz os erros do login externo em status HTTP.
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrOIDCProviderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, auth.ErrInvalidOIDCState):
+		return http.StatusBadRequest
+	case errors.Is(err, auth.ErrOIDCLoginFailed):
+		return http.StatusBadGateway
+	case errors.Is(err, auth.ErrOIDCUserNotLinked), errors.Is(err, auth.ErrOIDCDomainNotAllowed), errors.Is(err, auth.ErrUserDisabled):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,98 @@
+// internal/api/handlers/password_handler.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"auth-service/internal/api/middleware"
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordHandler expõe a troca de senha e o fluxo de redefinição.
+type PasswordHandler struct {
+	service auth.PasswordService
+}
+
+func NewPasswordHandler(service auth.PasswordService) *PasswordHandler {
+	return &PasswordHandler{service: service}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ResetRequestRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ResetConfirmRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// Change troca a senha do usuário autenticado. Deve ser registrado atrás de middleware.RequireAuth.
+func (h *PasswordHandler) Change(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	err := h.service.ChangePassword(c.Request.Context(), middleware.Username(c), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		writePasswordError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RequestReset sempre responde 202, exista ou não o usuário.
+func (h *PasswordHandler) RequestReset(c *gin.Context) {
+	var req ResetRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	if err := h.service.RequestReset(c.Request.Context(), req.Username); err != nil {
+		log.Printf("Erro ao solicitar redefinição de senha para %s: %v", req.Username, err)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Se o usuário existir, as instruções de redefinição serão enviadas"})
+}
+
+func (h *PasswordHandler) ConfirmReset(c *gin.Context) {
+	var req ResetConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	if err := h.service.ConfirmReset(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		writePasswordError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writePasswordError traduz os erros do PasswordService em status HTTP.
+func writePasswordError(c *gin.Context, err error) {
+	var weakPassword *auth.PasswordPolicyError
+	switch {
+	case errors.Is(err, auth.ErrWrongPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrEmptyPassword), errors.As(err, &weakPassword), errors.Is(err, auth.ErrDirectoryPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Erro ao alterar senha: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao processar a requisição"})
+	}
+}
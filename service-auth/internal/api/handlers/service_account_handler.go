@@ -0,0 +1,163 @@
+// internal/api/handlers/service_account_handler.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccountHandler expõe a administração de contas de serviço e chaves de API.
+// Todas as rotas devem ser registradas atrás de middleware.RequireAuth e
+// middleware.RequireRole(auth.RoleAdmin).
+type ServiceAccountHandler struct {
+	service auth.ServiceAccountService
+}
+
+func NewServiceAccountHandler(service auth.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{service: service}
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+}
+
+type UpdateServiceAccountRequest struct {
+	Description *string   `json:"description"`
+	Roles       *[]string `json:"roles"`
+	Disabled    *bool     `json:"disabled"`
+}
+
+type CreateAPIKeyRequest struct {
+	Roles     []string   `json:"roles"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h *ServiceAccountHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.service.ListAccounts(c.Request.Context())
+	if err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"service_accounts": accounts})
+}
+
+func (h *ServiceAccountHandler) GetAccount(c *gin.Context) {
+	account, err := h.service.GetAccount(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *ServiceAccountHandler) CreateAccount(c *gin.Context) {
+	var req CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	account, err := h.service.CreateAccount(c.Request.Context(), auth.CreateServiceAccountInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Roles:       req.Roles,
+	})
+	if err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *ServiceAccountHandler) UpdateAccount(c *gin.Context) {
+	var req UpdateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	account, err := h.service.UpdateAccount(c.Request.Context(), c.Param("name"), auth.UpdateServiceAccountInput{
+		Description: req.Description,
+		Roles:       req.Roles,
+		Disabled:    req.Disabled,
+	})
+	if err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *ServiceAccountHandler) DeleteAccount(c *gin.Context) {
+	if err := h.service.DeleteAccount(c.Request.Context(), c.Param("name")); err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ServiceAccountHandler) ListKeys(c *gin.Context) {
+	keys, err := h.service.ListKeys(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// CreateKey gera uma chave de API. O valor da chave só é retornado nesta resposta.
+func (h *ServiceAccountHandler) CreateKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+			return
+		}
+	}
+
+	key, err := h.service.CreateKey(c.Request.Context(), c.Param("name"), auth.CreateAPIKeyInput{
+		Roles:     req.Roles,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *ServiceAccountHandler) RevokeKey(c *gin.Context) {
+	if err := h.service.RevokeKey(c.Request.Context(), c.Param("name"), c.Param("id")); err != nil {
+		writeServiceAccountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writeServiceAccountError traduz os erros do ServiceAccountService em status HTTP.
+func writeServiceAccountError(c *gin.Context, err error) {
+	var invalidRole *auth.InvalidRoleError
+	switch {
+	case errors.Is(err, auth.ErrServiceAccountNotFound), errors.Is(err, auth.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrServiceAccountExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &invalidRole),
+		errors.Is(err, auth.ErrInvalidServiceAccountName),
+		errors.Is(err, auth.ErrServiceAccountAdminRole),
+		errors.Is(err, auth.ErrAPIKeyRoleNotGranted),
+		errors.Is(err, auth.ErrInvalidAPIKeyExpiration):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Erro na API de contas de serviço: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao processar a requisição"})
+	}
+}
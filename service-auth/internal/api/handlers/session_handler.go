@@ -0,0 +1,91 @@
+// internal/api/handlers/session_handler.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"auth-service/internal/api/middleware"
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler expõe as sessões do usuário autenticado (rotas atrás de middleware.RequireAuth)
+// e as de qualquer usuário para a administração (atrás de middleware.RequireRole(auth.RoleAdmin)).
+type SessionHandler struct {
+	service auth.SessionService
+}
+
+func NewSessionHandler(service auth.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+// Mine lista as sessões ativas do usuário, marcando a do token usado na requisição.
+func (h *SessionHandler) Mine(c *gin.Context) {
+	h.list(c, middleware.Username(c), middleware.Claims(c).SessionID)
+}
+
+// RevokeMine encerra uma sessão do usuário, inclusive a atual.
+func (h *SessionHandler) RevokeMine(c *gin.Context) {
+	h.revoke(c, middleware.Username(c))
+}
+
+// RevokeAllMine encerra todas as sessões do usuário; com ?keep_current=true, mantém a atual
+// ("sair dos outros dispositivos").
+func (h *SessionHandler) RevokeAllMine(c *gin.Context) {
+	keep := ""
+	if c.Query("keep_current") == "true" {
+		keep = middleware.Claims(c).SessionID
+	}
+	h.revokeAll(c, middleware.Username(c), keep)
+}
+
+func (h *SessionHandler) ListForUser(c *gin.Context) {
+	h.list(c, c.Param("username"), "")
+}
+
+func (h *SessionHandler) RevokeForUser(c *gin.Context) {
+	h.revoke(c, c.Param("username"))
+}
+
+func (h *SessionHandler) RevokeAllForUser(c *gin.Context) {
+	h.revokeAll(c, c.Param("username"), "")
+}
+
+func (h *SessionHandler) list(c *gin.Context, username, currentID string) {
+	sessions, err := h.service.List(c.Request.Context(), username, currentID)
+	if err != nil {
+		writeSessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func (h *SessionHandler) revoke(c *gin.Context, username string) {
+	if err := h.service.Revoke(c.Request.Context(), username, c.Param("id")); err != nil {
+		writeSessionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SessionHandler) revokeAll(c *gin.Context, username, keepID string) {
+	revoked, err := h.service.RevokeAll(c.Request.Context(), username, keepID)
+	if err != nil {
+		writeSessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// writeSessionError traduz os erros do SessionService em status HTTP.
+func writeSessionError(c *gin.Context, err error) {
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Erro na API de sessões: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao processar a requisição"})
+}
@@ -0,0 +1,133 @@
+// internal/api/handlers/tenant_handler.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"auth-service/internal/api/middleware"
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHandler expõe o cadastro de empresas clientes e a lista de empresas do usuário.
+// As rotas de cadastro devem ser registradas atrás de middleware.RequireAuth e
+// middleware.RequireRole(auth.RoleAdmin); Mine apenas atrás de middleware.RequireAuth.
+type TenantHandler struct {
+	service auth.TenantService
+}
+
+func NewTenantHandler(service auth.TenantService) *TenantHandler {
+	return &TenantHandler{service: service}
+}
+
+type CreateTenantRequest struct {
+	ID   string `json:"id" binding:"required"`
+	Name string `json:"name" binding:"required"`
+	CNPJ string `json:"cnpj"`
+}
+
+type UpdateTenantRequest struct {
+	Name     *string `json:"name"`
+	CNPJ     *string `json:"cnpj"`
+	Disabled *bool   `json:"disabled"`
+}
+
+func (h *TenantHandler) List(c *gin.Context) {
+	tenants, err := h.service.List(c.Request.Context())
+	if err != nil {
+		writeTenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tenants": tenants})
+}
+
+func (h *TenantHandler) Get(c *gin.Context) {
+	tenant, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeTenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tenant)
+}
+
+func (h *TenantHandler) Create(c *gin.Context) {
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	tenant, err := h.service.Create(c.Request.Context(), auth.CreateTenantInput{
+		ID:   req.ID,
+		Name: req.Name,
+		CNPJ: req.CNPJ,
+	})
+	if err != nil {
+		writeTenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tenant)
+}
+
+func (h *TenantHandler) Update(c *gin.Context) {
+	var req UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	tenant, err := h.service.Update(c.Request.Context(), c.Param("id"), auth.UpdateTenantInput{
+		Name:     req.Name,
+		CNPJ:     req.CNPJ,
+		Disabled: req.Disabled,
+	})
+	if err != nil {
+		writeTenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tenant)
+}
+
+func (h *TenantHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		writeTenantError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Mine lista as empresas em que o usuário autenticado pode trabalhar, para o seletor
+// de empresa do frontend.
+func (h *TenantHandler) Mine(c *gin.Context) {
+	tenants, err := h.service.ListForUser(c.Request.Context(), middleware.Username(c))
+	if errors.Is(err, auth.ErrUserNotFound) {
+		// Contas de serviço não têm empresas.
+		c.JSON(http.StatusOK, gin.H{"tenants": []auth.TenantInfo{}})
+		return
+	}
+	if err != nil {
+		writeTenantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tenants": tenants})
+}
+
+// writeTenantError traduz os erros do TenantService em status HTTP.
+func writeTenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrTenantExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrInvalidTenantID),
+		errors.Is(err, auth.ErrInvalidTenantName),
+		errors.Is(err, auth.ErrInvalidCNPJ):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Erro na API de empresas clientes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao processar a requisição"})
+	}
+}
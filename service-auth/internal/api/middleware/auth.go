@@ -0,0 +1,106 @@
+// internal/api/middleware/auth.go
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Chaves usadas para guardar o token e suas claims no contexto do gin.
+const (
+	ContextAccessToken = "accessToken"
+	ContextClaims      = "claims"
+)
+
+// RequestInfo anexa ao contexto da requisição o IP e o User-Agent do cliente, registrados
+// nos eventos de auditoria.
+func RequestInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := auth.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+		c.Request = c.Request.WithContext(auth.WithClientInfo(c.Request.Context(), client))
+		c.Next()
+	}
+}
+
+// RequireAuth exige um access token válido e não revogado no header Authorization
+// e disponibiliza o token e suas claims no contexto da requisição.
+func RequireAuth(service auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token de autorização não fornecido"})
+			return
+		}
+
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Formato do token inválido"})
+			return
+		}
+
+		claims, err := service.ValidateAccessToken(c.Request.Context(), parts[1])
+		if errors.Is(err, auth.ErrInvalidToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(ContextAccessToken, parts[1])
+		c.Set(ContextClaims, claims)
+		c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), claims.Subject))
+		c.Next()
+	}
+}
+
+// RequireRole exige que o token validado por RequireAuth contenha a role informada.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, r := range Roles(c) {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado: permissão necessária ausente"})
+	}
+}
+
+// RequireServiceAccount exige que o token validado por RequireAuth seja de uma conta de
+// serviço, para as rotas consultadas apenas pelos serviços internos.
+func RequireServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.IsServiceAccount(Username(c)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado: rota restrita a contas de serviço"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Claims retorna as claims do token autenticado, ou claims vazias fora de RequireAuth.
+func Claims(c *gin.Context) *auth.Claims {
+	value, _ := c.Get(ContextClaims)
+	claims, ok := value.(*auth.Claims)
+	if !ok {
+		return &auth.Claims{}
+	}
+	return claims
+}
+
+// Username retorna o username (sub) do token autenticado.
+func Username(c *gin.Context) string {
+	return Claims(c).Subject
+}
+
+// Roles retorna as roles do token autenticado.
+func Roles(c *gin.Context) []string {
+	return Claims(c).Roles
+}
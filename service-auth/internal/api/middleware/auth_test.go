@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"auth-service/internal/core/auth"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newInternalRouter monta as rotas internas com a mesma cadeia de middlewares de cmd/auth e
+// retorna o token de uma conta de serviço e o de um usuário, ambos com auth.RoleIntrospect.
+func newInternalRouter(t *testing.T) (router *gin.Engine, serviceToken, userToken string) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	users, err := auth.NewFileUserRepository(filepath.Join(dir, "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("senha-forte"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Create(ctx, &auth.User{Username: "maria", PasswordHash: string(hash), Roles: []string{auth.RoleIntrospect}}); err != nil {
+		t.Fatal(err)
+	}
+	stores := auth.Stores{
+		Users:           users,
+		RefreshTokens:   auth.NewMemoryRefreshTokenStore(),
+		Revocations:     auth.NewMemoryRevocationStore(),
+		SigningKeys:     auth.NewFileKeyStore(filepath.Join(dir, "signing_keys.json")),
+		PasswordResets:  auth.NewMemoryPasswordResetStore(),
+		LoginAttempts:   auth.NewMemoryLoginAttemptStore(),
+		MFAChallenges:   auth.NewMemoryMFAChallengeStore(),
+		ServiceAccounts: auth.NewFileServiceAccountStore(filepath.Join(dir, "service_accounts.json")),
+		AuditEvents:     auth.NewMemoryAuditStore(),
+		Tenants:         auth.NewFileTenantStore(filepath.Join(dir, "tenants.json")),
+		OIDCStates:      auth.NewMemoryOIDCStateStore(),
+		Sessions:        auth.NewMemorySessionStore(),
+	}
+	keys, err := auth.NewKeyManager(ctx, stores.SigningKeys, auth.KeyConfig{Algorithm: auth.AlgorithmEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := auth.NewService(stores, keys, auth.TokenConfig{}, auth.ThrottleConfig{}, auth.OIDCConfig{}, nil, nil, auth.PasswordPolicy{BcryptCost: bcrypt.MinCost})
+
+	accounts := auth.NewServiceAccountService(stores.ServiceAccounts, stores.AuditEvents, nil)
+	if _, err := accounts.CreateAccount(ctx, auth.CreateServiceAccountInput{Name: "api-gateway", Roles: []string{auth.RoleIntrospect}}); err != nil {
+		t.Fatal(err)
+	}
+	key, err := accounts.CreateKey(ctx, "api-gateway", auth.CreateAPIKeyInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceTokens, err := service.ExchangeAPIKey(ctx, key.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	login, err := service.Login(ctx, "maria", "senha-forte", auth.ClientInfo{IP: "203.0.113.10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router = gin.New()
+	router.GET("/revoked/:jti", RequireAuth(service), RequireServiceAccount(), RequireRole(auth.RoleIntrospect), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router, serviceTokens.AccessToken, login.Tokens.AccessToken
+}
+
+func TestInternalRoutesRequireServiceAccount(t *testing.T) {
+	router, serviceToken, userToken := newInternalRouter(t)
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"sem token", "", http.StatusUnauthorized},
+		{"token inválido", "Bearer abc.def.ghi", http.StatusUnauthorized},
+		{"token de usuário com a role", "Bearer " + userToken, http.StatusForbidden},
+		{"token de conta de serviço", "Bearer " + serviceToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/revoked/qualquer", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, esperado %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// internal/bootstrap/bootstrap.go
+
+// Package bootstrap reúne a inicialização compartilhada pelos binários do serviço de
+// autenticação (cmd/auth e cmd/authctl) a partir da configuração carregada pelo pacote
+// config: armazenamentos conforme USER_STORE, grupos de roles e política de senhas.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"auth-service/internal/config"
+	"auth-service/internal/core/auth"
+
+	"cloud.google.com/go/firestore"
+)
+
+func initFirestoreClient(ctx context.Context, cfg config.Firestore) (*firestore.Client, error) {
+	client, err := firestore.NewClientWithDatabase(ctx, cfg.ProjectID, cfg.DatabaseID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inicializar cliente Firestore: %w", err)
+	}
+	log.Printf("Conectado com sucesso ao Firestore (projeto %s, banco %s)", cfg.ProjectID, cfg.DatabaseID)
+	return client, nil
+}
+
+// OpenStores escolhe os armazenamentos conforme USER_STORE:
+// "firestore" (padrão, no projeto e banco de FIRESTORE_PROJECT_ID e FIRESTORE_DATABASE_ID) ou
+// "file", que lê USERS_FILE, SIGNING_KEYS_FILE, SERVICE_ACCOUNTS_FILE e TENANTS_FILE e
+// dispensa credenciais do Google.
+// No modo "file" os refresh tokens, a lista de revogação, os tokens de redefinição de senha,
+// as tentativas de login, os desafios de 2FA, os states de login OIDC e o histórico de auditoria
+// ficam apenas em memória.
+// Retorna também uma função de encerramento para liberar os recursos do backend.
+func OpenStores(ctx context.Context, cfg *config.Config) (auth.Stores, func(), error) {
+	switch store := cfg.Store.Kind; store {
+	case "firestore":
+		client, err := initFirestoreClient(ctx, cfg.Firestore)
+		if err != nil {
+			return auth.Stores{}, nil, err
+		}
+		return auth.Stores{
+			Users:           auth.NewFirestoreUserRepository(client),
+			RefreshTokens:   auth.NewFirestoreRefreshTokenStore(client),
+			Revocations:     auth.NewFirestoreRevocationStore(client),
+			SigningKeys:     auth.NewFirestoreKeyStore(client),
+			PasswordResets:  auth.NewFirestorePasswordResetStore(client),
+			LoginAttempts:   auth.NewFirestoreLoginAttemptStore(client),
+			MFAChallenges:   auth.NewFirestoreMFAChallengeStore(client),
+			ServiceAccounts: auth.NewFirestoreServiceAccountStore(client),
+			AuditEvents:     auth.NewFirestoreAuditStore(client),
+			Tenants:         auth.NewFirestoreTenantStore(client),
+			OIDCStates:      auth.NewFirestoreOIDCStateStore(client),
+			Sessions:        auth.NewFirestoreSessionStore(client),
+		}, func() { client.Close() }, nil
+	case "file":
+		path := cfg.Store.UsersFile
+		repo, err := auth.NewFileUserRepository(path)
+		if err != nil {
+			return auth.Stores{}, nil, fmt.Errorf("erro ao inicializar repositório de usuários em arquivo: %w", err)
+		}
+		log.Printf("Usando repositório de usuários em arquivo: %s", path)
+		return auth.Stores{
+			Users:           repo,
+			RefreshTokens:   auth.NewMemoryRefreshTokenStore(),
+			Revocations:     auth.NewMemoryRevocationStore(),
+			SigningKeys:     auth.NewFileKeyStore(cfg.Store.SigningKeysFile),
+			PasswordResets:  auth.NewMemoryPasswordResetStore(),
+			LoginAttempts:   auth.NewMemoryLoginAttemptStore(),
+			MFAChallenges:   auth.NewMemoryMFAChallengeStore(),
+			ServiceAccounts: auth.NewFileServiceAccountStore(cfg.Store.ServiceAccountsFile),
+			AuditEvents:     auth.NewMemoryAuditStore(),
+			Tenants:         auth.NewFileTenantStore(cfg.Store.TenantsFile),
+			OIDCStates:      auth.NewMemoryOIDCStateStore(),
+			Sessions:        auth.NewMemorySessionStore(),
+		}, func() {}, nil
+	default:
+		return auth.Stores{}, nil, fmt.Errorf("USER_STORE inválido: %q (use \"firestore\" ou \"file\")", store)
+	}
+}
+
+// RoleGroups carrega e valida os grupos de roles de ROLE_GROUPS_FILE; sem o arquivo, não há
+// grupos.
+func RoleGroups(cfg *config.Config) (auth.RoleGroups, error) {
+	path := cfg.Roles.GroupsFile
+	if path == "" {
+		return nil, nil
+	}
+	groups, err := auth.LoadRoleGroups(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := groups.Validate(); err != nil {
+		return nil, fmt.Errorf("ROLE_GROUPS_FILE inválido: %w", err)
+	}
+	log.Printf("%d grupos de roles carregados de %s", len(groups), path)
+	return groups, nil
+}
+
+// PasswordPolicy monta e valida a política de senhas (PASSWORD_*, BCRYPT_COST e
+// BREACHED_PASSWORDS_FILE).
+func PasswordPolicy(cfg *config.Config) (auth.PasswordPolicy, error) {
+	policy := auth.PasswordPolicy{
+		MinLength:      cfg.Passwords.MinLength,
+		MinCharClasses: cfg.Passwords.MinCharClasses,
+		BcryptCost:     cfg.Passwords.BcryptCost,
+	}
+	if path := cfg.Passwords.BreachedFile; path != "" {
+		breached, err := auth.LoadBreachedPasswords(path)
+		if err != nil {
+			return auth.PasswordPolicy{}, err
+		}
+		policy.Breached = breached
+		log.Printf("%d senhas vazadas carregadas de %s", breached.Len(), path)
+	}
+	if err := policy.Validate(); err != nil {
+		return auth.PasswordPolicy{}, fmt.Errorf("política de senhas inválida: %w", err)
+	}
+	return policy, nil
+}
+
+// KeyConfig monta a configuração das chaves de assinatura (JWT_ALGORITHM e
+// KEY_ROTATION_INTERVAL). As chaves aposentadas continuam publicadas por ACCESS_TOKEN_TTL,
+// mais o intervalo de recarga das chaves.
+func KeyConfig(cfg *config.Config) auth.KeyConfig {
+	return auth.KeyConfig{
+		Algorithm:        cfg.Keys.Algorithm,
+		RotationInterval: cfg.Keys.RotationInterval,
+		RetirementGrace:  cfg.Tokens.AccessTTL,
+	}
+}
@@ -0,0 +1,187 @@
+// internal/config/config.go
+
+// Package config define a configuração tipada dos binários do serviço de autenticação
+// (cmd/auth e cmd/authctl), lida de padrões, de um arquivo YAML opcional, de variáveis de
+// ambiente (inclusive .env) e de flags. Os nomes das variáveis são os documentados no README.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"shared/configloader"
+)
+
+// Config reúne toda a configuração do serviço de autenticação.
+type Config struct {
+	Port int `yaml:"port" env:"PORT" flag:"port" default:"8081" usage:"HTTP port of the service"`
+	// TrustedProxies são os proxies cujo X-Forwarded-For identifica o IP do cliente.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" default:"127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"`
+
+	Store     Store     `yaml:"store"`
+	Firestore Firestore `yaml:"firestore"`
+	Tokens    Tokens    `yaml:"tokens"`
+	Keys      Keys      `yaml:"keys"`
+	Login     Login     `yaml:"login"`
+	Passwords Passwords `yaml:"passwords"`
+	Roles     Roles     `yaml:"roles"`
+	Notifier  Notifier  `yaml:"notifier"`
+	MFA       MFA       `yaml:"mfa"`
+	OIDC      OIDC      `yaml:"oidc"`
+	LDAP      LDAP      `yaml:"ldap"`
+}
+
+// Store escolhe o backend dos armazenamentos e, no modo "file", os arquivos usados.
+type Store struct {
+	Kind                string `yaml:"kind" env:"USER_STORE" flag:"store" default:"firestore" usage:"user store: firestore or file"`
+	UsersFile           string `yaml:"users_file" env:"USERS_FILE" default:"users.json"`
+	SigningKeysFile     string `yaml:"signing_keys_file" env:"SIGNING_KEYS_FILE" default:"signing_keys.json"`
+	ServiceAccountsFile string `yaml:"service_accounts_file" env:"SERVICE_ACCOUNTS_FILE" default:"service_accounts.json"`
+	TenantsFile         string `yaml:"tenants_file" env:"TENANTS_FILE" default:"tenants.json"`
+}
+
+// Firestore identifica o projeto e o banco usados quando Store.Kind é "firestore".
+type Firestore struct {
+	ProjectID  string `yaml:"project_id" env:"FIRESTORE_PROJECT_ID" default:"analise-sped-db"`
+	DatabaseID string `yaml:"database_id" env:"FIRESTORE_DATABASE_ID" default:"analise-sped-db"`
+}
+
+// Tokens define a validade e os claims iss/aud dos tokens emitidos.
+type Tokens struct {
+	AccessTTL       time.Duration `yaml:"access_ttl" env:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTTL      time.Duration `yaml:"refresh_ttl" env:"REFRESH_TOKEN_TTL" default:"168h"`
+	MFAChallengeTTL time.Duration `yaml:"mfa_challenge_ttl" env:"MFA_CHALLENGE_TTL" default:"5m"`
+	// Issuer e Audience vazios usam os padrões do pacote auth.
+	Issuer   string `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience string `yaml:"audience" env:"JWT_AUDIENCE"`
+}
+
+// Keys configura as chaves de assinatura; Algorithm vazio usa RS256.
+type Keys struct {
+	Algorithm        string        `yaml:"algorithm" env:"JWT_ALGORITHM"`
+	RotationInterval time.Duration `yaml:"rotation_interval" env:"KEY_ROTATION_INTERVAL" default:"720h"`
+}
+
+// Login define os limites de tentativas de login.
+type Login struct {
+	MaxUserFailures int           `yaml:"max_user_failures" env:"LOGIN_MAX_USER_FAILURES" default:"5"`
+	MaxIPFailures   int           `yaml:"max_ip_failures" env:"LOGIN_MAX_IP_FAILURES" default:"50"`
+	LockoutDuration time.Duration `yaml:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION" default:"15m"`
+	BackoffBase     time.Duration `yaml:"backoff_base" env:"LOGIN_BACKOFF_BASE" default:"1s"`
+	BackoffMax      time.Duration `yaml:"backoff_max" env:"LOGIN_BACKOFF_MAX" default:"30s"`
+	FailureWindow   time.Duration `yaml:"failure_window" env:"LOGIN_FAILURE_WINDOW" default:"15m"`
+}
+
+// Passwords define a política de senhas e a redefinição de senha.
+type Passwords struct {
+	MinLength      int    `yaml:"min_length" env:"PASSWORD_MIN_LENGTH" default:"8"`
+	MinCharClasses int    `yaml:"min_char_classes" env:"PASSWORD_MIN_CHAR_CLASSES" default:"1"`
+	BcryptCost     int    `yaml:"bcrypt_cost" env:"BCRYPT_COST" default:"10"`
+	BreachedFile   string `yaml:"breached_file" env:"BREACHED_PASSWORDS_FILE"`
+
+	ResetTTL time.Duration `yaml:"reset_ttl" env:"PASSWORD_RESET_TTL" default:"30m"`
+	ResetURL string        `yaml:"reset_url" env:"PASSWORD_RESET_URL"`
+}
+
+// Roles aponta o arquivo com os grupos de roles; vazio não define grupos.
+type Roles struct {
+	GroupsFile string `yaml:"groups_file" env:"ROLE_GROUPS_FILE"`
+}
+
+// Notifier escolhe o canal das notificações: "log" (apenas desenvolvimento) ou "smtp".
+type Notifier struct {
+	Kind         string `yaml:"kind" env:"NOTIFIER" default:"log"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     string `yaml:"smtp_port" env:"SMTP_PORT" default:"587"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+	SMTPFrom     string `yaml:"smtp_from" env:"SMTP_FROM"`
+}
+
+// MFA configura o segundo fator; TOTPIssuer vazio usa o padrão do pacote auth.
+type MFA struct {
+	TOTPIssuer string `yaml:"totp_issuer" env:"TOTP_ISSUER"`
+}
+
+// OIDC configura o login com provedores de identidade externos.
+type OIDC struct {
+	ProvidersFile string        `yaml:"providers_file" env:"OIDC_PROVIDERS_FILE"`
+	StateTTL      time.Duration `yaml:"state_ttl" env:"OIDC_STATE_TTL" default:"10m"`
+	FrontendURL   string        `yaml:"frontend_url" env:"OIDC_FRONTEND_URL"`
+}
+
+// LDAP aponta o arquivo de configuração do Active Directory/LDAP; vazio desabilita.
+type LDAP struct {
+	ConfigFile string `yaml:"config_file" env:"LDAP_CONFIG_FILE"`
+}
+
+// Load lê a configuração; args são os argumentos da linha de comando (nil ignora as flags).
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	if err := configloader.Load(cfg, "auth", args); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate confere os valores que não dependem dos arquivos externos; a política de senhas
+// e as configurações de OIDC e LDAP são validadas pelo pacote auth ao serem instaladas.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT inválido: %d", c.Port))
+	}
+	if c.Store.Kind != "firestore" && c.Store.Kind != "file" {
+		errs = append(errs, fmt.Errorf("USER_STORE inválido: %q (use \"firestore\" ou \"file\")", c.Store.Kind))
+	}
+	if c.Store.Kind == "firestore" && (c.Firestore.ProjectID == "" || c.Firestore.DatabaseID == "") {
+		errs = append(errs, errors.New("FIRESTORE_PROJECT_ID e FIRESTORE_DATABASE_ID são obrigatórios quando USER_STORE=firestore"))
+	}
+	switch c.Notifier.Kind {
+	case "log":
+	case "smtp":
+		if c.Notifier.SMTPHost == "" || c.Notifier.SMTPFrom == "" {
+			errs = append(errs, errors.New("SMTP_HOST e SMTP_FROM são obrigatórios quando NOTIFIER=smtp"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("NOTIFIER inválido: %q (use \"log\" ou \"smtp\")", c.Notifier.Kind))
+	}
+
+	positiveInts := []struct {
+		name  string
+		value int
+	}{
+		{"LOGIN_MAX_USER_FAILURES", c.Login.MaxUserFailures},
+		{"LOGIN_MAX_IP_FAILURES", c.Login.MaxIPFailures},
+		{"PASSWORD_MIN_LENGTH", c.Passwords.MinLength},
+		{"PASSWORD_MIN_CHAR_CLASSES", c.Passwords.MinCharClasses},
+		{"BCRYPT_COST", c.Passwords.BcryptCost},
+	}
+	for _, v := range positiveInts {
+		if v.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s deve ser positivo: %d", v.name, v.value))
+		}
+	}
+	positiveDurations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"ACCESS_TOKEN_TTL", c.Tokens.AccessTTL},
+		{"REFRESH_TOKEN_TTL", c.Tokens.RefreshTTL},
+		{"MFA_CHALLENGE_TTL", c.Tokens.MFAChallengeTTL},
+		{"KEY_ROTATION_INTERVAL", c.Keys.RotationInterval},
+		{"LOGIN_LOCKOUT_DURATION", c.Login.LockoutDuration},
+		{"LOGIN_BACKOFF_BASE", c.Login.BackoffBase},
+		{"LOGIN_BACKOFF_MAX", c.Login.BackoffMax},
+		{"LOGIN_FAILURE_WINDOW", c.Login.FailureWindow},
+		{"PASSWORD_RESET_TTL", c.Passwords.ResetTTL},
+		{"OIDC_STATE_TTL", c.OIDC.StateTTL},
+	}
+	for _, v := range positiveDurations {
+		if v.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s deve ser positivo: %s", v.name, v.value))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,418 @@
+// internal/core/auth/admin.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidUsername é retornado quando o username não segue usernamePattern.
+	ErrInvalidUsername = errors.New("username inválido: use de 3 a 64 caracteres entre letras, números, '.', '_', '-' e '@'")
+	// ErrEmptyPassword é retornado quando uma senha vazia é informada.
+	ErrEmptyPassword = errors.New("a senha não pode ser vazia")
+	// ErrInvalidEmail é retornado quando o e-mail informado não é um endereço válido.
+	ErrInvalidEmail = errors.New("e-mail inválido")
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]{3,64}$`)
+
+// UserInfo é a visão de um usuário exposta pela API de administração, sem o hash da senha.
+type UserInfo struct {
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+	Tenants  []string `json:"tenants"`
+	Email    string   `json:"email,omitempty"`
+	Disabled bool     `json:"disabled"`
+	// MFAEnabled indica se o usuário concluiu o cadastro TOTP.
+	MFAEnabled bool `json:"mfa_enabled"`
+	// ExternalIDs lista as identidades OIDC vinculadas ("<provedor>:<sub>").
+	ExternalIDs []string `json:"external_ids"`
+}
+
+// CreateUserInput contém os dados para criar um usuário.
+type CreateUserInput struct {
+	Username string
+	Password string
+	Roles    []string
+	Tenants  []string
+	Email    string
+}
+
+// UpdateUserInput contém as alterações de um usuário; campos nil são mantidos.
+type UpdateUserInput struct {
+	Roles    *[]string
+	Tenants  *[]string
+	Email    *string
+	Disabled *bool
+}
+
+// AdminService define as operações de administração de usuários e roles.
+type AdminService interface {
+	ListUsers(ctx context.Context) ([]UserInfo, error)
+	GetUser(ctx context.Context, username string) (*UserInfo, error)
+	CreateUser(ctx context.Context, input CreateUserInput) (*UserInfo, error)
+	UpdateUser(ctx context.Context, username string, input UpdateUserInput) (*UserInfo, error)
+	DeleteUser(ctx context.Context, username string) error
+	AssignRole(ctx context.Context, username, role string) (*UserInfo, error)
+	RevokeRole(ctx context.Context, username, role string) (*UserInfo, error)
+	// AssignTenant e RevokeTenant alteram as empresas clientes do usuário; a alteração vale
+	// a partir do próximo login ou refresh.
+	AssignTenant(ctx context.Context, username, tenant string) (*UserInfo, error)
+	RevokeTenant(ctx context.Context, username, tenant string) (*UserInfo, error)
+	ResetPassword(ctx context.Context, username, password string) error
+	// UnlockUser e UnlockIP removem o bloqueio e o backoff de login.
+	UnlockUser(ctx context.Context, username string) error
+	UnlockIP(ctx context.Context, ip string) error
+	// ResetMFA remove o segundo fator, para usuários que perderam o autenticador e os
+	// códigos de recuperação.
+	ResetMFA(ctx context.Context, username string) error
+	// UnlinkIdentity remove o vínculo com uma identidade OIDC; o próximo login por ela segue
+	// a configuração do provedor (vínculo pelo e-mail, criação ou recusa).
+	UnlinkIdentity(ctx context.Context, username, externalID string) error
+	// RoleGroups retorna os grupos de roles aceitos na atribuição.
+	RoleGroups() RoleGroups
+}
+
+type adminService struct {
+	users    UserRepository
+	attempts LoginAttemptStore
+	tenants  TenantStore
+	sessions SessionService
+	audit    auditor
+	// roleGroups valida as roles atribuídas e é listado em RoleGroups.
+	roleGroups RoleGroups
+	// passwordPolicy é aplicada às senhas definidas pela administração.
+	passwordPolicy PasswordPolicy
+}
+
+// NewAdminService cria o serviço de administração de usuários. As alterações são
+// registradas na auditoria com o administrador anexado ao contexto (WithActor).
+// Desativar ou remover um usuário encerra todas as suas sessões.
+func NewAdminService(users UserRepository, attempts LoginAttemptStore, tenants TenantStore, sessions SessionService, audit AuditStore, roleGroups RoleGroups, passwordPolicy PasswordPolicy) AdminService {
+	return &adminService{
+		users:          users,
+		attempts:       attempts,
+		tenants:        tenants,
+		sessions:       sessions,
+		audit:          auditor{store: audit},
+		roleGroups:     roleGroups,
+		passwordPolicy: passwordPolicy.withDefaults(),
+	}
+}
+
+func (s *adminService) RoleGroups() RoleGroups {
+	if s.roleGroups == nil {
+		return RoleGroups{}
+	}
+	return s.roleGroups
+}
+
+func (s *adminService) ListUsers(ctx context.Context) ([]UserInfo, error) {
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]UserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, *toUserInfo(&user))
+	}
+	return infos, nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, username string) (*UserInfo, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return toUserInfo(user), nil
+}
+
+func (s *adminService) CreateUser(ctx context.Context, input CreateUserInput) (*UserInfo, error) {
+	if !usernamePattern.MatchString(input.Username) {
+		return nil, ErrInvalidUsername
+	}
+	roles, err := s.roleGroups.normalize(input.Roles)
+	if err != nil {
+		return nil, err
+	}
+	tenants, err := normalizeTenants(ctx, s.tenants, input.Tenants)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEmail(input.Email); err != nil {
+		return nil, err
+	}
+	hash, err := s.passwordPolicy.hash(input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{Username: input.Username, PasswordHash: hash, Roles: roles, Tenants: tenants, Email: input.Email}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditUserCreate, user.Username, true, "roles: "+formatRoles(roles))
+	return toUserInfo(user), nil
+}
+
+func (s *adminService) UpdateUser(ctx context.Context, username string, input UpdateUserInput) (*UserInfo, error) {
+	var previousRoles, previousTenants []string
+	info, err := s.modify(ctx, username, func(user *User) error {
+		previousRoles, previousTenants = user.Roles, user.Tenants
+		if input.Roles != nil {
+			roles, err := s.roleGroups.normalize(*input.Roles)
+			if err != nil {
+				return err
+			}
+			user.Roles = roles
+		}
+		if input.Tenants != nil {
+			tenants, err := normalizeTenants(ctx, s.tenants, *input.Tenants)
+			if err != nil {
+				return err
+			}
+			user.Tenants = tenants
+		}
+		if input.Email != nil {
+			if err := validateEmail(*input.Email); err != nil {
+				return err
+			}
+			user.Email = *input.Email
+		}
+		if input.Disabled != nil {
+			user.Disabled = *input.Disabled
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Roles != nil {
+		s.audit.record(ctx, AuditRoleChange, username, true, formatRoles(previousRoles)+" -> "+formatRoles(info.Roles))
+	}
+	if input.Tenants != nil {
+		s.audit.record(ctx, AuditTenantChange, username, true, formatRoles(previousTenants)+" -> "+formatRoles(info.Tenants))
+	}
+	if input.Email != nil {
+		s.audit.record(ctx, AuditUserUpdate, username, true, "e-mail alterado")
+	}
+	if input.Disabled != nil {
+		detail := "reativado"
+		if *input.Disabled {
+			detail = "desativado"
+		}
+		s.audit.record(ctx, AuditUserUpdate, username, true, detail)
+	}
+	if input.Disabled != nil && *input.Disabled {
+		if _, err := s.sessions.RevokeAll(ctx, username, ""); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+func (s *adminService) DeleteUser(ctx context.Context, username string) error {
+	if err := s.users.Delete(ctx, username); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditUserDelete, username, true, "")
+	_, err := s.sessions.RevokeAll(ctx, username, "")
+	return err
+}
+
+func (s *adminService) AssignRole(ctx context.Context, username, role string) (*UserInfo, error) {
+	info, err := s.modify(ctx, username, func(user *User) error {
+		roles, err := s.roleGroups.normalize(append(user.Roles, role))
+		if err != nil {
+			return err
+		}
+		user.Roles = roles
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditRoleChange, username, true, "+"+role)
+	return info, nil
+}
+
+func (s *adminService) RevokeRole(ctx context.Context, username, role string) (*UserInfo, error) {
+	info, err := s.modify(ctx, username, func(user *User) error {
+		kept := make([]string, 0, len(user.Roles))
+		for _, existing := range user.Roles {
+			if existing != role {
+				kept = append(kept, existing)
+			}
+		}
+		user.Roles = kept
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditRoleChange, username, true, "-"+role)
+	return info, nil
+}
+
+func (s *adminService) AssignTenant(ctx context.Context, username, tenant string) (*UserInfo, error) {
+	info, err := s.modify(ctx, username, func(user *User) error {
+		tenants, err := normalizeTenants(ctx, s.tenants, append(user.Tenants, tenant))
+		if err != nil {
+			return err
+		}
+		user.Tenants = tenants
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditTenantChange, username, true, "+"+tenant)
+	return info, nil
+}
+
+func (s *adminService) RevokeTenant(ctx context.Context, username, tenant string) (*UserInfo, error) {
+	info, err := s.modify(ctx, username, func(user *User) error {
+		kept := make([]string, 0, len(user.Tenants))
+		for _, existing := range user.Tenants {
+			if existing != tenant {
+				kept = append(kept, existing)
+			}
+		}
+		user.Tenants = kept
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditTenantChange, username, true, "-"+tenant)
+	return info, nil
+}
+
+func (s *adminService) ResetPassword(ctx context.Context, username, password string) error {
+	_, err := s.modify(ctx, username, func(user *User) error {
+		if isDirectoryUser(user) {
+			return ErrDirectoryPassword
+		}
+		hash, err := s.passwordPolicy.hash(password)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hash
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditPasswordReset, username, true, "redefinida pela administração")
+	return nil
+}
+
+func (s *adminService) UnlockUser(ctx context.Context, username string) error {
+	if _, err := s.users.FindByUsername(ctx, username); err != nil {
+		return err
+	}
+	if err := s.attempts.Delete(ctx, userAttemptKey(username)); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditUserUpdate, username, true, "bloqueio de login removido")
+	return nil
+}
+
+func (s *adminService) UnlockIP(ctx context.Context, ip string) error {
+	return s.attempts.Delete(ctx, ipAttemptKey(ip))
+}
+
+func (s *adminService) ResetMFA(ctx context.Context, username string) error {
+	_, err := s.modify(ctx, username, func(user *User) error {
+		clearTOTP(user)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditMFADisable, username, true, "removido pela administração")
+	return nil
+}
+
+func (s *adminService) UnlinkIdentity(ctx context.Context, username, externalID string) error {
+	_, err := s.modify(ctx, username, func(user *User) error {
+		kept := make([]string, 0, len(user.ExternalIDs))
+		for _, existing := range user.ExternalIDs {
+			if existing != externalID {
+				kept = append(kept, existing)
+			}
+		}
+		user.ExternalIDs = kept
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditUserUpdate, username, true, "identidade "+externalID+" desvinculada")
+	return nil
+}
+
+// modify carrega o usuário, aplica a alteração e grava o resultado.
+func (s *adminService) modify(ctx context.Context, username string, change func(user *User) error) (*UserInfo, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(user); err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return toUserInfo(user), nil
+}
+
+// formatRoles descreve uma lista de roles (ou de empresas) nos eventos de auditoria.
+func formatRoles(roles []string) string {
+	if len(roles) == 0 {
+		return "(nenhuma)"
+	}
+	return strings.Join(roles, ", ")
+}
+
+// validateEmail aceita e-mail vazio (usuário sem e-mail) ou um endereço simples, sem nome.
+func validateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+func toUserInfo(user *User) *UserInfo {
+	roles := user.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	tenants := user.Tenants
+	if tenants == nil {
+		tenants = []string{}
+	}
+	externalIDs := user.ExternalIDs
+	if externalIDs == nil {
+		externalIDs = []string{}
+	}
+	return &UserInfo{
+		Username:    user.Username,
+		Roles:       roles,
+		Tenants:     tenants,
+		Email:       user.Email,
+		Disabled:    user.Disabled,
+		MFAEnabled:  user.TOTPEnabled,
+		ExternalIDs: externalIDs,
+	}
+}
@@ -0,0 +1,133 @@
+// internal/core/auth/audit.go
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Tipos de evento registrados no histórico de auditoria.
+const (
+	AuditLoginSuccess   = "login.success"
+	AuditLoginFailure   = "login.failure"
+	AuditMFAChallenge   = "login.mfa_challenge"
+	AuditTokenRefresh   = "token.refresh"
+	AuditTokenReuse     = "token.refresh_reuse"
+	AuditLogout         = "logout"
+	AuditSessionRevoke  = "session.revoke"
+	AuditAPIKeyExchange = "api_key.exchange"
+	AuditAPIKeyCreate   = "api_key.create"
+	AuditAPIKeyRevoke   = "api_key.revoke"
+	AuditPasswordChange = "password.change"
+	AuditPasswordReset  = "password.reset"
+	AuditMFAEnable      = "mfa.enable"
+	AuditMFADisable     = "mfa.disable"
+	AuditUserCreate     = "user.create"
+	AuditUserUpdate     = "user.update"
+	AuditUserDelete     = "user.delete"
+	AuditRoleChange     = "user.roles"
+	AuditTenantChange   = "user.tenants"
+	AuditTenantSwitch   = "tenant.switch"
+	AuditTenantCreate   = "tenant.create"
+	AuditTenantUpdate   = "tenant.update"
+	AuditTenantDelete   = "tenant.delete"
+
+	AuditServiceAccountCreate = "service_account.create"
+	AuditServiceAccountUpdate = "service_account.update"
+	AuditServiceAccountDelete = "service_account.delete"
+	AuditServiceAccountRoles  = "service_account.roles"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type auditContextKey string
+
+const (
+	auditContextKeyClient auditContextKey = "client"
+	auditContextKeyActor  auditContextKey = "actor"
+)
+
+// WithClientInfo anexa ao contexto a origem da requisição, registrada nos eventos de auditoria.
+func WithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, auditContextKeyClient, client)
+}
+
+// WithActor anexa ao contexto o usuário autenticado que faz a requisição.
+func WithActor(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, auditContextKeyActor, username)
+}
+
+func clientInfoFrom(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(auditContextKeyClient).(ClientInfo)
+	return client
+}
+
+func actorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(auditContextKeyActor).(string)
+	return actor
+}
+
+// auditor grava eventos de auditoria. Falhas de gravação são apenas registradas no log,
+// para não impedir a operação auditada.
+type auditor struct {
+	store AuditStore
+}
+
+// record grava um evento com a origem e o autor anexados ao contexto.
+func (a auditor) record(ctx context.Context, eventType, username string, success bool, detail string) {
+	a.recordFrom(ctx, clientInfoFrom(ctx), eventType, username, success, detail)
+}
+
+// recordFrom grava um evento com a origem informada explicitamente.
+func (a auditor) recordFrom(ctx context.Context, client ClientInfo, eventType, username string, success bool, detail string) {
+	id, err := randomToken(16)
+	if err != nil {
+		log.Printf("Erro ao gerar id de evento de auditoria: %v", err)
+		return
+	}
+	event := &AuditEvent{
+		ID:        id,
+		Type:      eventType,
+		Username:  username,
+		Success:   success,
+		Detail:    detail,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Timestamp: time.Now().UTC(),
+	}
+	if actor := actorFrom(ctx); actor != username {
+		event.Actor = actor
+	}
+	if err := a.store.Record(ctx, event); err != nil {
+		log.Printf("Erro ao gravar evento de auditoria %s de %s: %v", eventType, username, err)
+	}
+}
+
+// AuditService define a consulta ao histórico de auditoria.
+type AuditService interface {
+	Query(ctx context.Context, filter AuditFilter) ([]AuditEvent, error)
+}
+
+type auditService struct {
+	store AuditStore
+}
+
+// NewAuditService cria o serviço de consulta ao histórico de auditoria.
+func NewAuditService(store AuditStore) AuditService {
+	return &auditService{store: store}
+}
+
+// Query aplica o limite padrão (100) e o máximo (1000) de eventos por consulta.
+func (s *auditService) Query(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	return s.store.Query(ctx, filter)
+}
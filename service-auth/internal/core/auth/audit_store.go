@@ -0,0 +1,40 @@
+// internal/core/auth/audit_store.go
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEvent registra uma operação de autenticação ou de administração.
+type AuditEvent struct {
+	ID   string `firestore:"id" json:"id"`
+	Type string `firestore:"type" json:"type"`
+	// Username é o usuário (ou conta de serviço) afetado pela operação.
+	Username string `firestore:"username" json:"username"`
+	// Actor é o usuário autenticado que executou a operação, quando diferente de Username
+	// (ex.: um administrador alterando roles).
+	Actor     string    `firestore:"actor" json:"actor,omitempty"`
+	Success   bool      `firestore:"success" json:"success"`
+	Detail    string    `firestore:"detail" json:"detail,omitempty"`
+	IP        string    `firestore:"ip" json:"ip,omitempty"`
+	UserAgent string    `firestore:"userAgent" json:"user_agent,omitempty"`
+	Timestamp time.Time `firestore:"timestamp" json:"timestamp"`
+}
+
+// AuditFilter restringe a consulta ao histórico. Campos vazios não filtram; o intervalo
+// é [From, To).
+type AuditFilter struct {
+	Username string
+	Type     string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// AuditStore abstrai o armazenamento do histórico de auditoria.
+type AuditStore interface {
+	Record(ctx context.Context, event *AuditEvent) error
+	// Query retorna os eventos que atendem ao filtro, do mais recente para o mais antigo.
+	Query(ctx context.Context, filter AuditFilter) ([]AuditEvent, error)
+}
@@ -0,0 +1,46 @@
+// internal/core/auth/claims.go
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultTokenIssuer   = "auth-service"
+	defaultTokenAudience = "services-with-gateway"
+)
+
+// Claims são as claims dos access tokens emitidos pelo serviço: as registradas na RFC 7519
+// (iss, sub, aud, exp, nbf, iat, jti) e as da aplicação. Quem consome os tokens é o gateway
+// (api-gateway/authMiddleware.js), que deve acompanhar mudanças nestas claims.
+type Claims struct {
+	// Username repete o sub para os clientes que já liam este claim.
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+	// Tenant é a empresa cliente ativa; ausente para usuários sem empresas e contas de serviço.
+	Tenant string `json:"tenant,omitempty"`
+	// SessionID identifica a sessão (login) que emitiu o token; ausente em contas de serviço.
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// newClaims monta as claims de um access token emitido em now.
+func newClaims(config TokenConfig, jti, subject string, roles []string, tenant, sessionID string, now time.Time) *Claims {
+	return &Claims{
+		Username:  subject,
+		Roles:     roles,
+		Tenant:    tenant,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    config.Issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{config.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(config.AccessTokenTTL)),
+		},
+	}
+}
@@ -0,0 +1,93 @@
+// internal/core/auth/file_key_store.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// fileKeyStore guarda as chaves de assinatura em um arquivo JSON local (permissão 0600),
+// para que os tokens emitidos continuem válidos após reiniciar o serviço em desenvolvimento.
+type fileKeyStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileKeyStore cria um armazenamento de chaves de assinatura no arquivo informado.
+func NewFileKeyStore(path string) KeyStore {
+	return &fileKeyStore{path: path}
+}
+
+func (s *fileKeyStore) List(ctx context.Context) ([]SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+func (s *fileKeyStore) Save(ctx context.Context, key SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.read()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range keys {
+		if keys[i].KID == key.KID {
+			keys[i] = key
+			replaced = true
+		}
+	}
+	if !replaced {
+		keys = append(keys, key)
+	}
+	return s.write(keys)
+}
+
+func (s *fileKeyStore) Delete(ctx context.Context, kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.read()
+	if err != nil {
+		return err
+	}
+	kept := keys[:0]
+	for _, key := range keys {
+		if key.KID != kid {
+			kept = append(kept, key)
+		}
+	}
+	return s.write(kept)
+}
+
+func (s *fileKeyStore) read() ([]SigningKey, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de chaves: %w", err)
+	}
+
+	var keys []SigningKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("arquivo de chaves inválido: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *fileKeyStore) write(keys []SigningKey) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo de chaves: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// internal/core/auth/file_repository.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// fileUserRepository mantém os usuários em memória, carregados de um arquivo JSON e
+// regravados nele a cada alteração. Pensado para desenvolvimento local e testes,
+// sem dependência do Firestore.
+type fileUserRepository struct {
+	mu    sync.RWMutex
+	path  string
+	users map[string]User
+}
+
+// NewFileUserRepository carrega os usuários do arquivo JSON informado (uma lista de User).
+// Se o arquivo não existir, o repositório começa vazio e o arquivo é criado na primeira gravação.
+func NewFileUserRepository(path string) (UserRepository, error) {
+	repo := &fileUserRepository{path: path, users: make(map[string]User)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de usuários: %w", err)
+	}
+
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("arquivo de usuários inválido: %w", err)
+	}
+	for _, user := range users {
+		repo.users[user.Username] = user
+	}
+	return repo, nil
+}
+
+func (r *fileUserRepository) FindByUsername(ctx context.Context, username string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+func (r *fileUserRepository) FindByExternalID(ctx context.Context, externalID string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		for _, id := range user.ExternalIDs {
+			if id == externalID {
+				return copyUser(user), nil
+			}
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *fileUserRepository) List(ctx context.Context) ([]User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sorted(), nil
+}
+
+func (r *fileUserRepository) Create(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.Username]; ok {
+		return ErrUserExists
+	}
+	r.users[user.Username] = *copyUser(*user)
+	return r.save()
+}
+
+func (r *fileUserRepository) Update(ctx context.Context, user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.Username]; !ok {
+		return ErrUserNotFound
+	}
+	r.users[user.Username] = *copyUser(*user)
+	return r.save()
+}
+
+func (r *fileUserRepository) Modify(ctx context.Context, username string, change func(user *User) error) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user := copyUser(stored)
+	if err := change(user); err != nil {
+		return nil, err
+	}
+	r.users[username] = *copyUser(*user)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *fileUserRepository) UpdatePasswordHash(ctx context.Context, username, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.PasswordHash = hash
+	r.users[username] = user
+	return r.save()
+}
+
+func (r *fileUserRepository) Delete(ctx context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[username]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.users, username)
+	return r.save()
+}
+
+// sorted retorna cópias dos usuários ordenadas por username. Deve ser chamado com o lock adquirido.
+func (r *fileUserRepository) sorted() []User {
+	users := make([]User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, *copyUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+// save regrava o arquivo inteiro. Deve ser chamado com o lock de escrita adquirido.
+func (r *fileUserRepository) save() error {
+	data, err := json.MarshalIndent(r.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, data, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo de usuários: %w", err)
+	}
+	return nil
+}
+
+func copyUser(user User) *User {
+	user.Roles = append([]string(nil), user.Roles...)
+	user.Tenants = append([]string(nil), user.Tenants...)
+	user.ExternalIDs = append([]string(nil), user.ExternalIDs...)
+	user.RecoveryCodes = append([]string(nil), user.RecoveryCodes...)
+	return &user
+}
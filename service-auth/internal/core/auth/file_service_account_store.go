@@ -0,0 +1,222 @@
+// internal/core/auth/file_service_account_store.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fileServiceAccountStore guarda contas de serviço e chaves de API em um arquivo JSON local
+// (permissão 0600), para que as chaves sobrevivam a reinícios em desenvolvimento.
+type fileServiceAccountStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// serviceAccountsFile é o formato do arquivo: contas e chaves em listas separadas.
+type serviceAccountsFile struct {
+	Accounts []ServiceAccount `json:"accounts"`
+	Keys     []APIKey         `json:"keys"`
+}
+
+// NewFileServiceAccountStore cria um armazenamento de contas de serviço no arquivo informado.
+func NewFileServiceAccountStore(path string) ServiceAccountStore {
+	return &fileServiceAccountStore{path: path}
+}
+
+func (s *fileServiceAccountStore) ListAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(data.Accounts, func(i, j int) bool { return data.Accounts[i].Name < data.Accounts[j].Name })
+	return data.Accounts, nil
+}
+
+func (s *fileServiceAccountStore) FindAccount(ctx context.Context, name string) (*ServiceAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range data.Accounts {
+		if account.Name == name {
+			return &account, nil
+		}
+	}
+	return nil, ErrServiceAccountNotFound
+}
+
+func (s *fileServiceAccountStore) CreateAccount(ctx context.Context, account *ServiceAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	for _, existing := range data.Accounts {
+		if existing.Name == account.Name {
+			return ErrServiceAccountExists
+		}
+	}
+	data.Accounts = append(data.Accounts, *account)
+	return s.write(data)
+}
+
+func (s *fileServiceAccountStore) UpdateAccount(ctx context.Context, account *ServiceAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range data.Accounts {
+		if data.Accounts[i].Name == account.Name {
+			data.Accounts[i] = *account
+			return s.write(data)
+		}
+	}
+	return ErrServiceAccountNotFound
+}
+
+func (s *fileServiceAccountStore) DeleteAccount(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	found := false
+	accounts := data.Accounts[:0]
+	for _, account := range data.Accounts {
+		if account.Name == name {
+			found = true
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	if !found {
+		return ErrServiceAccountNotFound
+	}
+	keys := data.Keys[:0]
+	for _, key := range data.Keys {
+		if key.Account != name {
+			keys = append(keys, key)
+		}
+	}
+	data.Accounts, data.Keys = accounts, keys
+	return s.write(data)
+}
+
+func (s *fileServiceAccountStore) ListKeys(ctx context.Context, account string) ([]APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]APIKey, 0)
+	for _, key := range data.Keys {
+		if key.Account == account {
+			keys = append(keys, key)
+		}
+	}
+	sortAPIKeys(keys)
+	return keys, nil
+}
+
+func (s *fileServiceAccountStore) FindKey(ctx context.Context, id string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range data.Keys {
+		if key.ID == id {
+			return &key, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (s *fileServiceAccountStore) SaveKey(ctx context.Context, key *APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range data.Keys {
+		if data.Keys[i].ID == key.ID {
+			data.Keys[i] = *key
+			return s.write(data)
+		}
+	}
+	data.Keys = append(data.Keys, *key)
+	return s.write(data)
+}
+
+func (s *fileServiceAccountStore) TouchKey(ctx context.Context, id string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range data.Keys {
+		if data.Keys[i].ID == id {
+			data.Keys[i].LastUsedAt = &usedAt
+			return s.write(data)
+		}
+	}
+	return ErrAPIKeyNotFound
+}
+
+func (s *fileServiceAccountStore) read() (*serviceAccountsFile, error) {
+	var data serviceAccountsFile
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return &data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de contas de serviço: %w", err)
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("arquivo de contas de serviço inválido: %w", err)
+	}
+	return &data, nil
+}
+
+func (s *fileServiceAccountStore) write(data *serviceAccountsFile) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, content, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo de contas de serviço: %w", err)
+	}
+	return nil
+}
+
+// sortAPIKeys ordena as chaves pela data de criação.
+func sortAPIKeys(keys []APIKey) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+}
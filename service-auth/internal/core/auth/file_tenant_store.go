@@ -0,0 +1,125 @@
+// internal/core/auth/file_tenant_store.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// fileTenantStore guarda as empresas clientes em um arquivo JSON local, para desenvolvimento.
+type fileTenantStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileTenantStore cria um armazenamento de empresas clientes no arquivo informado.
+func NewFileTenantStore(path string) TenantStore {
+	return &fileTenantStore{path: path}
+}
+
+func (s *fileTenantStore) List(ctx context.Context) ([]Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+func (s *fileTenantStore) Find(ctx context.Context, id string) (*Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, tenant := range tenants {
+		if tenant.ID == id {
+			return &tenant, nil
+		}
+	}
+	return nil, ErrTenantNotFound
+}
+
+func (s *fileTenantStore) Create(ctx context.Context, tenant *Tenant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants, err := s.read()
+	if err != nil {
+		return err
+	}
+	for _, existing := range tenants {
+		if existing.ID == tenant.ID {
+			return ErrTenantExists
+		}
+	}
+	return s.write(append(tenants, *tenant))
+}
+
+func (s *fileTenantStore) Update(ctx context.Context, tenant *Tenant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range tenants {
+		if tenants[i].ID == tenant.ID {
+			tenants[i] = *tenant
+			return s.write(tenants)
+		}
+	}
+	return ErrTenantNotFound
+}
+
+func (s *fileTenantStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range tenants {
+		if tenants[i].ID == id {
+			return s.write(append(tenants[:i], tenants[i+1:]...))
+		}
+	}
+	return ErrTenantNotFound
+}
+
+func (s *fileTenantStore) read() ([]Tenant, error) {
+	tenants := []Tenant{}
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tenants, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de empresas clientes: %w", err)
+	}
+	if err := json.Unmarshal(content, &tenants); err != nil {
+		return nil, fmt.Errorf("arquivo de empresas clientes inválido: %w", err)
+	}
+	return tenants, nil
+}
+
+func (s *fileTenantStore) write(tenants []Tenant) error {
+	content, err := json.MarshalIndent(tenants, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, content, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo de empresas clientes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// internal/core/auth/firestore_audit_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+)
+
+const auditEventsCollection = "auditEvents"
+
+// firestoreAuditStore guarda o histórico na coleção "auditEvents", com o id do evento como ID.
+// Os filtros por username ou type combinados com o intervalo de datas exigem índices
+// compostos (username/type + timestamp decrescente).
+type firestoreAuditStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreAuditStore cria um armazenamento de auditoria no Firestore.
+func NewFirestoreAuditStore(db *firestore.Client) AuditStore {
+	return &firestoreAuditStore{db: db}
+}
+
+func (s *firestoreAuditStore) Record(ctx context.Context, event *AuditEvent) error {
+	_, err := s.db.Collection(auditEventsCollection).Doc(event.ID).Set(ctx, event)
+	return err
+}
+
+func (s *firestoreAuditStore) Query(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	query := s.db.Collection(auditEventsCollection).Query
+	if filter.Username != "" {
+		query = query.Where("username", "==", filter.Username)
+	}
+	if filter.Type != "" {
+		query = query.Where("type", "==", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("timestamp", ">=", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("timestamp", "<", filter.To)
+	}
+
+	docs, err := query.OrderBy("timestamp", firestore.Desc).Limit(filter.Limit).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]AuditEvent, 0, len(docs))
+	for _, doc := range docs {
+		var event AuditEvent
+		if err := doc.DataTo(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,48 @@
+// internal/core/auth/firestore_key_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+)
+
+const signingKeysCollection = "signingKeys"
+
+// firestoreKeyStore guarda as chaves de assinatura na coleção "signingKeys", com o kid como ID.
+// Por conter chaves privadas, o acesso a essa coleção deve ser restrito à conta do Auth Service.
+type firestoreKeyStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreKeyStore cria um armazenamento de chaves de assinatura no Firestore.
+func NewFirestoreKeyStore(db *firestore.Client) KeyStore {
+	return &firestoreKeyStore{db: db}
+}
+
+func (s *firestoreKeyStore) List(ctx context.Context) ([]SigningKey, error) {
+	docs, err := s.db.Collection(signingKeysCollection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]SigningKey, 0, len(docs))
+	for _, doc := range docs {
+		var key SigningKey
+		if err := doc.DataTo(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *firestoreKeyStore) Save(ctx context.Context, key SigningKey) error {
+	_, err := s.db.Collection(signingKeysCollection).Doc(key.KID).Set(ctx, key)
+	return err
+}
+
+func (s *firestoreKeyStore) Delete(ctx context.Context, kid string) error {
+	_, err := s.db.Collection(signingKeysCollection).Doc(kid).Delete(ctx)
+	return err
+}
@@ -0,0 +1,65 @@
+// internal/core/auth/firestore_login_attempt_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const loginAttemptsCollection = "loginAttempts"
+
+// firestoreLoginAttemptStore guarda as tentativas de login na coleção "loginAttempts".
+// O ID do documento é o hash da chave, já que usernames e IPv6 podem conter caracteres
+// não aceitos em IDs do Firestore.
+type firestoreLoginAttemptStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreLoginAttemptStore cria um armazenamento de tentativas de login no Firestore.
+func NewFirestoreLoginAttemptStore(db *firestore.Client) LoginAttemptStore {
+	return &firestoreLoginAttemptStore{db: db}
+}
+
+func (s *firestoreLoginAttemptStore) Get(ctx context.Context, key string) (*LoginAttempts, error) {
+	doc, err := s.db.Collection(loginAttemptsCollection).Doc(hashToken(key)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &LoginAttempts{Key: key}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var attempts LoginAttempts
+	if err := doc.DataTo(&attempts); err != nil {
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+func (s *firestoreLoginAttemptStore) Update(ctx context.Context, key string, change func(attempts *LoginAttempts) error) error {
+	ref := s.db.Collection(loginAttemptsCollection).Doc(hashToken(key))
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		attempts := LoginAttempts{Key: key}
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if err := doc.DataTo(&attempts); err != nil {
+				return err
+			}
+		}
+		if err := change(&attempts); err != nil {
+			return err
+		}
+		return tx.Set(ref, attempts)
+	})
+}
+
+func (s *firestoreLoginAttemptStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.Collection(loginAttemptsCollection).Doc(hashToken(key)).Delete(ctx)
+	return err
+}
@@ -0,0 +1,58 @@
+// internal/core/auth/firestore_mfa_challenge_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const mfaChallengesCollection = "mfaChallenges"
+
+// firestoreMFAChallengeStore guarda os desafios na coleção "mfaChallenges", com o hash do
+// token como ID. Uma política de TTL sobre expiresAt limpa os não concluídos.
+type firestoreMFAChallengeStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreMFAChallengeStore cria um armazenamento de desafios de segundo fator no Firestore.
+func NewFirestoreMFAChallengeStore(db *firestore.Client) MFAChallengeStore {
+	return &firestoreMFAChallengeStore{db: db}
+}
+
+func (s *firestoreMFAChallengeStore) Save(ctx context.Context, challenge *MFAChallenge) error {
+	_, err := s.db.Collection(mfaChallengesCollection).Doc(challenge.TokenHash).Set(ctx, challenge)
+	return err
+}
+
+func (s *firestoreMFAChallengeStore) Find(ctx context.Context, tokenHash string) (*MFAChallenge, error) {
+	doc, err := s.db.Collection(mfaChallengesCollection).Doc(tokenHash).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrMFAChallengeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var challenge MFAChallenge
+	if err := doc.DataTo(&challenge); err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (s *firestoreMFAChallengeStore) Consume(ctx context.Context, tokenHash string) error {
+	ref := s.db.Collection(mfaChallengesCollection).Doc(tokenHash)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrMFAChallengeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+}
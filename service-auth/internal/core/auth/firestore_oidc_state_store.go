@@ -0,0 +1,51 @@
+// internal/core/auth/firestore_oidc_state_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const oidcStatesCollection = "oidcStates"
+
+// firestoreOIDCStateStore guarda os states na coleção "oidcStates", com o hash do state
+// como ID. Uma política de TTL sobre expiresAt limpa os logins abandonados.
+type firestoreOIDCStateStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreOIDCStateStore cria um armazenamento de states OIDC no Firestore.
+func NewFirestoreOIDCStateStore(db *firestore.Client) OIDCStateStore {
+	return &firestoreOIDCStateStore{db: db}
+}
+
+func (s *firestoreOIDCStateStore) Save(ctx context.Context, state *OIDCState) error {
+	_, err := s.db.Collection(oidcStatesCollection).Doc(state.StateHash).Set(ctx, state)
+	return err
+}
+
+func (s *firestoreOIDCStateStore) Consume(ctx context.Context, stateHash string) (*OIDCState, error) {
+	ref := s.db.Collection(oidcStatesCollection).Doc(stateHash)
+
+	var state OIDCState
+	err := s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrOIDCStateNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&state); err != nil {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
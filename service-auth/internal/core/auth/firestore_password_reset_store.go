@@ -0,0 +1,51 @@
+// internal/core/auth/firestore_password_reset_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const passwordResetsCollection = "passwordResets"
+
+// firestorePasswordResetStore guarda os tokens de redefinição na coleção "passwordResets",
+// com o hash do token como ID. Uma política de TTL sobre expiresAt limpa os não usados.
+type firestorePasswordResetStore struct {
+	db *firestore.Client
+}
+
+// NewFirestorePasswordResetStore cria um armazenamento de tokens de redefinição no Firestore.
+func NewFirestorePasswordResetStore(db *firestore.Client) PasswordResetStore {
+	return &firestorePasswordResetStore{db: db}
+}
+
+func (s *firestorePasswordResetStore) Save(ctx context.Context, token *PasswordResetToken) error {
+	_, err := s.db.Collection(passwordResetsCollection).Doc(token.TokenHash).Set(ctx, token)
+	return err
+}
+
+func (s *firestorePasswordResetStore) Consume(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	ref := s.db.Collection(passwordResetsCollection).Doc(tokenHash)
+
+	var token PasswordResetToken
+	err := s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrResetTokenNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&token); err != nil {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,85 @@
+// internal/core/auth/firestore_refresh_token_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const refreshTokensCollection = "refreshTokens"
+
+// firestoreRefreshTokenStore guarda os refresh tokens na coleção "refreshTokens",
+// usando o hash do token como ID do documento.
+type firestoreRefreshTokenStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreRefreshTokenStore cria um armazenamento de refresh tokens no Firestore.
+func NewFirestoreRefreshTokenStore(db *firestore.Client) RefreshTokenStore {
+	return &firestoreRefreshTokenStore{db: db}
+}
+
+func (s *firestoreRefreshTokenStore) Save(ctx context.Context, token *RefreshToken) error {
+	_, err := s.db.Collection(refreshTokensCollection).Doc(token.TokenHash).Set(ctx, token)
+	return err
+}
+
+func (s *firestoreRefreshTokenStore) Find(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	doc, err := s.db.Collection(refreshTokensCollection).Doc(tokenHash).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token RefreshToken
+	if err := doc.DataTo(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *firestoreRefreshTokenStore) MarkUsed(ctx context.Context, tokenHash string) error {
+	ref := s.db.Collection(refreshTokensCollection).Doc(tokenHash)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrRefreshTokenNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		used, err := doc.DataAt("used")
+		if err != nil {
+			return err
+		}
+		if used == true {
+			return ErrRefreshTokenReused
+		}
+		return tx.Update(ref, []firestore.Update{{Path: "used", Value: true}})
+	})
+}
+
+func (s *firestoreRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	iter := s.db.Collection(refreshTokensCollection).Where("familyId", "==", familyID).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := doc.Ref.Update(ctx, []firestore.Update{{Path: "revoked", Value: true}}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// internal/core/auth/firestore_repository.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+const usersCollection = "users"
+
+type firestoreUserRepository struct {
+	db *firestore.Client
+}
+
+// NewFirestoreUserRepository cria um repositório de usuários sobre a coleção "users" do Firestore.
+// Os documentos são localizados pelo campo username; novos usuários usam o username como ID.
+func NewFirestoreUserRepository(db *firestore.Client) UserRepository {
+	return &firestoreUserRepository{db: db}
+}
+
+func (r *firestoreUserRepository) FindByUsername(ctx context.Context, username string) (*User, error) {
+	doc, err := r.findDoc(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *firestoreUserRepository) FindByExternalID(ctx context.Context, externalID string) (*User, error) {
+	query := r.db.Collection(usersCollection).Where("externalIds", "array-contains", externalID).Limit(1).Documents(ctx)
+	defer query.Stop()
+
+	doc, err := query.Next()
+	if err == iterator.Done {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *firestoreUserRepository) List(ctx context.Context) ([]User, error) {
+	docs, err := r.db.Collection(usersCollection).OrderBy("username", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0, len(docs))
+	for _, doc := range docs {
+		var user User
+		if err := doc.DataTo(&user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *firestoreUserRepository) Create(ctx context.Context, user *User) error {
+	query := r.db.Collection(usersCollection).Where("username", "==", user.Username).Limit(1)
+	return r.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+		if len(docs) > 0 {
+			return ErrUserExists
+		}
+		return tx.Create(r.db.Collection(usersCollection).Doc(user.Username), user)
+	})
+}
+
+func (r *firestoreUserRepository) Update(ctx context.Context, user *User) error {
+	doc, err := r.findDoc(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	_, err = doc.Ref.Set(ctx, user)
+	return err
+}
+
+func (r *firestoreUserRepository) Modify(ctx context.Context, username string, change func(user *User) error) (*User, error) {
+	query := r.db.Collection(usersCollection).Where("username", "==", username).Limit(1)
+	var user User
+	err := r.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return ErrUserNotFound
+		}
+		user = User{}
+		if err := docs[0].DataTo(&user); err != nil {
+			return err
+		}
+		if err := change(&user); err != nil {
+			return err
+		}
+		return tx.Set(docs[0].Ref, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *firestoreUserRepository) UpdatePasswordHash(ctx context.Context, username, hash string) error {
+	doc, err := r.findDoc(ctx, username)
+	if err != nil {
+		return err
+	}
+	_, err = doc.Ref.Update(ctx, []firestore.Update{{Path: "passwordHash", Value: hash}})
+	return err
+}
+
+func (r *firestoreUserRepository) Delete(ctx context.Context, username string) error {
+	doc, err := r.findDoc(ctx, username)
+	if err != nil {
+		return err
+	}
+	_, err = doc.Ref.Delete(ctx)
+	return err
+}
+
+// findDoc localiza o documento do usuário pelo campo username, já que documentos antigos
+// foram criados manualmente com IDs arbitrários.
+func (r *firestoreUserRepository) findDoc(ctx context.Context, username string) (*firestore.DocumentSnapshot, error) {
+	query := r.db.Collection(usersCollection).Where("username", "==", username).Limit(1).Documents(ctx)
+	defer query.Stop()
+
+	doc, err := query.Next()
+	if err == iterator.Done {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
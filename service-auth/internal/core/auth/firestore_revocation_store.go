@@ -0,0 +1,50 @@
+// internal/core/auth/firestore_revocation_store.go
+package auth
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const revokedTokensCollection = "revokedTokens"
+
+// revokedToken é o documento gravado na coleção "revokedTokens", com o jti como ID.
+// Uma política de TTL do Firestore sobre o campo expiresAt remove as entradas vencidas.
+type revokedToken struct {
+	ExpiresAt time.Time `firestore:"expiresAt"`
+}
+
+type firestoreRevocationStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreRevocationStore cria uma lista de revogação no Firestore.
+func NewFirestoreRevocationStore(db *firestore.Client) RevocationStore {
+	return &firestoreRevocationStore{db: db}
+}
+
+func (s *firestoreRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := s.db.Collection(revokedTokensCollection).Doc(jti).Set(ctx, revokedToken{ExpiresAt: expiresAt})
+	return err
+}
+
+func (s *firestoreRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	doc, err := s.db.Collection(revokedTokensCollection).Doc(jti).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var entry revokedToken
+	if err := doc.DataTo(&entry); err != nil {
+		return false, err
+	}
+	// A remoção por TTL não é imediata, então a expiração é conferida aqui também.
+	return time.Now().Before(entry.ExpiresAt), nil
+}
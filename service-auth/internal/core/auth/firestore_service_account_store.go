@@ -0,0 +1,151 @@
+// internal/core/auth/firestore_service_account_store.go
+package auth
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	serviceAccountsCollection = "serviceAccounts"
+	apiKeysCollection         = "apiKeys"
+)
+
+// firestoreServiceAccountStore guarda as contas na coleção "serviceAccounts" (ID = nome)
+// e as chaves na coleção "apiKeys" (ID = id da chave).
+type firestoreServiceAccountStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreServiceAccountStore cria um armazenamento de contas de serviço no Firestore.
+func NewFirestoreServiceAccountStore(db *firestore.Client) ServiceAccountStore {
+	return &firestoreServiceAccountStore{db: db}
+}
+
+func (s *firestoreServiceAccountStore) ListAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	docs, err := s.db.Collection(serviceAccountsCollection).OrderBy("name", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]ServiceAccount, 0, len(docs))
+	for _, doc := range docs {
+		var account ServiceAccount
+		if err := doc.DataTo(&account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func (s *firestoreServiceAccountStore) FindAccount(ctx context.Context, name string) (*ServiceAccount, error) {
+	doc, err := s.db.Collection(serviceAccountsCollection).Doc(name).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrServiceAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var account ServiceAccount
+	if err := doc.DataTo(&account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (s *firestoreServiceAccountStore) CreateAccount(ctx context.Context, account *ServiceAccount) error {
+	_, err := s.db.Collection(serviceAccountsCollection).Doc(account.Name).Create(ctx, account)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrServiceAccountExists
+	}
+	return err
+}
+
+func (s *firestoreServiceAccountStore) UpdateAccount(ctx context.Context, account *ServiceAccount) error {
+	ref := s.db.Collection(serviceAccountsCollection).Doc(account.Name)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrServiceAccountNotFound
+			}
+			return err
+		}
+		return tx.Set(ref, account)
+	})
+}
+
+func (s *firestoreServiceAccountStore) DeleteAccount(ctx context.Context, name string) error {
+	ref := s.db.Collection(serviceAccountsCollection).Doc(name)
+	keys := s.db.Collection(apiKeysCollection).Where("account", "==", name)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrServiceAccountNotFound
+			}
+			return err
+		}
+		docs, err := tx.Documents(keys).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if err := tx.Delete(doc.Ref); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(ref)
+	})
+}
+
+func (s *firestoreServiceAccountStore) ListKeys(ctx context.Context, account string) ([]APIKey, error) {
+	docs, err := s.db.Collection(apiKeysCollection).Where("account", "==", account).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, 0, len(docs))
+	for _, doc := range docs {
+		var key APIKey
+		if err := doc.DataTo(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sortAPIKeys(keys)
+	return keys, nil
+}
+
+func (s *firestoreServiceAccountStore) FindKey(ctx context.Context, id string) (*APIKey, error) {
+	doc, err := s.db.Collection(apiKeysCollection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKey
+	if err := doc.DataTo(&key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *firestoreServiceAccountStore) SaveKey(ctx context.Context, key *APIKey) error {
+	_, err := s.db.Collection(apiKeysCollection).Doc(key.ID).Set(ctx, key)
+	return err
+}
+
+func (s *firestoreServiceAccountStore) TouchKey(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := s.db.Collection(apiKeysCollection).Doc(id).Update(ctx, []firestore.Update{{Path: "lastUsedAt", Value: usedAt}})
+	if status.Code(err) == codes.NotFound {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
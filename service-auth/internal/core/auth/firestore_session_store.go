@@ -0,0 +1,81 @@
+// internal/core/auth/firestore_session_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const sessionsCollection = "sessions"
+
+// firestoreSessionStore guarda as sessões na coleção "sessions", com o ID da sessão como ID
+// do documento. Uma política de TTL sobre expiresAt remove as sessões expiradas.
+type firestoreSessionStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreSessionStore cria um armazenamento de sessões no Firestore.
+func NewFirestoreSessionStore(db *firestore.Client) SessionStore {
+	return &firestoreSessionStore{db: db}
+}
+
+func (s *firestoreSessionStore) Update(ctx context.Context, id string, change func(session *Session) error) error {
+	ref := s.db.Collection(sessionsCollection).Doc(id)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		session := Session{ID: id}
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if err := doc.DataTo(&session); err != nil {
+				return err
+			}
+		}
+		if err := change(&session); err != nil {
+			return err
+		}
+		return tx.Set(ref, session)
+	})
+}
+
+func (s *firestoreSessionStore) Find(ctx context.Context, id string) (*Session, error) {
+	doc, err := s.db.Collection(sessionsCollection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := doc.DataTo(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *firestoreSessionStore) ListByUser(ctx context.Context, username string) ([]Session, error) {
+	iter := s.db.Collection(sessionsCollection).Where("username", "==", username).Documents(ctx)
+	defer iter.Stop()
+
+	var sessions []Session
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return sessions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var session Session
+		if err := doc.DataTo(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+}
@@ -0,0 +1,89 @@
+// internal/core/auth/firestore_tenant_store.go
+package auth
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const tenantsCollection = "tenants"
+
+// firestoreTenantStore guarda as empresas clientes na coleção "tenants" (ID = id da empresa).
+type firestoreTenantStore struct {
+	db *firestore.Client
+}
+
+// NewFirestoreTenantStore cria um armazenamento de empresas clientes no Firestore.
+func NewFirestoreTenantStore(db *firestore.Client) TenantStore {
+	return &firestoreTenantStore{db: db}
+}
+
+func (s *firestoreTenantStore) List(ctx context.Context) ([]Tenant, error) {
+	docs, err := s.db.Collection(tenantsCollection).OrderBy("id", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := make([]Tenant, 0, len(docs))
+	for _, doc := range docs {
+		var tenant Tenant
+		if err := doc.DataTo(&tenant); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+func (s *firestoreTenantStore) Find(ctx context.Context, id string) (*Tenant, error) {
+	doc, err := s.db.Collection(tenantsCollection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant Tenant
+	if err := doc.DataTo(&tenant); err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (s *firestoreTenantStore) Create(ctx context.Context, tenant *Tenant) error {
+	_, err := s.db.Collection(tenantsCollection).Doc(tenant.ID).Create(ctx, tenant)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrTenantExists
+	}
+	return err
+}
+
+func (s *firestoreTenantStore) Update(ctx context.Context, tenant *Tenant) error {
+	ref := s.db.Collection(tenantsCollection).Doc(tenant.ID)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrTenantNotFound
+			}
+			return err
+		}
+		return tx.Set(ref, tenant)
+	})
+}
+
+func (s *firestoreTenantStore) Delete(ctx context.Context, id string) error {
+	ref := s.db.Collection(tenantsCollection).Doc(id)
+	return s.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrTenantNotFound
+			}
+			return err
+		}
+		return tx.Delete(ref)
+	})
+}
@@ -0,0 +1,25 @@
+// internal/core/auth/key_store.go
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// SigningKey é uma chave privada de assinatura de JWT persistida em formato PEM (PKCS#8).
+// Uma chave com RetiredAt preenchido não assina mais tokens, mas continua publicada no JWKS
+// até que todos os tokens assinados por ela tenham expirado.
+type SigningKey struct {
+	KID           string    `firestore:"kid" json:"kid"`
+	Algorithm     string    `firestore:"algorithm" json:"algorithm"`
+	PrivateKeyPEM string    `firestore:"privateKeyPem" json:"privateKeyPem"`
+	CreatedAt     time.Time `firestore:"createdAt" json:"createdAt"`
+	RetiredAt     time.Time `firestore:"retiredAt" json:"retiredAt"`
+}
+
+// KeyStore abstrai o armazenamento das chaves de assinatura.
+type KeyStore interface {
+	List(ctx context.Context) ([]SigningKey, error)
+	Save(ctx context.Context, key SigningKey) error
+	Delete(ctx context.Context, kid string) error
+}
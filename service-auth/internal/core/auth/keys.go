@@ -0,0 +1,387 @@
+// internal/core/auth/keys.go
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritmos de assinatura suportados.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	rsaKeyBits              = 2048
+	defaultRotationInterval = 30 * 24 * time.Hour
+	keyCheckInterval        = 5 * time.Minute
+	// unknownKeyReloadInterval limita as recargas provocadas por tokens com kid desconhecido,
+	// para que tokens forjados não consultem o armazenamento a cada requisição.
+	unknownKeyReloadInterval = 10 * time.Second
+	keyReloadTimeout         = 10 * time.Second
+)
+
+// ErrUnknownKey é retornado quando o kid de um token não corresponde a nenhuma chave publicada.
+var ErrUnknownKey = errors.New("chave de assinatura desconhecida")
+
+// JWK é a representação pública de uma chave no JWKS (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet é o documento publicado em /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyConfig define o algoritmo das novas chaves e a política de rotação.
+type KeyConfig struct {
+	Algorithm        string
+	RotationInterval time.Duration
+	// RetirementGrace é por quanto tempo uma chave aposentada continua publicada;
+	// deve ser ao menos o maior tempo de vida de um token assinado. NewKeyManager soma o
+	// intervalo de recarga das chaves (5 minutos), durante o qual outra instância ainda
+	// pode assinar com a chave que acabou de ser aposentada.
+	RetirementGrace time.Duration
+}
+
+// KeyManager mantém as chaves de assinatura, assina tokens com a chave ativa e
+// resolve a chave pública de verificação pelo kid do token.
+type KeyManager interface {
+	Sign(claims jwt.Claims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	ValidMethods() []string
+	JWKS() JWKSet
+	// Rotate cria uma nova chave ativa imediatamente e aposenta as anteriores.
+	Rotate(ctx context.Context) error
+	// Start verifica periodicamente se a chave ativa deve ser rotacionada e recarrega
+	// as chaves do armazenamento, até o contexto ser cancelado.
+	Start(ctx context.Context)
+}
+
+type loadedKey struct {
+	record SigningKey
+	signer crypto.Signer
+}
+
+type keyManager struct {
+	store  KeyStore
+	config KeyConfig
+
+	mu      sync.RWMutex
+	keys    map[string]loadedKey
+	current string
+
+	// reloadMu serializa as recargas por kid desconhecido; lastReload é a última delas.
+	reloadMu   sync.Mutex
+	lastReload time.Time
+}
+
+// NewKeyManager carrega as chaves do armazenamento, criando a primeira chave se necessário.
+func NewKeyManager(ctx context.Context, store KeyStore, config KeyConfig) (KeyManager, error) {
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmRS256
+	}
+	if config.Algorithm != AlgorithmRS256 && config.Algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("algoritmo de assinatura não suportado: %s", config.Algorithm)
+	}
+	if config.RotationInterval <= 0 {
+		config.RotationInterval = defaultRotationInterval
+	}
+	if config.RetirementGrace <= 0 {
+		config.RetirementGrace = defaultAccessTokenTTL
+	}
+	config.RetirementGrace += keyCheckInterval
+
+	m := &keyManager{store: store, config: config}
+	if err := m.rotateIfDue(ctx, false); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *keyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key, ok := m.keys[m.current]
+	m.mu.RUnlock()
+	if !ok {
+		return "", errors.New("nenhuma chave de assinatura ativa")
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.record.Algorithm), claims)
+	token.Header["kid"] = key.record.KID
+	return token.SignedString(key.signer)
+}
+
+func (m *keyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := m.key(kid)
+	if !ok {
+		// A chave pode ter sido criada por outra instância (ou pelo authctl) depois da última
+		// recarga periódica.
+		if key, ok = m.reloadForKey(kid); !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+	if token.Method.Alg() != key.record.Algorithm {
+		return nil, fmt.Errorf("algoritmo %s não corresponde à chave %s", token.Method.Alg(), kid)
+	}
+	return key.signer.Public(), nil
+}
+
+func (m *keyManager) key(kid string) (loadedKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, ok := m.keys[kid]
+	return key, ok
+}
+
+// reloadForKey recarrega as chaves do armazenamento para encontrar um kid desconhecido, no
+// máximo uma vez a cada unknownKeyReloadInterval.
+func (m *keyManager) reloadForKey(kid string) (loadedKey, bool) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	// Outra requisição pode ter recarregado as chaves enquanto esta esperava.
+	if key, ok := m.key(kid); ok {
+		return key, true
+	}
+	if time.Since(m.lastReload) < unknownKeyReloadInterval {
+		return loadedKey{}, false
+	}
+	m.lastReload = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyReloadTimeout)
+	defer cancel()
+	if err := m.reload(ctx); err != nil {
+		log.Printf("Erro ao recarregar chaves de assinatura: %v", err)
+		return loadedKey{}, false
+	}
+	return m.key(kid)
+}
+
+// reload carrega as chaves do armazenamento sem rotacioná-las nem remover as expiradas.
+func (m *keyManager) reload(ctx context.Context) error {
+	records, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar chaves de assinatura: %w", err)
+	}
+	now := time.Now()
+	var kept []SigningKey
+	var current *SigningKey
+	for i, record := range records {
+		if !record.RetiredAt.IsZero() && now.After(record.RetiredAt.Add(m.config.RetirementGrace)) {
+			continue
+		}
+		kept = append(kept, record)
+		if record.RetiredAt.IsZero() && (current == nil || record.CreatedAt.After(current.CreatedAt)) {
+			current = &records[i]
+		}
+	}
+	if current == nil {
+		return errors.New("nenhuma chave de assinatura ativa no armazenamento")
+	}
+	return m.install(kept, current.KID)
+}
+
+func (m *keyManager) ValidMethods() []string {
+	return []string{AlgorithmRS256, AlgorithmEdDSA}
+}
+
+func (m *keyManager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		set.Keys = append(set.Keys, publicJWK(key))
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func (m *keyManager) Rotate(ctx context.Context) error {
+	return m.rotateIfDue(ctx, true)
+}
+
+func (m *keyManager) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(keyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.rotateIfDue(ctx, false); err != nil {
+					log.Printf("Erro ao verificar rotação de chaves de assinatura: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// rotateIfDue recarrega as chaves do armazenamento, gera uma nova chave ativa quando não há
+// nenhuma, quando a atual passou do intervalo de rotação ou quando force é verdadeiro,
+// e remove as chaves aposentadas cujo período de carência terminou.
+func (m *keyManager) rotateIfDue(ctx context.Context, force bool) error {
+	records, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar chaves de assinatura: %w", err)
+	}
+
+	now := time.Now()
+	var active []SigningKey
+	var kept []SigningKey
+	for _, record := range records {
+		switch {
+		case record.RetiredAt.IsZero():
+			active = append(active, record)
+			kept = append(kept, record)
+		case now.After(record.RetiredAt.Add(m.config.RetirementGrace)):
+			if err := m.store.Delete(ctx, record.KID); err != nil {
+				return fmt.Errorf("erro ao remover chave expirada %s: %w", record.KID, err)
+			}
+		default:
+			kept = append(kept, record)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+
+	if force || len(active) == 0 || now.Sub(active[len(active)-1].CreatedAt) >= m.config.RotationInterval {
+		newKey, err := generateSigningKey(m.config.Algorithm, now)
+		if err != nil {
+			return err
+		}
+		if err := m.store.Save(ctx, newKey); err != nil {
+			return fmt.Errorf("erro ao salvar nova chave de assinatura: %w", err)
+		}
+		kept = append(kept, newKey)
+		active = append(active, newKey)
+		log.Printf("Nova chave de assinatura %s (%s) ativada", newKey.KID, newKey.Algorithm)
+	}
+
+	// Apenas a chave mais recente assina; as demais ativas são aposentadas.
+	for i := range kept {
+		if kept[i].RetiredAt.IsZero() && kept[i].KID != active[len(active)-1].KID {
+			kept[i].RetiredAt = now
+			if err := m.store.Save(ctx, kept[i]); err != nil {
+				return fmt.Errorf("erro ao aposentar chave %s: %w", kept[i].KID, err)
+			}
+		}
+	}
+
+	return m.install(kept, active[len(active)-1].KID)
+}
+
+// install troca as chaves carregadas; current é a chave que passa a assinar.
+func (m *keyManager) install(records []SigningKey, current string) error {
+	loaded := make(map[string]loadedKey, len(records))
+	for _, record := range records {
+		signer, err := parsePrivateKey(record.PrivateKeyPEM)
+		if err != nil {
+			return fmt.Errorf("chave %s inválida: %w", record.KID, err)
+		}
+		loaded[record.KID] = loadedKey{record: record, signer: signer}
+	}
+
+	m.mu.Lock()
+	m.keys = loaded
+	m.current = current
+	m.mu.Unlock()
+	return nil
+}
+
+func generateSigningKey(algorithm string, now time.Time) (SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return SigningKey{}, fmt.Errorf("algoritmo de assinatura não suportado: %s", algorithm)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("erro ao gerar chave de assinatura: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("erro ao serializar chave de assinatura: %w", err)
+	}
+	kid, err := randomToken(12)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return SigningKey{
+		KID:           kid,
+		Algorithm:     algorithm,
+		PrivateKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		CreatedAt:     now,
+	}, nil
+}
+
+func parsePrivateKey(pemData string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, errors.New("PEM inválido")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tipo de chave não suportado")
+	}
+	return signer, nil
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func publicJWK(key loadedKey) JWK {
+	jwk := JWK{Kid: key.record.KID, Use: "sig", Alg: key.record.Algorithm}
+	switch pub := key.signer.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestKeyManager(t *testing.T, store KeyStore) *keyManager {
+	t.Helper()
+	keys, err := NewKeyManager(context.Background(), store, KeyConfig{Algorithm: AlgorithmEdDSA, RetirementGrace: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys.(*keyManager)
+}
+
+func parseWith(keys KeyManager, signed string) error {
+	_, err := jwt.Parse(signed, keys.Keyfunc, jwt.WithValidMethods(keys.ValidMethods()))
+	return err
+}
+
+func TestKeyManagerReloadsUnknownKey(t *testing.T) {
+	// Duas instâncias com o mesmo armazenamento: a segunda rotaciona a chave e assina com a
+	// nova antes de a primeira recarregar as chaves.
+	store := NewFileKeyStore(filepath.Join(t.TempDir(), "signing_keys.json"))
+	first := newTestKeyManager(t, store)
+	second := newTestKeyManager(t, store)
+	if err := second.Rotate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := second.Sign(jwt.RegisteredClaims{Subject: "maria"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(first, signed); err != nil {
+		t.Fatalf("token da chave nova: %v", err)
+	}
+
+	// Um kid inexistente não provoca outra recarga dentro do intervalo.
+	reloaded := first.lastReload
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{Subject: "maria"})
+	forged.Header["kid"] = "inexistente"
+	key, _ := first.key(first.current)
+	forgedSigned, err := forged.SignedString(key.signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(first, forgedSigned); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("kid inexistente: %v, esperado %v", err, ErrUnknownKey)
+	}
+	if !first.lastReload.Equal(reloaded) {
+		t.Error("kid inexistente recarregou as chaves dentro do intervalo")
+	}
+}
+
+func TestKeyManagerKeepsRetiredKeyForGrace(t *testing.T) {
+	store := NewFileKeyStore(filepath.Join(t.TempDir(), "signing_keys.json"))
+	keys := newTestKeyManager(t, store)
+	if grace := keys.config.RetirementGrace; grace != time.Minute+keyCheckInterval {
+		t.Fatalf("RetirementGrace = %v, esperado o TTL mais %v", grace, keyCheckInterval)
+	}
+	retired := keys.current
+	ctx := context.Background()
+	if err := keys.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Aposentada há mais que o TTL, mas dentro do intervalo de recarga: continua publicada.
+	records, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if record.KID == retired {
+			record.RetiredAt = time.Now().Add(-2 * time.Minute)
+			if err := store.Save(ctx, record); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := keys.rotateIfDue(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := keys.key(retired); !ok {
+		t.Error("chave aposentada removida antes do fim da carência")
+	}
+}
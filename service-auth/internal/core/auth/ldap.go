@@ -0,0 +1,243 @@
+// internal/core/auth/ldap.go
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDirectoryUnavailable é retornado quando o diretório LDAP não responde ou recusa a
+	// conta de serviço; o motivo fica apenas no log.
+	ErrDirectoryUnavailable = errors.New("não foi possível consultar o diretório de usuários")
+	// ErrDirectoryNoAccess é retornado quando o usuário do diretório não pertence a nenhum
+	// grupo mapeado e a configuração exige um.
+	ErrDirectoryNoAccess = errors.New("o usuário não pertence a nenhum grupo com acesso ao sistema")
+	// ErrDirectoryPassword é retornado ao alterar a senha de um usuário do diretório, que só
+	// pode ser trocada no próprio diretório.
+	ErrDirectoryPassword = errors.New("a senha deste usuário é gerenciada pelo Active Directory/LDAP")
+)
+
+const (
+	// ldapExternalIDPrefix marca, em User.ExternalIDs, os usuários gerenciados pelo diretório.
+	ldapExternalIDPrefix = "ldap:"
+
+	defaultLDAPUserFilter        = "(&(objectCategory=person)(objectClass=user)(sAMAccountName={username}))"
+	defaultLDAPUsernameAttribute = "sAMAccountName"
+	defaultLDAPEmailAttribute    = "mail"
+	defaultLDAPGroupAttribute    = "memberOf"
+	defaultLDAPTimeout           = 10 * time.Second
+)
+
+// LDAPConfig configura a autenticação em um Active Directory ou outro diretório LDAP. Os
+// padrões seguem o Active Directory.
+type LDAPConfig struct {
+	// URL do servidor: ldaps://host:636 ou ldap://host:389 (de preferência com StartTLS).
+	URL      string `json:"url"`
+	StartTLS bool   `json:"start_tls"`
+	// CAFile é o certificado (PEM) da autoridade que emitiu o certificado do servidor, se
+	// não for confiável pelo sistema.
+	CAFile             string `json:"ca_file"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	// BindDN e BindPassword são a conta de serviço usada para localizar o usuário. Com
+	// BindPasswordEnv a senha é lida da variável de ambiente informada.
+	BindDN          string `json:"bind_dn"`
+	BindPassword    string `json:"bind_password"`
+	BindPasswordEnv string `json:"bind_password_env"`
+	// BaseDN é onde os usuários são procurados; UserFilter é o filtro da busca, com
+	// {username} substituído pelo login informado (escapado).
+	BaseDN     string `json:"base_dn"`
+	UserFilter string `json:"user_filter"`
+	// UsernameAttribute dá o username local (em minúsculas); EmailAttribute, o e-mail; e
+	// GroupAttribute, os DNs dos grupos do usuário.
+	UsernameAttribute string `json:"username_attribute"`
+	EmailAttribute    string `json:"email_attribute"`
+	GroupAttribute    string `json:"group_attribute"`
+	// GroupRoles mapeia grupos do diretório para roles. Chaves com DN comparam o DN completo;
+	// chaves só com o nome comparam o CN de qualquer grupo e não podem conceder RoleAdmin.
+	GroupRoles   map[string][]string `json:"group_roles"`
+	DefaultRoles []string            `json:"default_roles"`
+	// RequireGroup recusa o login de quem não pertence a nenhum grupo de GroupRoles.
+	RequireGroup   bool `json:"require_group"`
+	TimeoutSeconds int  `json:"timeout_seconds"`
+
+	// rootCAs é carregado de CAFile por LoadLDAPConfig.
+	rootCAs *x509.CertPool
+}
+
+// LoadLDAPConfig lê a configuração do diretório de um arquivo JSON (LDAPConfig) e a valida.
+// As roles são validadas contra as roles conhecidas e os grupos de roleGroups.
+func LoadLDAPConfig(path string, roleGroups RoleGroups) (*LDAPConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de configuração LDAP: %w", err)
+	}
+	var config LDAPConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("arquivo de configuração LDAP inválido: %w", err)
+	}
+
+	if !strings.HasPrefix(config.URL, "ldap://") && !strings.HasPrefix(config.URL, "ldaps://") {
+		return nil, fmt.Errorf("LDAP: url deve começar com ldap:// ou ldaps://: %q", config.URL)
+	}
+	if config.BaseDN == "" {
+		return nil, errors.New("LDAP: base_dn é obrigatório")
+	}
+	if config.BindPasswordEnv != "" {
+		config.BindPassword = os.Getenv(config.BindPasswordEnv)
+		if config.BindPassword == "" {
+			return nil, fmt.Errorf("LDAP: variável %s vazia", config.BindPasswordEnv)
+		}
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("LDAP: erro ao ler ca_file: %w", err)
+		}
+		config.rootCAs = x509.NewCertPool()
+		if !config.rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("LDAP: nenhum certificado válido em %s", config.CAFile)
+		}
+	}
+	if config.UserFilter == "" {
+		config.UserFilter = defaultLDAPUserFilter
+	}
+	if !strings.Contains(config.UserFilter, "{username}") {
+		return nil, errors.New("LDAP: user_filter deve conter {username}")
+	}
+	if config.UsernameAttribute == "" {
+		config.UsernameAttribute = defaultLDAPUsernameAttribute
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = defaultLDAPEmailAttribute
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = defaultLDAPGroupAttribute
+	}
+	if config.DefaultRoles, err = roleGroups.normalize(config.DefaultRoles); err != nil {
+		return nil, fmt.Errorf("LDAP: %w", err)
+	}
+	for group, roles := range config.GroupRoles {
+		if config.GroupRoles[group], err = roleGroups.normalize(roles); err != nil {
+			return nil, fmt.Errorf("LDAP: grupo %s: %w", group, err)
+		}
+		// Um grupo com o mesmo CN pode ser criado em qualquer OU; admin exige o DN completo.
+		if !isGroupDN(group) && containsRole(roleGroups.resolve(config.GroupRoles[group]), RoleAdmin) {
+			return nil, fmt.Errorf("LDAP: grupo %s: a role %s só pode ser concedida a um grupo identificado pelo DN completo", group, RoleAdmin)
+		}
+	}
+	return &config, nil
+}
+
+// isDirectoryUser informa se o usuário é gerenciado pelo diretório LDAP.
+func isDirectoryUser(user *User) bool {
+	for _, id := range user.ExternalIDs {
+		if strings.HasPrefix(id, ldapExternalIDPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ldapLogin confere a senha no diretório e sincroniza o usuário local: na primeira vez ele
+// é criado, sem senha local; depois, roles e e-mail são atualizados a cada login, pois o
+// diretório é a fonte dos grupos. Empresas e 2FA continuam locais.
+func (s *service) ldapLogin(ctx context.Context, attempt *loginAttempt, password string, client ClientInfo) (*User, error) {
+	username := attempt.username
+	identity, err := s.authenticateDirectory(ctx, attempt, password, client)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, mapped := s.ldap.roles(identity.Groups)
+	if !mapped && s.ldap.config.RequireGroup {
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, identity.Username, false, "ldap: sem grupo mapeado")
+		return nil, ErrDirectoryNoAccess
+	}
+
+	user, err := s.syncDirectoryUser(ctx, identity, roles)
+	if errors.Is(err, ErrUserExists) || errors.Is(err, ErrInvalidUsername) {
+		// Um usuário local (com senha própria) já usa o username, ou ele não é aceito
+		// localmente: o diretório não assume a conta.
+		log.Printf("Username %q do diretório não pode ser usado: %v", identity.Username, err)
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, username, false, "ldap: username do diretório recusado")
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		log.Printf("Erro ao sincronizar o usuário %s do diretório: %v", identity.Username, err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	return user, nil
+}
+
+// authenticateDirectory confere a senha no diretório, contabilizando a falha no throttle.
+func (s *service) authenticateDirectory(ctx context.Context, attempt *loginAttempt, password string, client ClientInfo) (*ldapIdentity, error) {
+	identity, err := s.ldap.authenticate(attempt.username, password)
+	if errors.Is(err, errLDAPInvalidCredentials) {
+		s.recordLoginFailure(ctx, attempt, client, "senha incorreta (ldap)")
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		log.Printf("Erro ao autenticar %s no diretório LDAP: %v", attempt.username, err)
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, attempt.username, false, "ldap: diretório indisponível")
+		return nil, ErrDirectoryUnavailable
+	}
+	return identity, nil
+}
+
+// syncDirectoryUser cria ou atualiza o usuário local de uma identidade do diretório.
+// Retorna ErrUserExists se o username pertence a um usuário que não é do diretório e
+// ErrInvalidUsername se ele não segue usernamePattern.
+func (s *service) syncDirectoryUser(ctx context.Context, identity *ldapIdentity, roles []string) (*User, error) {
+	externalID := ldapExternalIDPrefix + identity.Username
+
+	if !usernamePattern.MatchString(identity.Username) {
+		return nil, ErrInvalidUsername
+	}
+	if validateEmail(identity.Email) != nil {
+		identity.Email = ""
+	}
+
+	user, err := s.users.FindByUsername(ctx, identity.Username)
+	if errors.Is(err, ErrUserNotFound) {
+		user = &User{
+			Username:    identity.Username,
+			Roles:       roles,
+			Email:       identity.Email,
+			ExternalIDs: []string{externalID},
+		}
+		if err := s.users.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		s.audit.record(ctx, AuditUserCreate, user.Username, true, "criado no login ldap, roles: "+formatRoles(user.Roles))
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isDirectoryUser(user) {
+		return nil, ErrUserExists
+	}
+
+	if slices.Equal(user.Roles, roles) && user.Email == identity.Email {
+		return user, nil
+	}
+	previous := user.Roles
+	user.Roles = roles
+	user.Email = identity.Email
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if !slices.Equal(previous, roles) {
+		s.audit.record(ctx, AuditRoleChange, user.Username, true, formatRoles(previous)+" -> "+formatRoles(roles)+" (grupos do ldap)")
+	}
+	return user, nil
+}
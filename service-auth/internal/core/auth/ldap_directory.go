@@ -0,0 +1,174 @@
+// internal/core/auth/ldap_directory.go
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// errLDAPInvalidCredentials indica usuário inexistente no diretório, ambíguo ou com senha
+// incorreta; os casos não são diferenciados para o cliente.
+var errLDAPInvalidCredentials = errors.New("credenciais LDAP inválidas")
+
+// ldapIdentity é o usuário autenticado no diretório.
+type ldapIdentity struct {
+	Username string
+	DN       string
+	Email    string
+	Groups   []string
+}
+
+// ldapDirectory autentica usuários em um servidor LDAP: localiza o usuário com a conta de
+// serviço e confere a senha com um bind no DN encontrado. Cada login usa uma conexão nova.
+type ldapDirectory struct {
+	config    LDAPConfig
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	// dnRoles e cnRoles separam as chaves de GroupRoles com DN completo das que trazem só o CN.
+	dnRoles map[string][]string
+	cnRoles map[string][]string
+}
+
+func newLDAPDirectory(config LDAPConfig) *ldapDirectory {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify, RootCAs: config.rootCAs}
+	if host, _, err := net.SplitHostPort(strings.TrimPrefix(strings.TrimPrefix(config.URL, "ldaps://"), "ldap://")); err == nil {
+		tlsConfig.ServerName = host
+	}
+
+	timeout := time.Duration(config.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultLDAPTimeout
+	}
+
+	// Os grupos são comparados sem diferenciar maiúsculas: as chaves com DN pelo DN
+	// normalizado e as que trazem só o nome pelo CN do grupo.
+	dnRoles := make(map[string][]string)
+	cnRoles := make(map[string][]string)
+	for group, roles := range config.GroupRoles {
+		if isGroupDN(group) {
+			dnRoles[normalizeGroup(group)] = roles
+		} else {
+			cnRoles[normalizeGroup(group)] = roles
+		}
+	}
+	return &ldapDirectory{config: config, tlsConfig: tlsConfig, timeout: timeout, dnRoles: dnRoles, cnRoles: cnRoles}
+}
+
+// authenticate confere login e senha no diretório e retorna a identidade com os grupos.
+func (d *ldapDirectory) authenticate(username, password string) (*ldapIdentity, error) {
+	// Um bind com senha vazia é um bind anônimo, aceito pelo servidor sem conferir nada.
+	if username == "" || password == "" {
+		return nil, errLDAPInvalidCredentials
+	}
+
+	conn, err := ldap.DialURL(d.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.timeout}),
+		ldap.DialWithTLSConfig(d.tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetTimeout(d.timeout)
+
+	if d.config.StartTLS {
+		if err := conn.StartTLS(d.tlsConfig); err != nil {
+			return nil, fmt.Errorf("StartTLS: %w", err)
+		}
+	}
+	if d.config.BindDN != "" {
+		if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("bind da conta de serviço: %w", err)
+		}
+	}
+
+	filter := strings.ReplaceAll(d.config.UserFilter, "{username}", ldap.EscapeFilter(username))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		d.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(d.timeout.Seconds()), false, filter,
+		[]string{d.config.UsernameAttribute, d.config.EmailAttribute, d.config.GroupAttribute}, nil))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, errLDAPInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("busca do usuário: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, errLDAPInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("bind do usuário: %w", err)
+	}
+
+	identity := &ldapIdentity{
+		Username: strings.ToLower(entry.GetAttributeValue(d.config.UsernameAttribute)),
+		DN:       entry.DN,
+		Email:    strings.ToLower(entry.GetAttributeValue(d.config.EmailAttribute)),
+		Groups:   entry.GetAttributeValues(d.config.GroupAttribute),
+	}
+	if identity.Username == "" {
+		return nil, fmt.Errorf("entrada %s sem o atributo %s", entry.DN, d.config.UsernameAttribute)
+	}
+	return identity, nil
+}
+
+// roles retorna as roles padrão somadas às dos grupos do usuário e informa se algum grupo
+// estava mapeado.
+func (d *ldapDirectory) roles(groups []string) ([]string, bool) {
+	roles := append([]string{}, d.config.DefaultRoles...)
+	mapped := false
+	for _, group := range groups {
+		if groupRoles, ok := d.dnRoles[normalizeGroup(group)]; ok {
+			roles = append(roles, groupRoles...)
+			mapped = true
+		}
+		if groupRoles, ok := d.cnRoles[groupCN(group)]; ok {
+			roles = append(roles, groupRoles...)
+			mapped = true
+		}
+	}
+	// As roles já foram validadas no carregamento; basta ordenar e remover duplicatas.
+	slices.Sort(roles)
+	return slices.Compact(roles), mapped
+}
+
+// isGroupDN informa se a chave de GroupRoles é um DN (ex.: cn=Fiscal,ou=Grupos,dc=exemplo),
+// e não apenas o nome do grupo.
+func isGroupDN(group string) bool {
+	dn, err := ldap.ParseDN(group)
+	return err == nil && len(dn.RDNs) > 0
+}
+
+// normalizeGroup simplifica um DN (ou CN) de grupo para comparação.
+func normalizeGroup(group string) string {
+	if dn, err := ldap.ParseDN(group); err == nil && len(dn.RDNs) > 0 {
+		parts := make([]string, 0, len(dn.RDNs))
+		for _, rdn := range dn.RDNs {
+			for _, attr := range rdn.Attributes {
+				parts = append(parts, strings.ToLower(attr.Type)+"="+strings.ToLower(attr.Value))
+			}
+		}
+		return strings.Join(parts, ",")
+	}
+	return strings.ToLower(strings.TrimSpace(group))
+}
+
+// groupCN retorna o CN do grupo (o primeiro RDN do DN), em minúsculas.
+func groupCN(group string) string {
+	dn, err := ldap.ParseDN(group)
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return ""
+	}
+	return strings.ToLower(dn.RDNs[0].Attributes[0].Value)
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"auth-service/internal/ldaptest"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testDirectory é um Active Directory mínimo com a conta de serviço e dois usuários:
+// maria, do grupo Fiscal, e joao, sem grupos.
+var testDirectory = []ldaptest.Entry{
+	{DN: "dc=exemplo,dc=local", Attributes: map[string][]string{"objectClass": {"top", "domain"}}},
+	{
+		DN:         "cn=svc-auth,dc=exemplo,dc=local",
+		Password:   "segredo-servico",
+		Attributes: map[string][]string{"objectClass": {"top", "person", "user"}, "sAMAccountName": {"svc-auth"}},
+	},
+	{
+		DN:       "cn=Maria Souza,dc=exemplo,dc=local",
+		Password: "segredo-maria",
+		Attributes: map[string][]string{
+			"objectClass":    {"top", "person", "user"},
+			"objectCategory": {"person"},
+			"sAMAccountName": {"maria"},
+			"mail":           {"maria@exemplo.local"},
+			"memberOf":       {"cn=Fiscal,dc=exemplo,dc=local"},
+		},
+	},
+	{
+		DN:       "cn=Joao Lima,dc=exemplo,dc=local",
+		Password: "segredo-joao",
+		Attributes: map[string][]string{
+			"objectClass":    {"top", "person", "user"},
+			"objectCategory": {"person"},
+			"sAMAccountName": {"joao"},
+		},
+	},
+}
+
+// newTestLDAPConfig sobe o diretório de teste e carrega a configuração por LoadLDAPConfig,
+// como na inicialização do serviço.
+func newTestLDAPConfig(t *testing.T, requireGroup bool) *LDAPConfig {
+	t.Helper()
+	server, err := ldaptest.Start("127.0.0.1:0", testDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	config, err := LoadLDAPConfig(writeLDAPConfig(t, server.URL(), map[string][]string{"Fiscal": {"analise-icms"}}, requireGroup), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// writeLDAPConfig grava um arquivo de configuração do diretório de teste e retorna o caminho.
+func writeLDAPConfig(t *testing.T, url string, groupRoles map[string][]string, requireGroup bool) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{
+		"url":           url,
+		"bind_dn":       "cn=svc-auth,dc=exemplo,dc=local",
+		"bind_password": "segredo-servico",
+		"base_dn":       "dc=exemplo,dc=local",
+		"group_roles":   groupRoles,
+		"require_group": requireGroup,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ldap.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoginWithDirectoryCreatesUser(t *testing.T) {
+	stores, _ := newTestStores(t)
+	service := newTestService(t, stores, testOptions{ldap: newTestLDAPConfig(t, false)})
+	ctx := context.Background()
+
+	result, err := service.Login(ctx, "maria", "segredo-maria", testClient)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	claims, err := service.ValidateAccessToken(ctx, result.Tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.Username != "maria" || !slices.Equal(claims.Roles, []string{"analise-icms"}) {
+		t.Errorf("claims = %+v, esperado maria com a role analise-icms do grupo Fiscal", claims)
+	}
+
+	user, err := stores.Users.FindByUsername(ctx, "maria")
+	if err != nil {
+		t.Fatalf("usuário do diretório não foi criado: %v", err)
+	}
+	if !isDirectoryUser(user) || user.PasswordHash != "" || user.Email != "maria@exemplo.local" {
+		t.Errorf("usuário criado = %+v, esperado vinculado ao diretório, sem senha local", user)
+	}
+
+	waitBackoff()
+	if _, err := service.Login(ctx, "maria", "senha-errada", testClient); !errors.Is(err, errInvalidCredentials) {
+		t.Errorf("Login com senha errada: %v, esperado %v", err, errInvalidCredentials)
+	}
+}
+
+func TestLoginWithDirectoryRequiresGroup(t *testing.T) {
+	stores, _ := newTestStores(t)
+	service := newTestService(t, stores, testOptions{ldap: newTestLDAPConfig(t, true)})
+	ctx := context.Background()
+
+	if _, err := service.Login(ctx, "joao", "segredo-joao", testClient); !errors.Is(err, ErrDirectoryNoAccess) {
+		t.Fatalf("Login sem grupo mapeado: %v, esperado %v", err, ErrDirectoryNoAccess)
+	}
+	if _, err := stores.Users.FindByUsername(ctx, "joao"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("usuário sem acesso foi criado: %v", err)
+	}
+}
+
+func TestLoginWithDirectoryKeepsLocalUsers(t *testing.T) {
+	// Um usuário local com o mesmo username continua entrando com a senha local.
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-local", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{ldap: newTestLDAPConfig(t, false)})
+	ctx := context.Background()
+
+	if _, err := service.Login(ctx, "maria", "senha-local", testClient); err != nil {
+		t.Fatalf("Login local: %v", err)
+	}
+	if _, err := service.Login(ctx, "maria", "segredo-maria", testClient); !errors.Is(err, errInvalidCredentials) {
+		t.Errorf("Login com a senha do diretório: %v, esperado %v", err, errInvalidCredentials)
+	}
+}
+
+func TestLDAPGroupRolesMatchDNOrBareCN(t *testing.T) {
+	path := writeLDAPConfig(t, "ldap://127.0.0.1:389", map[string][]string{
+		"Fiscal": {"analise-icms"},
+		"cn=Financeiro,ou=Grupos,dc=exemplo,dc=local": {"converter-francesinha"},
+		"cn=Admins,ou=Grupos,dc=exemplo,dc=local":     {RoleAdmin},
+	}, false)
+	config, err := LoadLDAPConfig(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directory := newLDAPDirectory(*config)
+
+	tests := []struct {
+		name  string
+		group string
+		want  []string
+	}{
+		{"CN configurado, em qualquer OU", "cn=Fiscal,ou=Filial,dc=exemplo,dc=local", []string{"analise-icms"}},
+		{"DN configurado, sem diferenciar maiúsculas", "CN=Financeiro,OU=Grupos,DC=exemplo,DC=local", []string{"converter-francesinha"}},
+		{"DN configurado com o CN em outra OU", "cn=Financeiro,ou=Filial,dc=exemplo,dc=local", nil},
+		{"grupo admin em outra OU", "cn=Admins,ou=Filial,dc=exemplo,dc=local", nil},
+		{"CN igual ao texto de um DN configurado", `cn=cn\=Admins\,ou\=Grupos\,dc\=exemplo\,dc\=local,ou=Filial,dc=exemplo,dc=local`, nil},
+		{"DN do grupo admin", "cn=Admins,ou=Grupos,dc=exemplo,dc=local", []string{RoleAdmin}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, mapped := directory.roles([]string{tt.group})
+			if !slices.Equal(roles, tt.want) && !(len(roles) == 0 && len(tt.want) == 0) {
+				t.Errorf("roles(%q) = %v, esperado %v", tt.group, roles, tt.want)
+			}
+			if mapped != (len(tt.want) > 0) {
+				t.Errorf("roles(%q) mapeado = %v", tt.group, mapped)
+			}
+		})
+	}
+}
+
+func TestLoadLDAPConfigRejectsAdminByBareCN(t *testing.T) {
+	roleGroups := RoleGroups{"supervisor": {RoleAdmin, "analise-icms"}}
+	for _, roles := range [][]string{{RoleAdmin}, {"supervisor"}} {
+		path := writeLDAPConfig(t, "ldap://127.0.0.1:389", map[string][]string{"Admins": roles}, false)
+		if _, err := LoadLDAPConfig(path, roleGroups); err == nil {
+			t.Errorf("LoadLDAPConfig aceitou %v pelo CN", roles)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// internal/core/auth/login_attempt_store.go
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttempts acumula as falhas de login de uma chave (username ou IP de origem) e as
+// tentativas ainda em andamento.
+type LoginAttempts struct {
+	Key         string    `firestore:"key" json:"key"`
+	Failures    int       `firestore:"failures" json:"failures"`
+	LastFailure time.Time `firestore:"lastFailure" json:"lastFailure"`
+	LockedUntil time.Time `firestore:"lockedUntil" json:"lockedUntil"`
+	// Pending conta as tentativas reservadas cuja senha ainda está sendo conferida.
+	Pending     int       `firestore:"pending" json:"pending"`
+	LastAttempt time.Time `firestore:"lastAttempt" json:"lastAttempt"`
+}
+
+// LoginAttemptStore abstrai o armazenamento das tentativas de login.
+type LoginAttemptStore interface {
+	// Get retorna as tentativas da chave, ou um registro zerado se não houver nenhuma.
+	Get(ctx context.Context, key string) (*LoginAttempts, error)
+	// Update aplica a alteração de forma atômica e grava o resultado. Se change retornar
+	// erro, nada é gravado e o erro é devolvido.
+	Update(ctx context.Context, key string, change func(attempts *LoginAttempts) error) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,56 @@
+// internal/core/auth/memory_audit_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryAuditStoreCapacity limita o histórico em memória; os eventos mais antigos são descartados.
+const memoryAuditStoreCapacity = 10000
+
+// memoryAuditStore guarda o histórico de auditoria em memória.
+type memoryAuditStore struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+// NewMemoryAuditStore cria um armazenamento de auditoria em memória.
+func NewMemoryAuditStore() AuditStore {
+	return &memoryAuditStore{}
+}
+
+func (s *memoryAuditStore) Record(ctx context.Context, event *AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.events) >= memoryAuditStoreCapacity {
+		s.events = s.events[1:]
+	}
+	s.events = append(s.events, *event)
+	return nil
+}
+
+func (s *memoryAuditStore) Query(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]AuditEvent, 0)
+	for i := len(s.events) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		event := s.events[i]
+		if filter.Username != "" && event.Username != filter.Username {
+			continue
+		}
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if !filter.From.IsZero() && event.Timestamp.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !event.Timestamp.Before(filter.To) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,80 @@
+// internal/core/auth/memory_login_attempt_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryLoginAttemptPruneInterval é o intervalo mínimo entre as limpezas dos registros antigos.
+const memoryLoginAttemptPruneInterval = 10 * time.Minute
+
+// memoryLoginAttemptStore guarda as tentativas de login em memória.
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]LoginAttempts
+	// retention é por quanto tempo um registro sem novas tentativas é mantido.
+	retention time.Duration
+	// nextPrune é quando o próximo Update percorre o mapa em busca de registros antigos.
+	nextPrune time.Time
+}
+
+// NewMemoryLoginAttemptStore cria um armazenamento de tentativas de login em memória.
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]LoginAttempts), retention: 24 * time.Hour}
+}
+
+func (s *memoryLoginAttemptStore) Get(ctx context.Context, key string) (*LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		return &LoginAttempts{Key: key}, nil
+	}
+	return &attempts, nil
+}
+
+func (s *memoryLoginAttemptStore) Update(ctx context.Context, key string, change func(attempts *LoginAttempts) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		attempts = LoginAttempts{Key: key}
+	}
+	if err := change(&attempts); err != nil {
+		return err
+	}
+	s.attempts[key] = attempts
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune remove os registros sem tentativas há mais de retention e fora de bloqueio. O mapa
+// só é percorrido a cada memoryLoginAttemptPruneInterval, e não a cada falha.
+func (s *memoryLoginAttemptStore) prune(now time.Time) {
+	if now.Before(s.nextPrune) {
+		return
+	}
+	s.nextPrune = now.Add(memoryLoginAttemptPruneInterval)
+	for k, a := range s.attempts {
+		last := a.LastFailure
+		if a.LastAttempt.After(last) {
+			last = a.LastAttempt
+		}
+		if now.Sub(last) > s.retention && now.After(a.LockedUntil) {
+			delete(s.attempts, k)
+		}
+	}
+}
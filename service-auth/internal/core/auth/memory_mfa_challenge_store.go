@@ -0,0 +1,55 @@
+// internal/core/auth/memory_mfa_challenge_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryMFAChallengeStore guarda os desafios de segundo fator em memória.
+type memoryMFAChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]MFAChallenge
+}
+
+// NewMemoryMFAChallengeStore cria um armazenamento de desafios de segundo fator em memória.
+func NewMemoryMFAChallengeStore() MFAChallengeStore {
+	return &memoryMFAChallengeStore{challenges: make(map[string]MFAChallenge)}
+}
+
+func (s *memoryMFAChallengeStore) Save(ctx context.Context, challenge *MFAChallenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, c := range s.challenges {
+		if now.After(c.ExpiresAt) {
+			delete(s.challenges, hash)
+		}
+	}
+	s.challenges[challenge.TokenHash] = *challenge
+	return nil
+}
+
+func (s *memoryMFAChallengeStore) Find(ctx context.Context, tokenHash string) (*MFAChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, ok := s.challenges[tokenHash]
+	if !ok {
+		return nil, ErrMFAChallengeNotFound
+	}
+	return &challenge, nil
+}
+
+func (s *memoryMFAChallengeStore) Consume(ctx context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.challenges[tokenHash]; !ok {
+		return ErrMFAChallengeNotFound
+	}
+	delete(s.challenges, tokenHash)
+	return nil
+}
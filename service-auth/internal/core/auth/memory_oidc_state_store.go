@@ -0,0 +1,45 @@
+// internal/core/auth/memory_oidc_state_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryOIDCStateStore guarda os states de login OIDC em memória.
+type memoryOIDCStateStore struct {
+	mu     sync.Mutex
+	states map[string]OIDCState
+}
+
+// NewMemoryOIDCStateStore cria um armazenamento de states OIDC em memória.
+func NewMemoryOIDCStateStore() OIDCStateStore {
+	return &memoryOIDCStateStore{states: make(map[string]OIDCState)}
+}
+
+func (s *memoryOIDCStateStore) Save(ctx context.Context, state *OIDCState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, st := range s.states {
+		if now.After(st.ExpiresAt) {
+			delete(s.states, hash)
+		}
+	}
+	s.states[state.StateHash] = *state
+	return nil
+}
+
+func (s *memoryOIDCStateStore) Consume(ctx context.Context, stateHash string) (*OIDCState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[stateHash]
+	if !ok {
+		return nil, ErrOIDCStateNotFound
+	}
+	delete(s.states, stateHash)
+	return &state, nil
+}
@@ -0,0 +1,45 @@
+// internal/core/auth/memory_password_reset_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryPasswordResetStore guarda os tokens de redefinição em memória.
+type memoryPasswordResetStore struct {
+	mu     sync.Mutex
+	tokens map[string]PasswordResetToken
+}
+
+// NewMemoryPasswordResetStore cria um armazenamento de tokens de redefinição em memória.
+func NewMemoryPasswordResetStore() PasswordResetStore {
+	return &memoryPasswordResetStore{tokens: make(map[string]PasswordResetToken)}
+}
+
+func (s *memoryPasswordResetStore) Save(ctx context.Context, token *PasswordResetToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, t := range s.tokens {
+		if now.After(t.ExpiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+	s.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (s *memoryPasswordResetStore) Consume(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return nil, ErrResetTokenNotFound
+	}
+	delete(s.tokens, tokenHash)
+	return &token, nil
+}
@@ -0,0 +1,74 @@
+// internal/core/auth/memory_refresh_token_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryRefreshTokenStore guarda os refresh tokens em memória. Os tokens se perdem ao reiniciar
+// o serviço, o que é aceitável para desenvolvimento local.
+type memoryRefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]RefreshToken
+}
+
+// NewMemoryRefreshTokenStore cria um armazenamento de refresh tokens em memória.
+func NewMemoryRefreshTokenStore() RefreshTokenStore {
+	return &memoryRefreshTokenStore{tokens: make(map[string]RefreshToken)}
+}
+
+func (s *memoryRefreshTokenStore) Save(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, t := range s.tokens {
+		if now.After(t.ExpiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+	s.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (s *memoryRefreshTokenStore) Find(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+	return &token, nil
+}
+
+func (s *memoryRefreshTokenStore) MarkUsed(ctx context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return ErrRefreshTokenNotFound
+	}
+	if token.Used {
+		return ErrRefreshTokenReused
+	}
+	token.Used = true
+	s.tokens[tokenHash] = token
+	return nil
+}
+
+func (s *memoryRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, token := range s.tokens {
+		if token.FamilyID == familyID {
+			token.Revoked = true
+			s.tokens[hash] = token
+		}
+	}
+	return nil
+}
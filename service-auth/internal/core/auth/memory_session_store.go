@@ -0,0 +1,64 @@
+// internal/core/auth/memory_session_store.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySessionStore guarda as sessões em memória.
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewMemorySessionStore cria um armazenamento de sessões em memória.
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStore{sessions: make(map[string]Session)}
+}
+
+func (s *memorySessionStore) Update(ctx context.Context, id string, change func(session *Session) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for sid, sess := range s.sessions {
+		if now.After(sess.ExpiresAt) {
+			delete(s.sessions, sid)
+		}
+	}
+	session, ok := s.sessions[id]
+	if !ok {
+		session = Session{ID: id}
+	}
+	if err := change(&session); err != nil {
+		return err
+	}
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *memorySessionStore) Find(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (s *memorySessionStore) ListByUser(ctx context.Context, username string) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sessions []Session
+	for _, session := range s.sessions {
+		if session.Username == username {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,227 @@
+// internal/core/auth/mfa.go
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidOTP é retornado quando o código TOTP ou de recuperação não confere.
+	ErrInvalidOTP = errors.New("código de verificação inválido")
+	// ErrMFAAlreadyEnabled é retornado ao iniciar o cadastro de um usuário que já usa 2FA.
+	ErrMFAAlreadyEnabled = errors.New("a autenticação em dois fatores já está ativa")
+	// ErrMFANotEnrolled é retornado quando a operação exige um cadastro TOTP iniciado ou ativo.
+	ErrMFANotEnrolled = errors.New("a autenticação em dois fatores não está cadastrada")
+)
+
+const (
+	defaultTOTPIssuer = "Services With Gateway"
+	recoveryCodeCount = 10
+)
+
+// MFAConfig configura o cadastro TOTP.
+type MFAConfig struct {
+	// Issuer é o nome exibido pelo aplicativo autenticador.
+	Issuer string
+}
+
+// TOTPEnrollment contém os dados para cadastrar o segredo no aplicativo autenticador.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// ProvisioningURI é a URI otpauth:// a ser exibida como QR code.
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAService define o cadastro e a remoção do segundo fator pelo próprio usuário. As
+// operações que alteram o cadastro exigem a senha atual, conferida como no login.
+type MFAService interface {
+	// EnrollTOTP gera um novo segredo pendente; o 2FA só passa a valer após ConfirmTOTP.
+	EnrollTOTP(ctx context.Context, username, password string) (*TOTPEnrollment, error)
+	// ConfirmTOTP ativa o 2FA com o primeiro código gerado e retorna os códigos de recuperação.
+	ConfirmTOTP(ctx context.Context, username, password, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, username, password, code string) error
+	// RegenerateRecoveryCodes invalida os códigos de recuperação anteriores e gera novos.
+	RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error)
+}
+
+// PasswordVerifier confere a senha de um usuário autenticado; Service o implementa com a
+// mesma conferência do login (diretório LDAP ou hash local).
+type PasswordVerifier interface {
+	VerifyPassword(ctx context.Context, username, password string) error
+}
+
+type mfaService struct {
+	users     UserRepository
+	passwords PasswordVerifier
+	audit     auditor
+	config    MFAConfig
+}
+
+// NewMFAService cria o serviço de cadastro do segundo fator.
+func NewMFAService(users UserRepository, passwords PasswordVerifier, audit AuditStore, config MFAConfig) MFAService {
+	if config.Issuer == "" {
+		config.Issuer = defaultTOTPIssuer
+	}
+	return &mfaService{users: users, passwords: passwords, audit: auditor{store: audit}, config: config}
+}
+
+func (s *mfaService) EnrollTOTP(ctx context.Context, username, password string) (*TOTPEnrollment, error) {
+	if err := s.passwords.VerifyPassword(ctx, username, password); err != nil {
+		return nil, err
+	}
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.Modify(ctx, username, func(user *User) error {
+		if user.TOTPEnabled {
+			return ErrMFAAlreadyEnabled
+		}
+		user.TOTPSecret = secret
+		user.TOTPLastStep = 0
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(s.config.Issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *mfaService) ConfirmTOTP(ctx context.Context, username, password, code string) ([]string, error) {
+	if err := s.passwords.VerifyPassword(ctx, username, password); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	_, err = s.users.Modify(ctx, username, func(user *User) error {
+		if user.TOTPEnabled {
+			return ErrMFAAlreadyEnabled
+		}
+		if user.TOTPSecret == "" {
+			return ErrMFANotEnrolled
+		}
+		step, ok := matchTOTP(user.TOTPSecret, normalizeOTP(code), now, user.TOTPLastStep)
+		if !ok {
+			return ErrInvalidOTP
+		}
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+		user.RecoveryCodes = hashes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditMFAEnable, username, true, "")
+	return codes, nil
+}
+
+func (s *mfaService) DisableTOTP(ctx context.Context, username, password, code string) error {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrMFANotEnrolled
+	}
+	if err := s.passwords.VerifyPassword(ctx, username, password); err != nil {
+		return err
+	}
+	now := time.Now()
+	_, err = s.users.Modify(ctx, username, func(user *User) error {
+		if !user.TOTPEnabled {
+			return ErrMFANotEnrolled
+		}
+		if !verifySecondFactor(user, code, now) {
+			return ErrInvalidOTP
+		}
+		clearTOTP(user)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditMFADisable, username, true, "")
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	_, err = s.users.Modify(ctx, username, func(user *User) error {
+		if !user.TOTPEnabled {
+			return ErrMFANotEnrolled
+		}
+		if !verifySecondFactor(user, code, now) {
+			return ErrInvalidOTP
+		}
+		user.RecoveryCodes = hashes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifySecondFactor aceita um código TOTP ainda não usado ou um código de recuperação,
+// que é descartado. Altera o usuário; deve ser chamado dentro de UserRepository.Modify, para
+// que o mesmo código não seja aceito por duas requisições simultâneas.
+func verifySecondFactor(user *User, code string, now time.Time) bool {
+	code = normalizeOTP(code)
+	if step, ok := matchTOTP(user.TOTPSecret, code, now, user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return true
+	}
+
+	hash := hashToken(strings.ToLower(code))
+	for i, stored := range user.RecoveryCodes {
+		if stored == hash {
+			user.RecoveryCodes = append(user.RecoveryCodes[:i:i], user.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// clearTOTP remove o segredo e os códigos de recuperação do usuário.
+func clearTOTP(user *User) {
+	user.TOTPSecret = ""
+	user.TOTPEnabled = false
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = nil
+}
+
+// normalizeOTP remove espaços e hífens, aceitando códigos digitados em grupos.
+func normalizeOTP(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
+
+// newRecoveryCodes gera os códigos de recuperação no formato xxxxx-xxxxx e seus hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,28 @@
+// internal/core/auth/mfa_challenge_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMFAChallengeNotFound é retornado quando o desafio não existe ou já foi concluído.
+var ErrMFAChallengeNotFound = errors.New("desafio de autenticação não encontrado")
+
+// MFAChallenge é emitido após a senha correta de um usuário com 2FA e trocado, junto com
+// o código TOTP, pelo par de tokens. Apenas o hash SHA-256 do token é armazenado.
+type MFAChallenge struct {
+	TokenHash string    `firestore:"tokenHash" json:"tokenHash"`
+	Username  string    `firestore:"username" json:"username"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time `firestore:"expiresAt" json:"expiresAt"`
+}
+
+// MFAChallengeStore abstrai o armazenamento dos desafios de segundo fator.
+type MFAChallengeStore interface {
+	Save(ctx context.Context, challenge *MFAChallenge) error
+	Find(ctx context.Context, tokenHash string) (*MFAChallenge, error)
+	// Consume remove o desafio de forma atômica, garantindo que seja concluído uma única vez.
+	Consume(ctx context.Context, tokenHash string) error
+}
@@ -0,0 +1,323 @@
+// internal/core/auth/oidc.go
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrOIDCProviderNotFound é retornado para um provedor de identidade não configurado.
+	ErrOIDCProviderNotFound = errors.New("provedor de identidade não encontrado")
+	// ErrInvalidOIDCState é retornado quando o state do callback é desconhecido, já foi usado ou expirou.
+	ErrInvalidOIDCState = errors.New("login externo inválido ou expirado: inicie o login novamente")
+	// ErrOIDCLoginFailed é retornado quando a comunicação com o provedor ou a validação do
+	// ID token falha; o motivo fica apenas no log.
+	ErrOIDCLoginFailed = errors.New("falha na autenticação com o provedor de identidade")
+	// ErrOIDCUserNotLinked é retornado quando a identidade externa não corresponde a um usuário local.
+	ErrOIDCUserNotLinked = errors.New("nenhum usuário vinculado a esta identidade externa")
+	// ErrOIDCDomainNotAllowed é retornado quando o e-mail da identidade não pertence aos
+	// domínios aceitos pelo provedor.
+	ErrOIDCDomainNotAllowed = errors.New("o domínio do e-mail não é aceito para este provedor")
+)
+
+const (
+	defaultOIDCStateTTL    = 10 * time.Minute
+	defaultOIDCHTTPTimeout = 10 * time.Second
+)
+
+var oidcProviderNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// OIDCProviderConfig configura um provedor de identidade externo (Google, Microsoft etc.).
+type OIDCProviderConfig struct {
+	// Name identifica o provedor nas rotas (/oidc/<name>/...) e nas identidades vinculadas.
+	Name   string `json:"name"`
+	Issuer string `json:"issuer"`
+	// ClientID e ClientSecret são as credenciais do cliente registrado no provedor. Com
+	// ClientSecretEnv o segredo é lido da variável de ambiente informada.
+	ClientID        string `json:"client_id"`
+	ClientSecret    string `json:"client_secret"`
+	ClientSecretEnv string `json:"client_secret_env"`
+	// RedirectURL é a URL de callback registrada no provedor.
+	RedirectURL string   `json:"redirect_url"`
+	Scopes      []string `json:"scopes"`
+	// LinkByEmail vincula, no primeiro login, a identidade ao usuário local com o mesmo
+	// e-mail, desde que o provedor informe o e-mail como verificado.
+	LinkByEmail bool `json:"link_by_email"`
+	// AutoCreate cria um usuário local (username = e-mail verificado) com DefaultRoles quando
+	// a identidade não está vinculada.
+	AutoCreate   bool     `json:"auto_create"`
+	DefaultRoles []string `json:"default_roles"`
+	// AllowedDomains restringe o login a e-mails verificados desses domínios.
+	AllowedDomains []string `json:"allowed_domains"`
+}
+
+// OIDCConfig configura o login com provedores de identidade externos. Sem provedores, o
+// login externo fica desativado.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// StateTTL é o prazo para o usuário concluir o login no provedor.
+	StateTTL time.Duration
+	// HTTPClient é usado nas chamadas ao provedor; nil usa um cliente com timeout de 10s.
+	HTTPClient *http.Client
+}
+
+// LoadOIDCProviders lê os provedores de identidade de um arquivo JSON (lista de
+// OIDCProviderConfig) e valida a configuração. As roles padrão são validadas contra as
+// roles conhecidas e os grupos de roleGroups.
+func LoadOIDCProviders(path string, roleGroups RoleGroups) ([]OIDCProviderConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de provedores OIDC: %w", err)
+	}
+	var providers []OIDCProviderConfig
+	if err := json.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("arquivo de provedores OIDC inválido: %w", err)
+	}
+
+	seen := make(map[string]bool, len(providers))
+	for i := range providers {
+		provider := &providers[i]
+		if !oidcProviderNamePattern.MatchString(provider.Name) {
+			return nil, fmt.Errorf("nome de provedor OIDC inválido: %q", provider.Name)
+		}
+		if seen[provider.Name] {
+			return nil, fmt.Errorf("provedor OIDC duplicado: %s", provider.Name)
+		}
+		seen[provider.Name] = true
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return nil, fmt.Errorf("provedor OIDC %s: issuer, client_id e redirect_url são obrigatórios", provider.Name)
+		}
+		if provider.ClientSecretEnv != "" {
+			provider.ClientSecret = os.Getenv(provider.ClientSecretEnv)
+			if provider.ClientSecret == "" {
+				return nil, fmt.Errorf("provedor OIDC %s: variável %s vazia", provider.Name, provider.ClientSecretEnv)
+			}
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+		roles, err := roleGroups.normalize(provider.DefaultRoles)
+		if err != nil {
+			return nil, fmt.Errorf("provedor OIDC %s: %w", provider.Name, err)
+		}
+		provider.DefaultRoles = roles
+		for j, domain := range provider.AllowedDomains {
+			provider.AllowedDomains[j] = strings.ToLower(strings.TrimPrefix(domain, "@"))
+		}
+	}
+	return providers, nil
+}
+
+func (s *service) OIDCProviders() []string {
+	return s.oidcNames
+}
+
+func (s *service) StartOIDC(ctx context.Context, providerName, loginHint string) (string, string, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return "", "", ErrOIDCProviderNotFound
+	}
+
+	var secrets [4]string
+	for i := range secrets {
+		secret, err := randomToken(32)
+		if err != nil {
+			return "", "", errors.New("erro ao iniciar login externo")
+		}
+		secrets[i] = secret
+	}
+	state, nonce, codeVerifier, binding := secrets[0], secrets[1], secrets[2], secrets[3]
+
+	authorizationURL, err := provider.authorizationURL(ctx, state, nonce, codeVerifier, loginHint)
+	if err != nil {
+		log.Printf("Erro ao consultar o provedor OIDC %s: %v", providerName, err)
+		return "", "", ErrOIDCLoginFailed
+	}
+	now := time.Now()
+	err = s.oidcStates.Save(ctx, &OIDCState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		BindingHash:  hashToken(binding),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.oidcStateTTL),
+	})
+	if err != nil {
+		log.Printf("Erro ao salvar state OIDC: %v", err)
+		return "", "", errors.New("erro ao iniciar login externo")
+	}
+	return authorizationURL, binding, nil
+}
+
+func (s *service) CompleteOIDC(ctx context.Context, providerName, code, state, binding string, client ClientInfo) (*LoginResult, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+	detail := "oidc:" + providerName
+
+	// 1. Consumir o state: cada login externo só pode ser concluído uma vez.
+	stored, err := s.oidcStates.Consume(ctx, hashToken(state))
+	if errors.Is(err, ErrOIDCStateNotFound) {
+		return nil, ErrInvalidOIDCState
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar state OIDC: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if stored.Provider != providerName || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+	// O state só vale no navegador que iniciou o login: um callback com o state de outra
+	// pessoa (login CSRF) chega sem o cookie correspondente.
+	if subtle.ConstantTimeCompare([]byte(hashToken(binding)), []byte(stored.BindingHash)) != 1 {
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, "", false, detail+": state de outro navegador")
+		return nil, ErrInvalidOIDCState
+	}
+
+	// 2. Trocar o código (com o code_verifier do PKCE) e validar o ID token.
+	identity, err := provider.authenticate(ctx, code, stored)
+	if err != nil {
+		log.Printf("Erro no login OIDC com %s: %v", providerName, err)
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, "", false, detail+": falha no provedor")
+		return nil, ErrOIDCLoginFailed
+	}
+
+	// 3. Mapear a identidade para o usuário local.
+	user, err := s.oidcUser(ctx, provider, identity)
+	if errors.Is(err, ErrOIDCUserNotLinked) || errors.Is(err, ErrOIDCDomainNotAllowed) {
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, identity.ExternalID, false, detail+": "+err.Error())
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Erro ao mapear identidade %s: %v", identity.ExternalID, err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if user.Disabled {
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, user.Username, false, detail+": usuário desativado")
+		return nil, ErrUserDisabled
+	}
+
+	// 4. Daqui em diante o login segue como o de senha: 2FA local, se ativo, e emissão dos tokens.
+	if user.TOTPEnabled {
+		challenge, err := s.issueChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		s.audit.recordFrom(ctx, client, AuditMFAChallenge, user.Username, true, detail)
+		return &LoginResult{Challenge: challenge}, nil
+	}
+	tokens, err := s.startSession(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	s.audit.recordFrom(ctx, client, AuditLoginSuccess, user.Username, true, detail)
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// oidcUser localiza o usuário vinculado à identidade externa. Sem vínculo, conforme a
+// configuração do provedor, vincula pelo e-mail verificado ou cria um novo usuário.
+func (s *service) oidcUser(ctx context.Context, provider *oidcProvider, identity *oidcIdentity) (*User, error) {
+	config := provider.config
+	if len(config.AllowedDomains) > 0 && !emailInDomains(identity.Email, config.AllowedDomains) {
+		return nil, ErrOIDCDomainNotAllowed
+	}
+
+	user, err := s.users.FindByExternalID(ctx, identity.ExternalID)
+	if err == nil || !errors.Is(err, ErrUserNotFound) {
+		return user, err
+	}
+	if identity.Email == "" {
+		return nil, ErrOIDCUserNotLinked
+	}
+
+	if config.LinkByEmail {
+		user, err := s.linkOIDCIdentity(ctx, identity)
+		if err == nil || !errors.Is(err, ErrUserNotFound) {
+			return user, err
+		}
+	}
+	if config.AutoCreate {
+		return s.createOIDCUser(ctx, config, identity)
+	}
+	return nil, ErrOIDCUserNotLinked
+}
+
+// linkOIDCIdentity vincula a identidade ao único usuário local com o mesmo e-mail.
+// Retorna ErrUserNotFound se nenhum usuário (ou mais de um) tiver o e-mail.
+func (s *service) linkOIDCIdentity(ctx context.Context, identity *oidcIdentity) (*User, error) {
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var match *User
+	for i := range users {
+		if !strings.EqualFold(users[i].Email, identity.Email) {
+			continue
+		}
+		if match != nil {
+			log.Printf("E-mail %s pertence a mais de um usuário; identidade %s não vinculada", identity.Email, identity.ExternalID)
+			return nil, ErrUserNotFound
+		}
+		match = &users[i]
+	}
+	if match == nil {
+		return nil, ErrUserNotFound
+	}
+
+	match.ExternalIDs = append(match.ExternalIDs, identity.ExternalID)
+	if err := s.users.Update(ctx, match); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditUserUpdate, match.Username, true, "identidade "+identity.ExternalID+" vinculada pelo e-mail")
+	return match, nil
+}
+
+// createOIDCUser cria o usuário de uma identidade externa, sem senha: o hash vazio nunca
+// confere, e o usuário só entra pelo provedor até definir uma senha pela redefinição.
+func (s *service) createOIDCUser(ctx context.Context, config OIDCProviderConfig, identity *oidcIdentity) (*User, error) {
+	if !usernamePattern.MatchString(identity.Email) {
+		log.Printf("E-mail %s não é um username válido; identidade %s não criada", identity.Email, identity.ExternalID)
+		return nil, ErrOIDCUserNotLinked
+	}
+	user := &User{
+		Username:    identity.Email,
+		Roles:       append([]string(nil), config.DefaultRoles...),
+		Email:       identity.Email,
+		ExternalIDs: []string{identity.ExternalID},
+	}
+	err := s.users.Create(ctx, user)
+	if errors.Is(err, ErrUserExists) {
+		return nil, ErrOIDCUserNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditUserCreate, user.Username, true, "criado no login oidc:"+config.Name+", roles: "+formatRoles(user.Roles))
+	return user, nil
+}
+
+// emailInDomains informa se o e-mail pertence a um dos domínios.
+func emailInDomains(email string, domains []string) bool {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return false
+	}
+	for _, allowed := range domains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
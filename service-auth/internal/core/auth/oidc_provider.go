@@ -0,0 +1,342 @@
+// internal/core/auth/oidc_provider.go
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcKeysRefreshInterval limita a frequência com que um kid desconhecido provoca uma
+	// nova leitura do JWKS do provedor.
+	oidcKeysRefreshInterval = time.Minute
+	// oidcClockSkew é a tolerância de relógio aceita nos claims de tempo do ID token.
+	oidcClockSkew = time.Minute
+	// oidcMaxResponseSize limita as respostas lidas do provedor.
+	oidcMaxResponseSize = 1 << 20
+)
+
+// oidcMetadata são os campos usados do documento de descoberta do provedor.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcIDToken são os claims lidos do ID token.
+type oidcIDToken struct {
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	Email           string `json:"email"`
+	// EmailVerified é booleano na especificação, mas alguns provedores o enviam como string.
+	EmailVerified any `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+// verifiedEmail retorna o e-mail em minúsculas, ou vazio se o provedor não o confirmou.
+func (t *oidcIDToken) verifiedEmail() string {
+	verified := false
+	switch v := t.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+	if !verified {
+		return ""
+	}
+	return strings.ToLower(t.Email)
+}
+
+// oidcIdentity é a identidade autenticada pelo provedor.
+type oidcIdentity struct {
+	// ExternalID é "<provedor>:<sub>", o valor gravado em User.ExternalIDs.
+	ExternalID string
+	// Email é o e-mail verificado pelo provedor, ou vazio.
+	Email string
+}
+
+// oidcProvider fala com um provedor OIDC: descoberta, JWKS, troca do código e validação do
+// ID token. Os metadados e as chaves ficam em cache.
+type oidcProvider struct {
+	config OIDCProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+func newOIDCProvider(config OIDCProviderConfig, client *http.Client) *oidcProvider {
+	return &oidcProvider{config: config, client: client}
+}
+
+// discover retorna os metadados do provedor, lidos na primeira chamada.
+func (p *oidcProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var metadata oidcMetadata
+	discoveryURL := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("issuer da descoberta (%s) difere do configurado (%s)", metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("documento de descoberta incompleto")
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// authorizationURL monta a URL do endpoint de autorização com o desafio PKCE (S256).
+func (p *oidcProvider) authorizationURL(ctx context.Context, state, nonce, codeVerifier, loginHint string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("authorization_endpoint inválido: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", pkceChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	if loginHint != "" {
+		query.Set("login_hint", loginHint)
+	}
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// authenticate troca o código de autorização pelo ID token e o valida.
+func (p *oidcProvider) authenticate(ctx context.Context, code string, state *OIDCState) (*oidcIdentity, error) {
+	rawIDToken, err := p.exchange(ctx, code, state.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := p.verifyIDToken(ctx, rawIDToken, state.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &oidcIdentity{
+		ExternalID: p.config.Name + ":" + idToken.Subject,
+		Email:      idToken.verifiedEmail(),
+	}, nil
+}
+
+// exchange chama o token endpoint (client_secret_post) e retorna o ID token.
+func (p *oidcProvider) exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oidcMaxResponseSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("resposta do token endpoint inválida (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint respondeu %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token endpoint não retornou id_token")
+	}
+	return body.IDToken, nil
+}
+
+// verifyIDToken valida a assinatura, o issuer, a audiência, a validade e o nonce do ID token.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (*oidcIDToken, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oidcIDToken{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token inválido: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id_token sem claim sub")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, errors.New("id_token com várias audiências e azp diferente do client_id")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("nonce do id_token não confere")
+	}
+	return claims, nil
+}
+
+// publicKey retorna a chave RSA do provedor com o kid informado, relendo o JWKS quando o kid
+// é desconhecido (rotação de chaves do provedor). Sem kid, aceita apenas JWKS de chave única.
+func (p *oidcProvider) publicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < oidcKeysRefreshInterval {
+		return nil, fmt.Errorf("chave %q não encontrada no JWKS do provedor", kid)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAJWK(jwk.N, jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("chave %q do JWKS inválida: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("chave %q não encontrada no JWKS do provedor", kid)
+}
+
+// lookupKey procura a chave em cache; deve ser chamado com p.mu travado.
+func (p *oidcProvider) lookupKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s respondeu %d", target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oidcMaxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("resposta inválida de %s: %w", target, err)
+	}
+	return nil
+}
+
+// parseRSAJWK monta a chave pública a partir do módulo e do expoente em base64url.
+func parseRSAJWK(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("módulo ou expoente inválido")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+// pkceChallenge calcula o code_challenge S256 (RFC 7636) do code_verifier.
+func pkceChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+// internal/core/auth/oidc_state_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrOIDCStateNotFound é retornado quando o state não existe ou já foi usado.
+var ErrOIDCStateNotFound = errors.New("state OIDC não encontrado")
+
+// OIDCState guarda, entre o redirecionamento ao provedor e o callback, o nonce e o
+// code_verifier (PKCE) de um login OIDC. Apenas o hash SHA-256 do state é armazenado.
+type OIDCState struct {
+	StateHash    string `firestore:"stateHash" json:"stateHash"`
+	Provider     string `firestore:"provider" json:"provider"`
+	Nonce        string `firestore:"nonce" json:"nonce"`
+	CodeVerifier string `firestore:"codeVerifier" json:"codeVerifier"`
+	// BindingHash é o hash do valor guardado no cookie do navegador que iniciou o login;
+	// o callback só é aceito no mesmo navegador (proteção contra login CSRF).
+	BindingHash string    `firestore:"bindingHash" json:"bindingHash"`
+	CreatedAt   time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt   time.Time `firestore:"expiresAt" json:"expiresAt"`
+}
+
+// OIDCStateStore abstrai o armazenamento dos states de login OIDC.
+type OIDCStateStore interface {
+	Save(ctx context.Context, state *OIDCState) error
+	// Consume remove o state de forma atômica e o retorna, garantindo o uso único.
+	Consume(ctx context.Context, stateHash string) (*OIDCState, error)
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"auth-service/internal/oidctest"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestOIDCConfig sobe o provedor de teste e o configura como "mock", com vínculo pelo e-mail.
+func newTestOIDCConfig(t *testing.T) OIDCConfig {
+	t.Helper()
+	server, err := oidctest.Start("127.0.0.1:0", oidctest.Config{ClientID: "test-client", ClientSecret: "segredo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return OIDCConfig{Providers: []OIDCProviderConfig{{
+		Name:         "mock",
+		Issuer:       server.URL(),
+		ClientID:     "test-client",
+		ClientSecret: "segredo",
+		RedirectURL:  "http://localhost/oidc/mock/callback",
+		LinkByEmail:  true,
+	}}}
+}
+
+// authorizeOIDC segue a URL de autorização até o redirecionamento ao callback e retorna o
+// código e o state recebidos.
+func authorizeOIDC(t *testing.T, authorizationURL string) (code, state string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("autorização: status %d, Location %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	query := callback.Query()
+	if query.Get("error") != "" {
+		t.Fatalf("autorização recusada: %s", query.Get("error"))
+	}
+	return query.Get("code"), query.Get("state")
+}
+
+func TestOIDCLoginLinksUserByEmail(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{oidc: newTestOIDCConfig(t)})
+	ctx := context.Background()
+
+	authorizationURL, binding, err := service.StartOIDC(ctx, "mock", "maria@exemplo.local")
+	if err != nil {
+		t.Fatalf("StartOIDC: %v", err)
+	}
+	code, state := authorizeOIDC(t, authorizationURL)
+	result, err := service.CompleteOIDC(ctx, "mock", code, state, binding, testClient)
+	if err != nil {
+		t.Fatalf("CompleteOIDC: %v", err)
+	}
+	claims, err := service.ValidateAccessToken(ctx, result.Tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.Username != "maria" {
+		t.Errorf("username = %q, esperado maria", claims.Username)
+	}
+
+	user, err := stores.Users.FindByUsername(ctx, "maria")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(user.ExternalIDs) != 1 {
+		t.Fatalf("ExternalIDs = %v, esperada a identidade do provedor", user.ExternalIDs)
+	}
+	if _, err := service.CompleteOIDC(ctx, "mock", code, state, binding, testClient); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("state reutilizado: %v, esperado %v", err, ErrInvalidOIDCState)
+	}
+}
+
+func TestOIDCLoginRejectsStateFromAnotherBrowser(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{oidc: newTestOIDCConfig(t)})
+	ctx := context.Background()
+
+	// O atacante inicia o login com a própria conta e entrega o callback à vítima, cujo
+	// navegador não tem o cookie com o vínculo (login CSRF).
+	for _, other := range []string{"", "outro-navegador"} {
+		authorizationURL, binding, err := service.StartOIDC(ctx, "mock", "maria@exemplo.local")
+		if err != nil {
+			t.Fatalf("StartOIDC: %v", err)
+		}
+		code, state := authorizeOIDC(t, authorizationURL)
+		if _, err := service.CompleteOIDC(ctx, "mock", code, state, other, testClient); !errors.Is(err, ErrInvalidOIDCState) {
+			t.Errorf("CompleteOIDC com vínculo %q: %v, esperado %v", other, err, ErrInvalidOIDCState)
+		}
+		// A tentativa recusada consome o state: nem o navegador certo o reaproveita.
+		if _, err := service.CompleteOIDC(ctx, "mock", code, state, binding, testClient); !errors.Is(err, ErrInvalidOIDCState) {
+			t.Errorf("state após a recusa: %v, esperado %v", err, ErrInvalidOIDCState)
+		}
+	}
+}
+
+func TestOIDCLoginRejectsUnlinkedIdentity(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{oidc: newTestOIDCConfig(t)})
+	ctx := context.Background()
+
+	authorizationURL, binding, err := service.StartOIDC(ctx, "mock", "ana@exemplo.local")
+	if err != nil {
+		t.Fatalf("StartOIDC: %v", err)
+	}
+	code, state := authorizeOIDC(t, authorizationURL)
+	if _, err := service.CompleteOIDC(ctx, "mock", code, state, binding, testClient); !errors.Is(err, ErrOIDCUserNotLinked) {
+		t.Errorf("CompleteOIDC: %v, esperado %v", err, ErrOIDCUserNotLinked)
+	}
+}
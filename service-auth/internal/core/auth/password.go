@@ -0,0 +1,169 @@
+// internal/core/auth/password.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"auth-service/internal/core/notify"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrWrongPassword é retornado quando a senha atual informada não confere.
+	ErrWrongPassword = errors.New("senha atual incorreta")
+	// ErrInvalidResetToken é retornado quando o token de redefinição é inválido, expirado ou já usado.
+	ErrInvalidResetToken = errors.New("token de redefinição inválido ou expirado")
+)
+
+const defaultPasswordResetTTL = 30 * time.Minute
+
+// PasswordConfig configura o fluxo de redefinição de senha.
+type PasswordConfig struct {
+	ResetTTL time.Duration
+	// ResetURL, se preenchida, é usada para montar o link enviado ao usuário,
+	// acrescentando o token no parâmetro "token".
+	ResetURL string
+	// Policy é a política aplicada às novas senhas.
+	Policy PasswordPolicy
+}
+
+// PasswordService define a troca de senha pelo próprio usuário e o fluxo de redefinição.
+type PasswordService interface {
+	ChangePassword(ctx context.Context, username, currentPassword, newPassword string) error
+	// RequestReset gera um token de redefinição e o envia pelo Notifier. Não informa se o
+	// usuário existe, para não permitir enumeração de contas.
+	RequestReset(ctx context.Context, username string) error
+	ConfirmReset(ctx context.Context, token, newPassword string) error
+}
+
+type passwordService struct {
+	users    UserRepository
+	resets   PasswordResetStore
+	notifier notify.Notifier
+	audit    auditor
+	config   PasswordConfig
+}
+
+// NewPasswordService cria o serviço de senhas.
+func NewPasswordService(users UserRepository, resets PasswordResetStore, audit AuditStore, notifier notify.Notifier, config PasswordConfig) PasswordService {
+	if config.ResetTTL <= 0 {
+		config.ResetTTL = defaultPasswordResetTTL
+	}
+	config.Policy = config.Policy.withDefaults()
+	return &passwordService{users: users, resets: resets, notifier: notifier, audit: auditor{store: audit}, config: config}
+}
+
+func (s *passwordService) ChangePassword(ctx context.Context, username, currentPassword, newPassword string) error {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if isDirectoryUser(user) {
+		return ErrDirectoryPassword
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)) != nil {
+		s.audit.record(ctx, AuditPasswordChange, username, false, "senha atual incorreta")
+		return ErrWrongPassword
+	}
+
+	hash, err := s.config.Policy.hash(newPassword)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	if err := s.users.Update(ctx, user); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditPasswordChange, username, true, "")
+	return nil
+}
+
+func (s *passwordService) RequestReset(ctx context.Context, username string) error {
+	user, err := s.users.FindByUsername(ctx, username)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Disabled || user.Email == "" || isDirectoryUser(user) {
+		log.Printf("Redefinição de senha ignorada para %s: usuário desativado, sem e-mail ou do diretório LDAP", username)
+		return nil
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	reset := &PasswordResetToken{
+		TokenHash: hashToken(token),
+		Username:  user.Username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.ResetTTL),
+	}
+	if err := s.resets.Save(ctx, reset); err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha",
+		Body:    s.resetBody(user.Username, token, reset.ExpiresAt),
+	})
+}
+
+func (s *passwordService) ConfirmReset(ctx context.Context, token, newPassword string) error {
+	// Valida a senha antes de consumir o token, para que um erro de digitação não o invalide.
+	hash, err := s.config.Policy.hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	reset, err := s.resets.Consume(ctx, hashToken(token))
+	if errors.Is(err, ErrResetTokenNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	if time.Now().After(reset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.users.FindByUsername(ctx, reset.Username)
+	if errors.Is(err, ErrUserNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	if err := s.users.Update(ctx, user); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditPasswordReset, user.Username, true, "token de redefinição")
+	return nil
+}
+
+func (s *passwordService) resetBody(username, token string, expiresAt time.Time) string {
+	instructions := fmt.Sprintf("Use o token abaixo para redefinir a senha:\n\n%s", token)
+	if s.config.ResetURL != "" {
+		if link, err := url.Parse(s.config.ResetURL); err == nil {
+			query := link.Query()
+			query.Set("token", token)
+			link.RawQuery = query.Encode()
+			instructions = fmt.Sprintf("Acesse o link abaixo para redefinir a senha:\n\n%s", link.String())
+		}
+	}
+
+	return fmt.Sprintf("Olá, %s.\n\nRecebemos um pedido de redefinição de senha para a sua conta.\n%s\n\n"+
+		"O token expira em %s e só pode ser usado uma vez. Se você não fez o pedido, ignore esta mensagem.\n",
+		username, instructions, expiresAt.Format("02/01/2006 15:04"))
+}
@@ -0,0 +1,203 @@
+// internal/core/auth/password_policy.go
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptMaxPasswordLength é o limite do bcrypt: bytes além dele seriam ignorados.
+const bcryptMaxPasswordLength = 72
+
+// PasswordPolicyError é retornado quando uma nova senha não atende à política.
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "senha fraca: " + e.Reason
+}
+
+// PasswordPolicy define as regras aplicadas sempre que uma senha é definida e o custo
+// bcrypt dos hashes gravados. É passada aos serviços que gravam senhas; campos zerados
+// usam os padrões (8 caracteres, 1 classe e o custo padrão do bcrypt).
+type PasswordPolicy struct {
+	MinLength int
+	// MinCharClasses é o número mínimo de classes de caracteres distintas (minúsculas,
+	// maiúsculas, dígitos e símbolos) presentes na senha, de 1 a 4.
+	MinCharClasses int
+	// BcryptCost é o custo dos novos hashes. Hashes com custo menor são refeitos no próximo login.
+	BcryptCost int
+	// Breached é a lista de senhas vazadas, carregada por LoadBreachedPasswords.
+	Breached *BreachedPasswords
+}
+
+// Valores padrão de PasswordPolicy, usados nos campos zerados.
+const (
+	defaultPasswordMinLength      = 8
+	defaultPasswordMinCharClasses = 1
+)
+
+// Validate verifica se os limites da política são aceitáveis. Deve ser chamado na
+// inicialização, antes de a política ser passada aos serviços.
+func (policy PasswordPolicy) Validate() error {
+	if policy.MinLength < 1 || policy.MinLength > bcryptMaxPasswordLength {
+		return fmt.Errorf("tamanho mínimo de senha inválido: %d (use de 1 a %d)", policy.MinLength, bcryptMaxPasswordLength)
+	}
+	if policy.MinCharClasses < 1 || policy.MinCharClasses > 4 {
+		return fmt.Errorf("número de classes de caracteres inválido: %d (use de 1 a 4)", policy.MinCharClasses)
+	}
+	if policy.BcryptCost < bcrypt.MinCost || policy.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("custo bcrypt inválido: %d (use de %d a %d)", policy.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return nil
+}
+
+// withDefaults preenche os campos zerados com os valores padrão.
+func (policy PasswordPolicy) withDefaults() PasswordPolicy {
+	if policy.MinLength == 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+	if policy.MinCharClasses == 0 {
+		policy.MinCharClasses = defaultPasswordMinCharClasses
+	}
+	if policy.BcryptCost == 0 {
+		policy.BcryptCost = bcrypt.DefaultCost
+	}
+	return policy
+}
+
+// BreachedPasswords é uma lista de senhas vazadas. Aceita senhas em claro, comparadas sem
+// diferenciar maiúsculas, e hashes SHA-1 no formato do Have I Been Pwned ("HASH" ou "HASH:contagem").
+type BreachedPasswords struct {
+	plain map[string]struct{}
+	sha1  map[string]struct{}
+}
+
+// LoadBreachedPasswords lê a lista de senhas vazadas, uma por linha; linhas vazias e
+// iniciadas por '#' são ignoradas.
+func LoadBreachedPasswords(path string) (*BreachedPasswords, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler lista de senhas vazadas: %w", err)
+	}
+	defer file.Close()
+
+	list := &BreachedPasswords{plain: map[string]struct{}{}, sha1: map[string]struct{}{}}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			list.sha1[strings.ToUpper(hash)] = struct{}{}
+			continue
+		}
+		list.plain[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler lista de senhas vazadas: %w", err)
+	}
+	return list, nil
+}
+
+// Len retorna o número de entradas da lista.
+func (b *BreachedPasswords) Len() int {
+	return len(b.plain) + len(b.sha1)
+}
+
+// Contains informa se a senha consta na lista.
+func (b *BreachedPasswords) Contains(password string) bool {
+	if b == nil {
+		return false
+	}
+	if _, ok := b.plain[strings.ToLower(password)]; ok {
+		return true
+	}
+	sum := sha1.Sum([]byte(password))
+	_, ok := b.sha1[strings.ToUpper(hex.EncodeToString(sum[:]))]
+	return ok
+}
+
+func isSHA1Hex(s string) bool {
+	if len(s) != 2*sha1.Size {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// validate aplica a política a uma nova senha.
+func (policy PasswordPolicy) validate(password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	if len([]rune(password)) < policy.MinLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("use pelo menos %d caracteres", policy.MinLength)}
+	}
+	if len(password) > bcryptMaxPasswordLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("use no máximo %d bytes", bcryptMaxPasswordLength)}
+	}
+	if charClasses(password) < policy.MinCharClasses {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("combine pelo menos %d tipos de caracteres (minúsculas, maiúsculas, dígitos e símbolos)", policy.MinCharClasses)}
+	}
+	if policy.Breached.Contains(password) {
+		return &PasswordPolicyError{Reason: "a senha consta em uma lista de senhas vazadas"}
+	}
+	return nil
+}
+
+// charClasses conta as classes de caracteres presentes na senha.
+func charClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// hash valida a nova senha e gera o hash com o custo da política.
+func (policy PasswordPolicy) hash(password string) (string, error) {
+	if err := policy.validate(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), policy.BcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// needsRehash informa se o hash foi gerado com custo menor que o da política.
+func (policy PasswordPolicy) needsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		log.Printf("Hash de senha com formato inesperado: %v", err)
+		return false
+	}
+	return cost < policy.BcryptCost
+}
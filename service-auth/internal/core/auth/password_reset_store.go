@@ -0,0 +1,27 @@
+// internal/core/auth/password_reset_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrResetTokenNotFound é retornado quando o token de redefinição não existe ou já foi usado.
+var ErrResetTokenNotFound = errors.New("token de redefinição não encontrado")
+
+// PasswordResetToken é um token de uso único para redefinir a senha.
+// Assim como nos refresh tokens, apenas o hash SHA-256 é armazenado.
+type PasswordResetToken struct {
+	TokenHash string    `firestore:"tokenHash" json:"tokenHash"`
+	Username  string    `firestore:"username" json:"username"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time `firestore:"expiresAt" json:"expiresAt"`
+}
+
+// PasswordResetStore abstrai o armazenamento dos tokens de redefinição de senha.
+type PasswordResetStore interface {
+	Save(ctx context.Context, token *PasswordResetToken) error
+	// Consume remove o token de forma atômica e o retorna, garantindo o uso único.
+	Consume(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
+}
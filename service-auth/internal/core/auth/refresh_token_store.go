@@ -0,0 +1,40 @@
+// internal/core/auth/refresh_token_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRefreshTokenNotFound é retornado quando o hash do refresh token não existe no armazenamento.
+	ErrRefreshTokenNotFound = errors.New("refresh token não encontrado")
+	// ErrRefreshTokenReused é retornado por MarkUsed quando o token já havia sido rotacionado.
+	ErrRefreshTokenReused = errors.New("refresh token já utilizado")
+)
+
+// RefreshToken representa um refresh token opaco persistido no servidor.
+// Apenas o hash SHA-256 do token é armazenado; o valor em claro só existe na resposta ao cliente.
+// Todos os tokens gerados a partir de um mesmo login compartilham o FamilyID; Tenant é a
+// empresa ativa da sessão, mantida nas rotações.
+type RefreshToken struct {
+	TokenHash string    `firestore:"tokenHash" json:"tokenHash"`
+	FamilyID  string    `firestore:"familyId" json:"familyId"`
+	Username  string    `firestore:"username" json:"username"`
+	Tenant    string    `firestore:"tenant" json:"tenant,omitempty"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time `firestore:"expiresAt" json:"expiresAt"`
+	Used      bool      `firestore:"used" json:"used"`
+	Revoked   bool      `firestore:"revoked" json:"revoked"`
+}
+
+// RefreshTokenStore abstrai o armazenamento de refresh tokens.
+type RefreshTokenStore interface {
+	Save(ctx context.Context, token *RefreshToken) error
+	Find(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// MarkUsed marca o token como rotacionado de forma atômica e retorna
+	// ErrRefreshTokenReused se ele já estava marcado.
+	MarkUsed(ctx context.Context, tokenHash string) error
+	RevokeFamily(ctx context.Context, familyID string) error
+}
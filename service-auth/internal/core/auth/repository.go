@@ -0,0 +1,56 @@
+// internal/core/auth/repository.go
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUserNotFound é retornado pelos repositórios quando o usuário não existe.
+	ErrUserNotFound = errors.New("usuário não encontrado")
+	// ErrUserExists é retornado por Create quando já existe um usuário com o mesmo username.
+	ErrUserExists = errors.New("usuário já existe")
+)
+
+// User representa a estrutura de um usuário armazenado.
+type User struct {
+	Username     string   `firestore:"username" json:"username"`
+	PasswordHash string   `firestore:"passwordHash" json:"passwordHash"`
+	Roles        []string `firestore:"roles" json:"roles"`
+	Email        string   `firestore:"email" json:"email,omitempty"`
+	Disabled     bool     `firestore:"disabled" json:"disabled"`
+
+	// Tenants lista as empresas clientes em que o usuário trabalha; a primeira é a
+	// empresa ativa após o login.
+	Tenants []string `firestore:"tenants" json:"tenants,omitempty"`
+
+	// ExternalIDs vincula o usuário a identidades de provedores OIDC, no formato
+	// "<provedor>:<sub>".
+	ExternalIDs []string `firestore:"externalIds" json:"externalIds,omitempty"`
+
+	// Segundo fator (TOTP). TOTPSecret preenchido com TOTPEnabled falso indica um cadastro
+	// ainda não confirmado. RecoveryCodes guarda apenas os hashes SHA-256 dos códigos.
+	TOTPSecret    string   `firestore:"totpSecret" json:"totpSecret,omitempty"`
+	TOTPEnabled   bool     `firestore:"totpEnabled" json:"totpEnabled,omitempty"`
+	TOTPLastStep  int64    `firestore:"totpLastStep" json:"totpLastStep,omitempty"`
+	RecoveryCodes []string `firestore:"recoveryCodes" json:"recoveryCodes,omitempty"`
+}
+
+// UserRepository abstrai o armazenamento de usuários usado pelo serviço de autenticação.
+type UserRepository interface {
+	FindByUsername(ctx context.Context, username string) (*User, error)
+	// FindByExternalID localiza o usuário vinculado à identidade externa informada.
+	FindByExternalID(ctx context.Context, externalID string) (*User, error)
+	List(ctx context.Context) ([]User, error)
+	Create(ctx context.Context, user *User) error
+	// Update substitui os dados do usuário identificado por user.Username.
+	Update(ctx context.Context, user *User) error
+	// Modify relê o usuário, aplica change e grava o resultado de forma atômica, para
+	// alterações que dependem do estado atual (ex.: o último intervalo TOTP usado). Se change
+	// retornar erro, nada é gravado e o erro é devolvido.
+	Modify(ctx context.Context, username string, change func(user *User) error) (*User, error)
+	// UpdatePasswordHash grava apenas o hash da senha do usuário, sem tocar nos demais campos.
+	UpdatePasswordHash(ctx context.Context, username, hash string) error
+	Delete(ctx context.Context, username string) error
+}
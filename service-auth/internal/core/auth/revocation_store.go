@@ -0,0 +1,14 @@
+// internal/core/auth/revocation_store.go
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// RevocationStore guarda os identificadores (jti) de access tokens revogados antes do exp.
+// Cada entrada só precisa existir até o momento em que o próprio token expiraria.
+type RevocationStore interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
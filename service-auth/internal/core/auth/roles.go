@@ -0,0 +1,187 @@
+// internal/core/auth/roles.go
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RoleAdmin concede acesso à API de administração de usuários.
+const RoleAdmin = "admin"
+
+// RoleIntrospect permite consultar a introspecção de tokens e a lista de revogação. É
+// concedida às contas de serviço internas (gateway e serviços Go); tokens de usuários não são
+// aceitos nessas rotas mesmo com a role (middleware.RequireServiceAccount).
+const RoleIntrospect = "token-introspect"
+
+// KnownRoles lista as permissões concretas. Deve acompanhar as permissões checadas pelo
+// gateway em cada rota (permissionMiddleware). User.Roles também aceita grupos e curingas,
+// resolvidos nessas permissões ao emitir o token (RoleGroups.resolve).
+var KnownRoles = []string{
+	RoleAdmin,
+	RoleIntrospect,
+	"analise-icms",
+	"analise-ipi-st",
+	"converter-francesinha",
+	"converter-receitas-acisa",
+	"converter-atolini-pagamentos",
+	"converter-atolini-recebimentos",
+}
+
+// InvalidRoleError é retornado quando uma role não consta em KnownRoles.
+type InvalidRoleError struct {
+	Role string
+}
+
+func (e *InvalidRoleError) Error() string {
+	return fmt.Sprintf("role desconhecida: %s", e.Role)
+}
+
+// IsKnownRole informa se a role consta em KnownRoles.
+func IsKnownRole(role string) bool {
+	for _, known := range KnownRoles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
+
+// roleWildcardSuffix marca uma role curinga: "converter-*" concede todas as permissões de
+// KnownRoles com o prefixo "converter-". Curingas nunca concedem RoleAdmin nem RoleIntrospect.
+const roleWildcardSuffix = "*"
+
+// RoleGroups associa cada grupo (ex.: "contador-pleno") às roles que ele concede, que podem
+// ser permissões, curingas ou outros grupos. É carregado na inicialização (LoadRoleGroups e
+// Validate) e injetado nos serviços que validam ou resolvem roles; nil não tem grupos.
+type RoleGroups map[string][]string
+
+// Validate confere os nomes e os membros dos grupos e rejeita ciclos.
+func (g RoleGroups) Validate() error {
+	for name, members := range g {
+		if name == "" || IsKnownRole(name) || strings.HasSuffix(name, roleWildcardSuffix) {
+			return fmt.Errorf("nome de grupo de roles inválido: %q", name)
+		}
+		for _, member := range members {
+			if _, isGroup := g[member]; !isGroup && !IsKnownRole(member) && !isValidWildcard(member) {
+				return fmt.Errorf("grupo %s: %w", name, &InvalidRoleError{Role: member})
+			}
+		}
+	}
+	for name := range g {
+		if err := checkGroupCycle(g, name, map[string]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadRoleGroups lê os grupos de um arquivo JSON no formato {"grupo": ["role", ...]}.
+func LoadRoleGroups(path string) (RoleGroups, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de grupos de roles: %w", err)
+	}
+	var groups RoleGroups
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("arquivo de grupos de roles inválido: %w", err)
+	}
+	return groups, nil
+}
+
+func checkGroupCycle(groups RoleGroups, name string, path map[string]bool) error {
+	if path[name] {
+		return fmt.Errorf("ciclo no grupo de roles %s", name)
+	}
+	path[name] = true
+	defer delete(path, name)
+	for _, member := range groups[name] {
+		if _, isGroup := groups[member]; isGroup {
+			if err := checkGroupCycle(groups, member, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isValidWildcard informa se a role é um curinga que corresponde a ao menos uma permissão.
+func isValidWildcard(role string) bool {
+	return strings.HasSuffix(role, roleWildcardSuffix) && len(matchWildcard(role)) > 0
+}
+
+// matchWildcard retorna as permissões de KnownRoles, exceto RoleAdmin e RoleIntrospect, que o
+// curinga concede.
+func matchWildcard(role string) []string {
+	prefix := strings.TrimSuffix(role, roleWildcardSuffix)
+	var matches []string
+	for _, known := range KnownRoles {
+		if known != RoleAdmin && known != RoleIntrospect && strings.HasPrefix(known, prefix) {
+			matches = append(matches, known)
+		}
+	}
+	return matches
+}
+
+// isAssignable informa se a role pode ser atribuída: permissão, grupo ou curinga.
+func (g RoleGroups) isAssignable(role string) bool {
+	if _, isGroup := g[role]; isGroup {
+		return true
+	}
+	return IsKnownRole(role) || isValidWildcard(role)
+}
+
+// normalize valida as roles e retorna a lista sem duplicatas, em ordem alfabética.
+func (g RoleGroups) normalize(roles []string) ([]string, error) {
+	seen := make(map[string]bool, len(roles))
+	normalized := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if !g.isAssignable(role) {
+			return nil, &InvalidRoleError{Role: role}
+		}
+		if !seen[role] {
+			seen[role] = true
+			normalized = append(normalized, role)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// resolve expande grupos e curingas nas permissões concretas de KnownRoles, que são as
+// enviadas no token e checadas pelo gateway. Roles desconhecidas (ex.: um grupo removido da
+// configuração) são ignoradas.
+func (g RoleGroups) resolve(roles []string) []string {
+	seen := make(map[string]bool)
+	resolved := make([]string, 0, len(roles))
+	// Validate rejeita ciclos, então a expansão sempre termina.
+	var expand func(role string)
+	expand = func(role string) {
+		var permissions []string
+		switch members, isGroup := g[role]; {
+		case isGroup:
+			for _, member := range members {
+				expand(member)
+			}
+			return
+		case IsKnownRole(role):
+			permissions = []string{role}
+		case strings.HasSuffix(role, roleWildcardSuffix):
+			permissions = matchWildcard(role)
+		}
+		for _, permission := range permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				resolved = append(resolved, permission)
+			}
+		}
+	}
+	for _, role := range roles {
+		expand(role)
+	}
+	sort.Strings(resolved)
+	return resolved
+}
@@ -3,81 +3,880 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log"
-	"os"
+	"net/http"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
-	"google.golang.org/api/iterator"
+)
+
+var (
+	// ErrInvalidRefreshToken é retornado por Refresh quando o token não pode ser trocado.
+	ErrInvalidRefreshToken = errors.New("refresh token inválido ou expirado")
+	// ErrInvalidToken é retornado quando um access token é inválido, expirado ou revogado.
+	ErrInvalidToken = errors.New("token inválido ou expirado")
+	// ErrUserDisabled é retornado no login de um usuário desativado pela administração.
+	ErrUserDisabled = errors.New("usuário desativado")
+	// ErrInvalidMFAChallenge é retornado quando o desafio de segundo fator é inválido ou expirou.
+	ErrInvalidMFAChallenge = errors.New("desafio de autenticação inválido ou expirado")
+
+	// errInvalidCredentials é a resposta do login para usuário inexistente ou senha
+	// incorreta, sem distinguir os dois casos.
+	errInvalidCredentials = errors.New("usuário ou senha inválidos")
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+	defaultMFAChallengeTTL = 5 * time.Minute
 )
 
 type Service interface {
-	Login(ctx context.Context, username, password string) (string, error)
+	Login(ctx context.Context, username, password string, client ClientInfo) (*LoginResult, error)
+	// VerifyPassword reautentica um usuário já logado antes de uma operação sensível, com a
+	// mesma conferência de credenciais e o mesmo throttle do Login. Retorna ErrWrongPassword
+	// se a senha não confere.
+	VerifyPassword(ctx context.Context, username, password string) error
+	// CompleteMFA troca o desafio emitido pelo Login e um código TOTP (ou de recuperação)
+	// pelo par de tokens.
+	CompleteMFA(ctx context.Context, challengeToken, code string, client ClientInfo) (*TokenPair, error)
+	// ExchangeAPIKey troca a chave de API de uma conta de serviço por um access token,
+	// sem refresh token: o cliente repete a troca quando o token expira.
+	ExchangeAPIKey(ctx context.Context, apiKey string) (*TokenPair, error)
+	// OIDCProviders lista os nomes dos provedores de identidade externos configurados.
+	OIDCProviders() []string
+	// StartOIDC inicia o login no provedor externo e retorna a URL de autorização, com
+	// state, nonce e desafio PKCE, e o vínculo a ser guardado em um cookie do navegador.
+	// loginHint (opcional) sugere a conta ao provedor.
+	StartOIDC(ctx context.Context, provider, loginHint string) (authorizationURL, binding string, err error)
+	// CompleteOIDC troca o código recebido no callback pela identidade externa e conclui o
+	// login do usuário local vinculado, como em Login. binding é o valor do cookie definido
+	// em StartOIDC; sem ele, o state é recusado.
+	CompleteOIDC(ctx context.Context, provider, code, state, binding string, client ClientInfo) (*LoginResult, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	ValidateAccessToken(ctx context.Context, accessToken string) (*Claims, error)
+	// Introspect descreve o access token (RFC 7662). Tokens inválidos, expirados ou
+	// revogados retornam Active falso, sem erro.
+	Introspect(ctx context.Context, accessToken string) (*TokenInfo, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	// SwitchTenant emite um novo par de tokens com outra empresa ativa e encerra a sessão
+	// do access token (e do refresh token, se informado) apresentado.
+	SwitchTenant(ctx context.Context, accessToken, refreshToken, tenant string) (*TokenPair, error)
+	// IsRevoked informa se o jti ou a sessão (sid, opcional) de um access token foram revogados.
+	IsRevoked(ctx context.Context, jti, sid string) (bool, error)
+	JWKS() JWKSet
+	// Sessions retorna o serviço de sessões usado pelo login, para que a API de sessões e a
+	// administração compartilhem a mesma instância.
+	Sessions() SessionService
+}
+
+// ClientInfo identifica a origem de uma requisição de autenticação.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// TokenPair é o par de tokens entregue ao cliente após login ou refresh.
+// RefreshToken fica vazio nos tokens de contas de serviço.
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// TokenInfo descreve um access token no formato de resposta da introspecção (RFC 7662).
+type TokenInfo struct {
+	Active    bool     `json:"active"`
+	TokenType string   `json:"token_type,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Tenant    string   `json:"tenant,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	JTI       string   `json:"jti,omitempty"`
+}
+
+// LoginResult é o resultado do Login: o par de tokens ou, para usuários com 2FA ativo,
+// o desafio a ser concluído em CompleteMFA.
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge *MFAChallengeToken
+}
+
+// MFAChallengeToken é entregue ao cliente quando o login exige o segundo fator.
+type MFAChallengeToken struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"`
+}
+
+// TokenConfig define o tempo de vida e a identificação dos tokens emitidos. Valores zerados
+// usam os padrões.
+type TokenConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	MFAChallengeTTL time.Duration
+	// Issuer e Audience preenchem os claims iss e aud, exigidos na validação dos tokens.
+	Issuer   string
+	Audience string
+}
+
+// Stores agrupa os armazenamentos usados pelo serviço de autenticação.
+type Stores struct {
+	Users           UserRepository
+	RefreshTokens   RefreshTokenStore
+	Revocations     RevocationStore
+	SigningKeys     KeyStore
+	PasswordResets  PasswordResetStore
+	LoginAttempts   LoginAttemptStore
+	MFAChallenges   MFAChallengeStore
+	ServiceAccounts ServiceAccountStore
+	AuditEvents     AuditStore
+	Tenants         TenantStore
+	OIDCStates      OIDCStateStore
+	Sessions        SessionStore
 }
 
 type service struct {
-	db        *firestore.Client
-	jwtSecret []byte
+	users         UserRepository
+	refreshTokens RefreshTokenStore
+	revocations   RevocationStore
+	mfaChallenges MFAChallengeStore
+	accounts      ServiceAccountStore
+	tenants       TenantStore
+	oidcStates    OIDCStateStore
+	sessions      *sessionService
+	keys          KeyManager
+	throttle      *loginThrottle
+	audit         auditor
+	tokenConfig   TokenConfig
+	oidcProviders map[string]*oidcProvider
+	oidcNames     []string
+	oidcStateTTL  time.Duration
+	ldap          *ldapDirectory
+	roleGroups    RoleGroups
+	// passwordPolicy define o custo bcrypt para o qual os hashes são refeitos no login.
+	passwordPolicy PasswordPolicy
+}
+
+// NewService cria o serviço de autenticação. ldapConfig nil desativa a autenticação no
+// diretório LDAP. roleGroups expande os grupos nas roles dos tokens emitidos e
+// passwordPolicy define o custo bcrypt dos hashes refeitos no login.
+func NewService(stores Stores, keys KeyManager, tokenConfig TokenConfig, throttleConfig ThrottleConfig, oidcConfig OIDCConfig, ldapConfig *LDAPConfig, roleGroups RoleGroups, passwordPolicy PasswordPolicy) Service {
+	if tokenConfig.AccessTokenTTL <= 0 {
+		tokenConfig.AccessTokenTTL = defaultAccessTokenTTL
+	}
+	if tokenConfig.RefreshTokenTTL <= 0 {
+		tokenConfig.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+	if tokenConfig.MFAChallengeTTL <= 0 {
+		tokenConfig.MFAChallengeTTL = defaultMFAChallengeTTL
+	}
+	if tokenConfig.Issuer == "" {
+		tokenConfig.Issuer = defaultTokenIssuer
+	}
+	if tokenConfig.Audience == "" {
+		tokenConfig.Audience = defaultTokenAudience
+	}
+
+	if oidcConfig.StateTTL <= 0 {
+		oidcConfig.StateTTL = defaultOIDCStateTTL
+	}
+	if oidcConfig.HTTPClient == nil {
+		oidcConfig.HTTPClient = &http.Client{Timeout: defaultOIDCHTTPTimeout}
+	}
+	providers := make(map[string]*oidcProvider, len(oidcConfig.Providers))
+	names := make([]string, 0, len(oidcConfig.Providers))
+	for _, config := range oidcConfig.Providers {
+		providers[config.Name] = newOIDCProvider(config, oidcConfig.HTTPClient)
+		names = append(names, config.Name)
+	}
+
+	var directory *ldapDirectory
+	if ldapConfig != nil {
+		directory = newLDAPDirectory(*ldapConfig)
+	}
+
+	return &service{
+		users:          stores.Users,
+		refreshTokens:  stores.RefreshTokens,
+		revocations:    stores.Revocations,
+		mfaChallenges:  stores.MFAChallenges,
+		accounts:       stores.ServiceAccounts,
+		tenants:        stores.Tenants,
+		oidcStates:     stores.OIDCStates,
+		sessions:       newSessionService(stores.Sessions, stores.RefreshTokens, stores.Revocations, stores.AuditEvents, tokenConfig.AccessTokenTTL),
+		keys:           keys,
+		throttle:       newLoginThrottle(stores.LoginAttempts, throttleConfig),
+		audit:          auditor{store: stores.AuditEvents},
+		tokenConfig:    tokenConfig,
+		oidcProviders:  providers,
+		oidcNames:      names,
+		oidcStateTTL:   oidcConfig.StateTTL,
+		ldap:           directory,
+		roleGroups:     roleGroups,
+		passwordPolicy: passwordPolicy.withDefaults(),
+	}
+}
+
+func (s *service) Login(ctx context.Context, username, password string, client ClientInfo) (*LoginResult, error) {
+	// 1. Recusar tentativas de usernames ou IPs em backoff ou bloqueados e reservar esta.
+	attempt, err := s.reserveLoginAttempt(ctx, username, client)
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseLoginAttempt(ctx, attempt)
+
+	// 2. Encontrar o usuário no repositório.
+	user, err := s.users.FindByUsername(ctx, username)
+	directory := s.ldap != nil && (errors.Is(err, ErrUserNotFound) || err == nil && isDirectoryUser(user))
+	switch {
+	case directory:
+		// Usuários do diretório, e os ainda não cadastrados quando há diretório configurado,
+		// conferem a senha no LDAP.
+		if user, err = s.ldapLogin(ctx, attempt, password, client); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, ErrUserNotFound):
+		s.recordLoginFailure(ctx, attempt, client, "usuário inexistente")
+		return nil, errInvalidCredentials
+	case err != nil:
+		log.Printf("Erro detalhado do repositório de usuários: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	default:
+		// 3. Comparar a senha fornecida com o hash armazenado.
+		if err := s.checkLocalPassword(ctx, attempt, user, password, client); err != nil {
+			return nil, err
+		}
+	}
+	if user.Disabled {
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, username, false, "usuário desativado")
+		return nil, ErrUserDisabled
+	}
+	detail := "ldap"
+	if !directory {
+		detail = ""
+		s.rehashPassword(ctx, user, password)
+	}
+
+	// 4. Com 2FA ativo, emitir um desafio. As falhas só são zeradas após o segundo fator,
+	// para que a senha correta não libere novas tentativas de código.
+	if user.TOTPEnabled {
+		challenge, err := s.issueChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		s.audit.recordFrom(ctx, client, AuditMFAChallenge, user.Username, true, detail)
+		return &LoginResult{Challenge: challenge}, nil
+	}
+	s.recordLoginSuccess(ctx, attempt)
+
+	// 5. Gerar o par de tokens, iniciando uma nova família de refresh tokens.
+	tokens, err := s.startSession(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	s.audit.recordFrom(ctx, client, AuditLoginSuccess, user.Username, true, detail)
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+func (s *service) VerifyPassword(ctx context.Context, username, password string) error {
+	client := clientInfoFrom(ctx)
+	attempt, err := s.reserveLoginAttempt(ctx, username, client)
+	if err != nil {
+		return err
+	}
+	defer s.releaseLoginAttempt(ctx, attempt)
+
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if s.ldap != nil && isDirectoryUser(user) {
+		_, err = s.authenticateDirectory(ctx, attempt, password, client)
+	} else {
+		err = s.checkLocalPassword(ctx, attempt, user, password, client)
+	}
+	if errors.Is(err, errInvalidCredentials) {
+		return ErrWrongPassword
+	}
+	if err != nil {
+		return err
+	}
+	s.recordLoginSuccess(ctx, attempt)
+	return nil
+}
+
+// checkLocalPassword compara a senha com o hash local, contabilizando a falha no throttle.
+func (s *service) checkLocalPassword(ctx context.Context, attempt *loginAttempt, user *User, password string, client ClientInfo) error {
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		s.recordLoginFailure(ctx, attempt, client, "senha incorreta")
+		return errInvalidCredentials
+	}
+	return nil
 }
 
-func NewService(db *firestore.Client, jwtSecret []byte) Service {
+func (s *service) CompleteMFA(ctx context.Context, challengeToken, code string, client ClientInfo) (*TokenPair, error) {
+	challengeHash := hashToken(challengeToken)
+	challenge, err := s.mfaChallenges.Find(ctx, challengeHash)
+	if errors.Is(err, ErrMFAChallengeNotFound) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar desafio de autenticação: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	// Os códigos errados contam como falhas de login do usuário, limitando a força bruta.
+	attempt, err := s.reserveLoginAttempt(ctx, challenge.Username, client)
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseLoginAttempt(ctx, attempt)
+
+	// Confere o código e grava o último intervalo TOTP usado (ou descarta o código de
+	// recuperação) na mesma operação atômica, para que um código não seja aceito duas vezes.
+	now := time.Now()
+	user, err := s.users.Modify(ctx, challenge.Username, func(user *User) error {
+		if user.Disabled {
+			return ErrUserDisabled
+		}
+		if !user.TOTPEnabled {
+			return ErrInvalidMFAChallenge
+		}
+		if !verifySecondFactor(user, code, now) {
+			return ErrInvalidOTP
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrInvalidOTP):
+		s.recordLoginFailure(ctx, attempt, client, "código de verificação inválido")
+		return nil, err
+	case errors.Is(err, ErrUserNotFound):
+		return nil, ErrInvalidMFAChallenge
+	case errors.Is(err, ErrUserDisabled), errors.Is(err, ErrInvalidMFAChallenge):
+		return nil, err
+	case err != nil:
+		log.Printf("Erro ao atualizar segundo fator de %s: %v", challenge.Username, err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+
+	if err := s.mfaChallenges.Consume(ctx, challengeHash); err != nil {
+		if errors.Is(err, ErrMFAChallengeNotFound) {
+			return nil, ErrInvalidMFAChallenge
+		}
+		log.Printf("Erro ao concluir desafio de autenticação: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	s.recordLoginSuccess(ctx, attempt)
+
+	tokens, err := s.startSession(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	s.audit.recordFrom(ctx, client, AuditLoginSuccess, user.Username, true, "segundo fator")
+	return tokens, nil
+}
+
+func (s *service) ExchangeAPIKey(ctx context.Context, apiKey string) (*TokenPair, error) {
+	id, secret, ok := parseAPIKey(apiKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.accounts.FindKey(ctx, id)
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar chave de API: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 ||
+		key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		s.audit.record(ctx, AuditAPIKeyExchange, serviceAccountSubject(key.Account), false, "chave "+key.ID+" inválida, revogada ou expirada")
+		return nil, ErrInvalidAPIKey
+	}
 
-	if len(jwtSecret) == 0 {
-		if env := os.Getenv("JWT_SECRET"); env != "" {
-			jwtSecret = []byte(env)
+	account, err := s.accounts.FindAccount(ctx, key.Account)
+	if errors.Is(err, ErrServiceAccountNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar conta de serviço: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if account.Disabled {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// As roles da conta podem ter sido reduzidas depois da criação da chave.
+	granted := s.roleGroups.resolve(account.Roles)
+	roles := make([]string, 0, len(key.Roles))
+	for _, role := range s.roleGroups.resolve(key.Roles) {
+		if containsRole(granted, role) {
+			roles = append(roles, role)
 		}
 	}
 
-	return &service{db: db, jwtSecret: jwtSecret}
+	if err := s.accounts.TouchKey(ctx, key.ID, now); err != nil {
+		log.Printf("Erro ao registrar uso da chave de API %s: %v", key.ID, err)
+	}
+
+	accessToken, err := s.signAccessToken(serviceAccountSubject(account.Name), roles, "", "", now)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditAPIKeyExchange, serviceAccountSubject(account.Name), true, "chave "+key.ID)
+	return &TokenPair{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(s.tokenConfig.AccessTokenTTL.Seconds()),
+	}, nil
 }
 
-// User representa a estrutura de um usuário no Firestore.
-type User struct {
-	Username     string   `firestore:"username"`
-	PasswordHash string   `firestore:"passwordHash"`
-	Roles        []string `firestore:"roles"`
+// issueChallenge registra um desafio de segundo fator para o usuário.
+func (s *service) issueChallenge(ctx context.Context, user *User) (*MFAChallengeToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, errors.New("erro ao gerar desafio de autenticação")
+	}
+	now := time.Now()
+	err = s.mfaChallenges.Save(ctx, &MFAChallenge{
+		TokenHash: hashToken(token),
+		Username:  user.Username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.tokenConfig.MFAChallengeTTL),
+	})
+	if err != nil {
+		log.Printf("Erro ao salvar desafio de autenticação: %v", err)
+		return nil, errors.New("erro ao gerar desafio de autenticação")
+	}
+	return &MFAChallengeToken{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresIn:      int64(s.tokenConfig.MFAChallengeTTL.Seconds()),
+	}, nil
 }
 
-func (s *service) Login(ctx context.Context, username, password string) (string, error) {
-	// 1. Encontrar o usuário no Firestore.
-	query := s.db.Collection("users").Where("username", "==", username).Limit(1).Documents(ctx)
-	defer query.Stop()
+// startSession abre uma nova sessão e emite o primeiro par de tokens da sua família de
+// refresh tokens, com a empresa padrão do usuário (ou a empresa informada, já validada) ativa.
+func (s *service) startSession(ctx context.Context, user *User, tenant string) (*TokenPair, error) {
+	if tenant == "" {
+		var err error
+		tenant, err = s.sessionTenant(ctx, user, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, errors.New("erro ao gerar token de acesso")
+	}
+	return s.issueTokens(ctx, user, familyID, tenant)
+}
 
-	doc, err := query.Next()
-	if err == iterator.Done {
-		return "", errors.New("usuário ou senha inválidos")
+// sessionTenant retorna a empresa ativa de uma sessão: a preferida, se o usuário ainda tiver
+// acesso a ela, ou a primeira empresa ativa do usuário.
+func (s *service) sessionTenant(ctx context.Context, user *User, preferred string) (string, error) {
+	if preferred != "" {
+		err := memberTenant(ctx, s.tenants, user, preferred)
+		if err == nil {
+			return preferred, nil
+		}
+		if !errors.Is(err, ErrTenantNotMember) && !errors.Is(err, ErrTenantDisabled) {
+			log.Printf("Erro ao consultar empresa %s: %v", preferred, err)
+			return "", errors.New("erro ao consultar o banco de dados")
+		}
 	}
+	tenant, err := defaultTenant(ctx, s.tenants, user)
 	if err != nil {
-		log.Printf("Erro detalhado do Firestore: %v", err)
+		log.Printf("Erro ao consultar empresas de %s: %v", user.Username, err)
 		return "", errors.New("erro ao consultar o banco de dados")
 	}
+	return tenant, nil
+}
+
+// rehashPassword refaz o hash da senha com o custo bcrypt da política quando o hash gravado
+// usa um custo menor. A senha não passa pela política: usuários com senhas antigas continuam
+// entrando. Só o campo do hash é gravado, para não sobrescrever alterações concorrentes do
+// usuário. Falhas são apenas registradas e não impedem o login.
+func (s *service) rehashPassword(ctx context.Context, user *User, password string) {
+	if !s.passwordPolicy.needsRehash(user.PasswordHash) {
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.passwordPolicy.BcryptCost)
+	if err != nil {
+		log.Printf("Erro ao refazer hash da senha de %s: %v", user.Username, err)
+		return
+	}
+	user.PasswordHash = string(hash)
+	if err := s.users.UpdatePasswordHash(ctx, user.Username, user.PasswordHash); err != nil {
+		log.Printf("Erro ao gravar novo hash da senha de %s: %v", user.Username, err)
+	}
+}
+
+// reserveLoginAttempt reserva a tentativa no throttle, recusando usernames ou IPs em
+// backoff ou bloqueados.
+func (s *service) reserveLoginAttempt(ctx context.Context, username string, client ClientInfo) (*loginAttempt, error) {
+	attempt, err := s.throttle.reserve(ctx, username, client.IP)
+	var tooMany *TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		s.audit.recordFrom(ctx, client, AuditLoginFailure, username, false, "bloqueado por excesso de tentativas")
+		return nil, tooMany
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar tentativas de login: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	return attempt, nil
+}
+
+// releaseLoginAttempt desfaz a reserva se a tentativa terminou sem sucesso nem falha.
+func (s *service) releaseLoginAttempt(ctx context.Context, attempt *loginAttempt) {
+	if err := attempt.release(ctx); err != nil {
+		log.Printf("Erro ao liberar tentativa de login de %s (%s): %v", attempt.username, attempt.ip, err)
+	}
+}
+
+func (s *service) recordLoginSuccess(ctx context.Context, attempt *loginAttempt) {
+	if err := attempt.succeed(ctx); err != nil {
+		log.Printf("Erro ao zerar tentativas de login de %s: %v", attempt.username, err)
+	}
+}
+
+// recordLoginFailure contabiliza a falha no throttle e a registra na auditoria.
+func (s *service) recordLoginFailure(ctx context.Context, attempt *loginAttempt, client ClientInfo, reason string) {
+	if err := attempt.fail(ctx); err != nil {
+		log.Printf("Erro ao registrar tentativa de login de %s (%s): %v", attempt.username, client.IP, err)
+	}
+	s.audit.recordFrom(ctx, client, AuditLoginFailure, attempt.username, false, reason)
+}
+
+// Refresh troca um refresh token válido por um novo par de tokens (rotação).
+// Se um token já rotacionado for apresentado novamente, toda a família é revogada,
+// pois isso indica que o token vazou e está sendo usado por mais de um cliente.
+func (s *service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	tokenHash := hashToken(refreshToken)
+
+	stored, err := s.refreshTokens.Find(ctx, tokenHash)
+	if errors.Is(err, ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar refresh token: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if stored.Revoked || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	err = s.refreshTokens.MarkUsed(ctx, tokenHash)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		log.Printf("Reuso de refresh token detectado para o usuário %s; revogando a família %s", stored.Username, stored.FamilyID)
+		s.audit.record(ctx, AuditTokenReuse, stored.Username, false, "família "+stored.FamilyID+" revogada")
+		if err := s.refreshTokens.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Erro ao revogar família de refresh tokens %s: %v", stored.FamilyID, err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		log.Printf("Erro ao rotacionar refresh token: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+
+	// Recarrega o usuário para que o novo token reflita as roles atuais
+	// e para barrar usuários removidos ou desativados.
+	user, err := s.users.FindByUsername(ctx, stored.Username)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		log.Printf("Erro detalhado do repositório de usuários: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if user.Disabled {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// A empresa da sessão é mantida enquanto o usuário tiver acesso a ela.
+	tenant, err := s.sessionTenant(ctx, user, stored.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.issueTokens(ctx, user, stored.FamilyID, tenant)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditTokenRefresh, user.Username, true, "")
+	return tokens, nil
+}
+
+// ValidateAccessToken verifica assinatura, emissor, audiência, validade e revogação de um
+// access token e retorna suas claims.
+func (s *service) ValidateAccessToken(ctx context.Context, accessToken string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.ValidMethods()),
+		jwt.WithIssuer(s.tokenConfig.Issuer),
+		jwt.WithAudience(s.tokenConfig.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.ID == "" || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.IsRevoked(ctx, claims.ID, claims.SessionID)
+	if err != nil {
+		log.Printf("Erro ao consultar lista de revogação: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *service) Introspect(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	claims, err := s.ValidateAccessToken(ctx, accessToken)
+	if errors.Is(err, ErrInvalidToken) {
+		return &TokenInfo{Active: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return TokenInfoFromClaims(claims), nil
+}
+
+// TokenInfoFromClaims monta o TokenInfo de claims já validadas por ValidateAccessToken.
+func TokenInfoFromClaims(claims *Claims) *TokenInfo {
+	info := &TokenInfo{
+		Active:    true,
+		TokenType: "access_token",
+		Username:  claims.Subject,
+		Roles:     claims.Roles,
+		Tenant:    claims.Tenant,
+		SessionID: claims.SessionID,
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		JTI:       claims.ID,
+	}
+	if info.Roles == nil {
+		info.Roles = []string{}
+	}
+	if claims.IssuedAt != nil {
+		info.IssuedAt = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		info.NotBefore = claims.NotBefore.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		info.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return info
+}
+
+// Logout revoga o access token apresentado até o seu exp. Se um refresh token do mesmo
+// usuário for informado, a sua sessão também é encerrada.
+func (s *service) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	claims, err := s.ValidateAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if err := s.endSession(ctx, claims, refreshToken); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditLogout, claims.Subject, true, "")
+	return nil
+}
+
+func (s *service) SwitchTenant(ctx context.Context, accessToken, refreshToken, tenant string) (*TokenPair, error) {
+	claims, err := s.ValidateAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	username := claims.Subject
+
+	// Contas de serviço não constam no repositório de usuários e não têm empresas.
+	user, err := s.users.FindByUsername(ctx, username)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrTenantNotMember
+	}
+	if err != nil {
+		log.Printf("Erro detalhado do repositório de usuários: %v", err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+	if err := memberTenant(ctx, s.tenants, user, tenant); err != nil {
+		if errors.Is(err, ErrTenantNotMember) || errors.Is(err, ErrTenantDisabled) {
+			s.audit.record(ctx, AuditTenantSwitch, username, false, tenant+": "+err.Error())
+			return nil, err
+		}
+		log.Printf("Erro ao consultar empresa %s: %v", tenant, err)
+		return nil, errors.New("erro ao consultar o banco de dados")
+	}
+
+	tokens, err := s.startSession(ctx, user, tenant)
+	if err != nil {
+		return nil, err
+	}
+	// Os tokens da empresa anterior deixam de valer, para que o cliente não misture os dados.
+	if err := s.endSession(ctx, claims, refreshToken); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditTenantSwitch, username, true, claims.Tenant+" -> "+tenant)
+	return tokens, nil
+}
+
+// endSession revoga o access token das claims até o seu exp. Se um refresh token do mesmo
+// usuário for informado, a sua sessão também é encerrada.
+func (s *service) endSession(ctx context.Context, claims *Claims, refreshToken string) error {
+	if err := s.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		log.Printf("Erro ao revogar access token: %v", err)
+		return errors.New("erro ao revogar token")
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := s.refreshTokens.Find(ctx, hashToken(refreshToken))
+	if errors.Is(err, ErrRefreshTokenNotFound) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("Erro ao consultar refresh token: %v", err)
+		return errors.New("erro ao revogar token")
+	}
+	if stored.Username != claims.Subject {
+		return nil
+	}
+	if err := s.sessions.endByID(ctx, stored.FamilyID); err != nil {
+		log.Printf("Erro ao encerrar a sessão %s: %v", stored.FamilyID, err)
+		return errors.New("erro ao revogar token")
+	}
+	return nil
+}
 
-	var user User
-	if err := doc.DataTo(&user); err != nil {
-		return "", errors.New("erro ao ler dados do usuário")
+// IsRevoked informa se o jti ou a sessão constam na lista de revogação.
+func (s *service) IsRevoked(ctx context.Context, jti, sid string) (bool, error) {
+	revoked, err := s.revocations.IsRevoked(ctx, jti)
+	if err != nil || revoked || sid == "" {
+		return revoked, err
 	}
+	return s.sessions.isRevoked(ctx, sid)
+}
+
+// JWKS retorna as chaves públicas usadas para verificar os tokens emitidos.
+func (s *service) JWKS() JWKSet {
+	return s.keys.JWKS()
+}
+
+func (s *service) Sessions() SessionService {
+	return s.sessions
+}
 
-	// 2. Comparar a senha fornecida com o hash armazenado.
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+// issueTokens gera um access token JWT e um novo refresh token pertencente à família informada,
+// ambos com a empresa ativa informada, e registra o uso da sessão da família.
+func (s *service) issueTokens(ctx context.Context, user *User, familyID, tenant string) (*TokenPair, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.tokenConfig.RefreshTokenTTL)
+
+	// Um refresh de uma sessão já encerrada não grava um novo refresh token. O encerramento
+	// concorrente é barrado por touch, depois da gravação (veja sessionService.end).
+	ended, err := s.sessions.ended(ctx, familyID)
 	if err != nil {
-		return "", errors.New("usuário ou senha inválidos")
+		log.Printf("Erro ao consultar a sessão %s: %v", familyID, err)
+		return nil, errors.New("erro ao gerar refresh token")
+	}
+	if ended {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	// 3. Gerar o Token JWT com as permissões (roles).
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"username": user.Username,
-		"roles":    user.Roles,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+	accessToken, err := s.signAccessToken(user.Username, s.roleGroups.resolve(user.Roles), tenant, familyID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, errors.New("erro ao gerar refresh token")
+	}
+	err = s.refreshTokens.Save(ctx, &RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		FamilyID:  familyID,
+		Username:  user.Username,
+		Tenant:    tenant,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
 	})
+	if err != nil {
+		log.Printf("Erro ao salvar refresh token: %v", err)
+		return nil, errors.New("erro ao gerar refresh token")
+	}
+	err = s.sessions.touch(ctx, familyID, user, tenant, now, expiresAt)
+	if errors.Is(err, errSessionEnded) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		log.Printf("Erro ao salvar a sessão %s: %v", familyID, err)
+		return nil, errors.New("erro ao gerar refresh token")
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokenConfig.AccessTokenTTL.Seconds()),
+	}, nil
+}
 
-	tokenString, err := claims.SignedString(s.jwtSecret)
+// signAccessToken assina um access token JWT com um jti novo. Os claims tenant e sid só são
+// incluídos quando há uma empresa ativa e uma sessão (contas de serviço não têm).
+func (s *service) signAccessToken(subject string, roles []string, tenant, sessionID string, now time.Time) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", errors.New("erro ao gerar token de acesso")
+	}
 
+	accessToken, err := s.keys.Sign(newClaims(s.tokenConfig, jti, subject, roles, tenant, sessionID, now))
 	if err != nil {
 		return "", errors.New("erro ao gerar token de acesso")
 	}
+	return accessToken, nil
+}
+
+// randomToken gera n bytes aleatórios codificados em base64 URL-safe.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	return tokenString, nil
+// hashToken retorna o SHA-256 em hexadecimal de um token opaco.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,347 @@
+// internal/core/auth/service_account.go
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidServiceAccountName é retornado quando o nome não segue usernamePattern.
+	ErrInvalidServiceAccountName = errors.New("nome de conta de serviço inválido: use de 3 a 64 caracteres entre letras, números, '.', '_', '-' e '@'")
+	// ErrServiceAccountAdminRole é retornado ao conceder a role admin a uma conta de serviço.
+	ErrServiceAccountAdminRole = errors.New("contas de serviço não podem receber a role admin")
+	// ErrAPIKeyRoleNotGranted é retornado quando a chave pede uma role que a conta não possui.
+	ErrAPIKeyRoleNotGranted = errors.New("as roles da chave devem pertencer à conta de serviço")
+	// ErrInvalidAPIKeyExpiration é retornado quando a expiração da chave já passou.
+	ErrInvalidAPIKeyExpiration = errors.New("a expiração da chave deve ser uma data futura")
+	// ErrInvalidAPIKey é retornado na troca de uma chave inexistente, revogada ou expirada.
+	ErrInvalidAPIKey = errors.New("chave de API inválida, revogada ou expirada")
+)
+
+const (
+	// apiKeyPrefix identifica as chaves emitidas por este serviço (ex.: em scanners de segredos).
+	apiKeyPrefix = "swg_"
+	// serviceAccountSubjectPrefix separa, no claim username, as contas de serviço dos usuários;
+	// ':' não é aceito em usernames.
+	serviceAccountSubjectPrefix = "svc:"
+)
+
+// ServiceAccountInfo é a visão de uma conta de serviço exposta pela API de administração.
+type ServiceAccountInfo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Roles       []string  `json:"roles"`
+	Disabled    bool      `json:"disabled"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// APIKeyInfo é a visão de uma chave de API, sem o segredo.
+type APIKeyInfo struct {
+	ID         string     `json:"id"`
+	Account    string     `json:"account"`
+	Roles      []string   `json:"roles"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Revoked    bool       `json:"revoked"`
+}
+
+// CreatedAPIKey é retornado na criação da chave; Key só é exibida nesse momento.
+type CreatedAPIKey struct {
+	APIKeyInfo
+	Key string `json:"key"`
+}
+
+// CreateServiceAccountInput contém os dados para criar uma conta de serviço.
+type CreateServiceAccountInput struct {
+	Name        string
+	Description string
+	Roles       []string
+}
+
+// UpdateServiceAccountInput contém as alterações de uma conta de serviço; campos nil são mantidos.
+type UpdateServiceAccountInput struct {
+	Description *string
+	Roles       *[]string
+	Disabled    *bool
+}
+
+// CreateAPIKeyInput contém as opções de uma nova chave. Roles nil concede todas as roles
+// da conta; ExpiresAt nil cria uma chave sem expiração.
+type CreateAPIKeyInput struct {
+	Roles     []string
+	ExpiresAt *time.Time
+}
+
+// ServiceAccountService define a administração de contas de serviço e chaves de API.
+type ServiceAccountService interface {
+	ListAccounts(ctx context.Context) ([]ServiceAccountInfo, error)
+	GetAccount(ctx context.Context, name string) (*ServiceAccountInfo, error)
+	CreateAccount(ctx context.Context, input CreateServiceAccountInput) (*ServiceAccountInfo, error)
+	UpdateAccount(ctx context.Context, name string, input UpdateServiceAccountInput) (*ServiceAccountInfo, error)
+	DeleteAccount(ctx context.Context, name string) error
+	ListKeys(ctx context.Context, account string) ([]APIKeyInfo, error)
+	CreateKey(ctx context.Context, account string, input CreateAPIKeyInput) (*CreatedAPIKey, error)
+	RevokeKey(ctx context.Context, account, id string) error
+}
+
+type serviceAccountService struct {
+	accounts   ServiceAccountStore
+	audit      auditor
+	roleGroups RoleGroups
+}
+
+// NewServiceAccountService cria o serviço de administração de contas de serviço.
+func NewServiceAccountService(accounts ServiceAccountStore, audit AuditStore, roleGroups RoleGroups) ServiceAccountService {
+	return &serviceAccountService{accounts: accounts, audit: auditor{store: audit}, roleGroups: roleGroups}
+}
+
+func (s *serviceAccountService) ListAccounts(ctx context.Context) ([]ServiceAccountInfo, error) {
+	accounts, err := s.accounts.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ServiceAccountInfo, 0, len(accounts))
+	for _, account := range accounts {
+		infos = append(infos, *toServiceAccountInfo(&account))
+	}
+	return infos, nil
+}
+
+func (s *serviceAccountService) GetAccount(ctx context.Context, name string) (*ServiceAccountInfo, error) {
+	account, err := s.accounts.FindAccount(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return toServiceAccountInfo(account), nil
+}
+
+func (s *serviceAccountService) CreateAccount(ctx context.Context, input CreateServiceAccountInput) (*ServiceAccountInfo, error) {
+	if !usernamePattern.MatchString(input.Name) {
+		return nil, ErrInvalidServiceAccountName
+	}
+	roles, err := s.serviceAccountRoles(input.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &ServiceAccount{
+		Name:        input.Name,
+		Description: input.Description,
+		Roles:       roles,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.accounts.CreateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditServiceAccountCreate, serviceAccountSubject(account.Name), true, "roles: "+formatRoles(roles))
+	return toServiceAccountInfo(account), nil
+}
+
+func (s *serviceAccountService) UpdateAccount(ctx context.Context, name string, input UpdateServiceAccountInput) (*ServiceAccountInfo, error) {
+	account, err := s.accounts.FindAccount(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if input.Description != nil {
+		account.Description = *input.Description
+	}
+	previousRoles := account.Roles
+	if input.Roles != nil {
+		roles, err := s.serviceAccountRoles(*input.Roles)
+		if err != nil {
+			return nil, err
+		}
+		account.Roles = roles
+	}
+	if input.Disabled != nil {
+		account.Disabled = *input.Disabled
+	}
+	if err := s.accounts.UpdateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	subject := serviceAccountSubject(account.Name)
+	if input.Roles != nil {
+		s.audit.record(ctx, AuditServiceAccountRoles, subject, true, formatRoles(previousRoles)+" -> "+formatRoles(account.Roles))
+	}
+	if input.Disabled != nil {
+		detail := "reativada"
+		if *input.Disabled {
+			detail = "desativada"
+		}
+		s.audit.record(ctx, AuditServiceAccountUpdate, subject, true, detail)
+	}
+	return toServiceAccountInfo(account), nil
+}
+
+func (s *serviceAccountService) DeleteAccount(ctx context.Context, name string) error {
+	if err := s.accounts.DeleteAccount(ctx, name); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditServiceAccountDelete, serviceAccountSubject(name), true, "")
+	return nil
+}
+
+func (s *serviceAccountService) ListKeys(ctx context.Context, account string) ([]APIKeyInfo, error) {
+	if _, err := s.accounts.FindAccount(ctx, account); err != nil {
+		return nil, err
+	}
+	keys, err := s.accounts.ListKeys(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]APIKeyInfo, 0, len(keys))
+	for _, key := range keys {
+		infos = append(infos, *toAPIKeyInfo(&key))
+	}
+	return infos, nil
+}
+
+func (s *serviceAccountService) CreateKey(ctx context.Context, account string, input CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	owner, err := s.accounts.FindAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := owner.Roles
+	if input.Roles != nil {
+		roles, err = s.roleGroups.normalize(input.Roles)
+		if err != nil {
+			return nil, err
+		}
+		granted := s.roleGroups.resolve(owner.Roles)
+		for _, role := range s.roleGroups.resolve(roles) {
+			if !containsRole(granted, role) {
+				return nil, ErrAPIKeyRoleNotGranted
+			}
+		}
+	}
+	now := time.Now()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, ErrInvalidAPIKeyExpiration
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := &APIKey{
+		ID:         hex.EncodeToString(idBytes),
+		Account:    owner.Name,
+		SecretHash: hashToken(secret),
+		Roles:      roles,
+		CreatedAt:  now,
+		ExpiresAt:  input.ExpiresAt,
+	}
+	if err := s.accounts.SaveKey(ctx, key); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditAPIKeyCreate, serviceAccountSubject(owner.Name), true, "chave "+key.ID+", roles: "+formatRoles(roles))
+	return &CreatedAPIKey{APIKeyInfo: *toAPIKeyInfo(key), Key: apiKeyPrefix + key.ID + "_" + secret}, nil
+}
+
+func (s *serviceAccountService) RevokeKey(ctx context.Context, account, id string) error {
+	key, err := s.accounts.FindKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.Account != account {
+		return ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := s.accounts.SaveKey(ctx, key); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditAPIKeyRevoke, serviceAccountSubject(account), true, "chave "+id)
+	return nil
+}
+
+// parseAPIKey separa o id e o segredo de uma chave no formato swg_<id>_<segredo>.
+func parseAPIKey(apiKey string) (id, secret string, ok bool) {
+	rest, found := strings.CutPrefix(apiKey, apiKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found = strings.Cut(rest, "_")
+	if !found || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// serviceAccountSubject é o valor do claim username nos tokens de uma conta de serviço.
+func serviceAccountSubject(name string) string {
+	return serviceAccountSubjectPrefix + name
+}
+
+// IsServiceAccount informa se o subject de um token pertence a uma conta de serviço.
+func IsServiceAccount(subject string) bool {
+	return strings.HasPrefix(subject, serviceAccountSubjectPrefix)
+}
+
+// serviceAccountRoles valida as roles de uma conta de serviço.
+func (s *serviceAccountService) serviceAccountRoles(roles []string) ([]string, error) {
+	normalized, err := s.roleGroups.normalize(roles)
+	if err != nil {
+		return nil, err
+	}
+	if containsRole(s.roleGroups.resolve(normalized), RoleAdmin) {
+		return nil, ErrServiceAccountAdminRole
+	}
+	return normalized, nil
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func toServiceAccountInfo(account *ServiceAccount) *ServiceAccountInfo {
+	roles := account.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return &ServiceAccountInfo{
+		Name:        account.Name,
+		Description: account.Description,
+		Roles:       roles,
+		Disabled:    account.Disabled,
+		CreatedAt:   account.CreatedAt,
+	}
+}
+
+func toAPIKeyInfo(key *APIKey) *APIKeyInfo {
+	roles := key.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return &APIKeyInfo{
+		ID:         key.ID,
+		Account:    key.Account,
+		Roles:      roles,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		Revoked:    key.RevokedAt != nil,
+	}
+}
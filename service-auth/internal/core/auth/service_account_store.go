@@ -0,0 +1,58 @@
+// internal/core/auth/service_account_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrServiceAccountNotFound é retornado quando a conta de serviço não existe.
+	ErrServiceAccountNotFound = errors.New("conta de serviço não encontrada")
+	// ErrServiceAccountExists é retornado ao criar uma conta de serviço com nome já usado.
+	ErrServiceAccountExists = errors.New("conta de serviço já existe")
+	// ErrAPIKeyNotFound é retornado quando a chave de API não existe.
+	ErrAPIKeyNotFound = errors.New("chave de API não encontrada")
+)
+
+// ServiceAccount representa um cliente de máquina (scripts, integrações) com as roles
+// que suas chaves de API podem receber.
+type ServiceAccount struct {
+	Name        string    `firestore:"name" json:"name"`
+	Description string    `firestore:"description" json:"description,omitempty"`
+	Roles       []string  `firestore:"roles" json:"roles"`
+	Disabled    bool      `firestore:"disabled" json:"disabled"`
+	CreatedAt   time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+// APIKey é uma chave de longa duração de uma conta de serviço. Apenas o hash SHA-256 do
+// segredo é armazenado; Roles é um subconjunto das roles da conta.
+type APIKey struct {
+	ID         string     `firestore:"id" json:"id"`
+	Account    string     `firestore:"account" json:"account"`
+	SecretHash string     `firestore:"secretHash" json:"secretHash"`
+	Roles      []string   `firestore:"roles" json:"roles"`
+	CreatedAt  time.Time  `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt  *time.Time `firestore:"expiresAt" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `firestore:"lastUsedAt" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `firestore:"revokedAt" json:"revokedAt,omitempty"`
+}
+
+// ServiceAccountStore abstrai o armazenamento das contas de serviço e de suas chaves de API.
+type ServiceAccountStore interface {
+	ListAccounts(ctx context.Context) ([]ServiceAccount, error)
+	FindAccount(ctx context.Context, name string) (*ServiceAccount, error)
+	CreateAccount(ctx context.Context, account *ServiceAccount) error
+	UpdateAccount(ctx context.Context, account *ServiceAccount) error
+	// DeleteAccount remove a conta e todas as suas chaves.
+	DeleteAccount(ctx context.Context, name string) error
+
+	ListKeys(ctx context.Context, account string) ([]APIKey, error)
+	FindKey(ctx context.Context, id string) (*APIKey, error)
+	// SaveKey cria ou substitui a chave identificada por key.ID.
+	SaveKey(ctx context.Context, key *APIKey) error
+	// TouchKey grava apenas o último uso da chave, sem sobrescrever os demais campos
+	// (ex.: uma revogação feita em paralelo).
+	TouchKey(ctx context.Context, id string, usedAt time.Time) error
+}
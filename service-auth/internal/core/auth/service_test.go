@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testClient = ClientInfo{IP: "203.0.113.10", UserAgent: "go-test"}
+
+// testOptions ajusta o serviço criado por newTestService; valores zerados usam os padrões.
+type testOptions struct {
+	throttle ThrottleConfig
+	oidc     OIDCConfig
+	ldap     *LDAPConfig
+	policy   PasswordPolicy
+}
+
+// newTestStores monta os armazenamentos do modo "file" em um diretório temporário, com o
+// repositório de usuários em arquivo iniciado com users. Retorna também o caminho do
+// arquivo de usuários, para que os testes o releiam.
+func newTestStores(t *testing.T, users ...User) (Stores, string) {
+	t.Helper()
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.json")
+	repo, err := NewFileUserRepository(usersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range users {
+		if err := repo.Create(context.Background(), &user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Stores{
+		Users:           repo,
+		RefreshTokens:   NewMemoryRefreshTokenStore(),
+		Revocations:     NewMemoryRevocationStore(),
+		SigningKeys:     NewFileKeyStore(filepath.Join(dir, "signing_keys.json")),
+		PasswordResets:  NewMemoryPasswordResetStore(),
+		LoginAttempts:   NewMemoryLoginAttemptStore(),
+		MFAChallenges:   NewMemoryMFAChallengeStore(),
+		ServiceAccounts: NewFileServiceAccountStore(filepath.Join(dir, "service_accounts.json")),
+		AuditEvents:     NewMemoryAuditStore(),
+		Tenants:         NewFileTenantStore(filepath.Join(dir, "tenants.json")),
+		OIDCStates:      NewMemoryOIDCStateStore(),
+		Sessions:        NewMemorySessionStore(),
+	}, usersFile
+}
+
+func newTestService(t *testing.T, stores Stores, options testOptions) Service {
+	t.Helper()
+	keys, err := NewKeyManager(context.Background(), stores.SigningKeys, KeyConfig{Algorithm: AlgorithmEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.policy.BcryptCost == 0 {
+		options.policy.BcryptCost = bcrypt.MinCost
+	}
+	// Backoff curto, para que os testes possam repetir tentativas após uma falha.
+	if options.throttle.BaseDelay == 0 {
+		options.throttle.BaseDelay, options.throttle.MaxDelay = time.Millisecond, time.Millisecond
+	}
+	return NewService(stores, keys, TokenConfig{}, options.throttle, options.oidc, options.ldap, nil, options.policy)
+}
+
+// testUser cria um usuário local com a senha informada, com hash de custo cost.
+func testUser(t *testing.T, username, password string, cost int) User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return User{Username: username, PasswordHash: string(hash), Roles: []string{"analise-icms"}, Email: username + "@exemplo.local"}
+}
+
+// waitBackoff espera o backoff de newTestService após uma falha de login.
+func waitBackoff() {
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestLoginIssuesTokens(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{})
+	ctx := context.Background()
+
+	result, err := service.Login(ctx, "maria", "senha-forte", testClient)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if result.Tokens == nil || result.Challenge != nil {
+		t.Fatalf("esperado par de tokens sem desafio, obtido %+v", result)
+	}
+	claims, err := service.ValidateAccessToken(ctx, result.Tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.Username != "maria" || len(claims.Roles) != 1 || claims.Roles[0] != "analise-icms" {
+		t.Errorf("claims = %+v, esperado maria com a role analise-icms", claims)
+	}
+	if _, err := service.Refresh(ctx, result.Tokens.RefreshToken); err != nil {
+		t.Errorf("Refresh: %v", err)
+	}
+}
+
+func TestLoginRejectsInvalidCredentials(t *testing.T) {
+	disabled := testUser(t, "joao", "senha-forte", bcrypt.MinCost)
+	disabled.Disabled = true
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost), disabled)
+	service := newTestService(t, stores, testOptions{})
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     error
+	}{
+		// Usuário inexistente e senha errada têm o mesmo erro, para não revelar quem existe.
+		{"senha incorreta", "maria", "outra-senha", errInvalidCredentials},
+		{"usuário inexistente", "ana", "senha-forte", errInvalidCredentials},
+		{"usuário desativado", "joao", "senha-forte", ErrUserDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waitBackoff()
+			result, err := service.Login(context.Background(), tt.username, tt.password, testClient)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Login = %+v, %v; esperado %v", result, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginLocksOutAfterRepeatedFailures(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{throttle: ThrottleConfig{MaxUserFailures: 3}})
+	ctx := context.Background()
+
+	for i := range 3 {
+		// Sem esperar o backoff da falha anterior, a tentativa seria recusada antes da senha.
+		waitBackoff()
+		if _, err := service.Login(ctx, "maria", "outra-senha", testClient); !errors.Is(err, errInvalidCredentials) {
+			t.Fatalf("tentativa %d: %v, esperado %v", i+1, err, errInvalidCredentials)
+		}
+	}
+
+	waitBackoff()
+	_, err := service.Login(ctx, "maria", "senha-forte", testClient)
+	var tooMany *TooManyAttemptsError
+	if !errors.As(err, &tooMany) {
+		t.Fatalf("Login após o bloqueio: %v, esperado TooManyAttemptsError", err)
+	}
+	if tooMany.RetryAfter <= 0 {
+		t.Errorf("RetryAfter = %v, esperado positivo", tooMany.RetryAfter)
+	}
+}
+
+func TestLoginRehashesOnlyThePasswordHash(t *testing.T) {
+	user := testUser(t, "maria", "senha-forte", bcrypt.MinCost)
+	stores, usersFile := newTestStores(t, user)
+	service := newTestService(t, stores, testOptions{policy: PasswordPolicy{BcryptCost: bcrypt.MinCost + 1}})
+	ctx := context.Background()
+
+	if _, err := service.Login(ctx, "maria", "senha-forte", testClient); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	// Relê o arquivo, para conferir o que foi de fato gravado.
+	repo, err := NewFileUserRepository(usersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.FindByUsername(ctx, "maria")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, err := bcrypt.Cost([]byte(stored.PasswordHash)); err != nil || cost != bcrypt.MinCost+1 {
+		t.Errorf("custo do hash gravado = %d (%v), esperado %d", cost, err, bcrypt.MinCost+1)
+	}
+	if stored.Email != user.Email || len(stored.Roles) != 1 || stored.Roles[0] != "analise-icms" {
+		t.Errorf("usuário gravado = %+v, esperado apenas o hash alterado", stored)
+	}
+	if _, err := service.Login(ctx, "maria", "senha-forte", testClient); err != nil {
+		t.Errorf("Login com o novo hash: %v", err)
+	}
+}
+
+func TestLoginWithTOTPRequiresSecondFactor(t *testing.T) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := testUser(t, "maria", "senha-forte", bcrypt.MinCost)
+	user.TOTPSecret = secret
+	user.TOTPEnabled = true
+	stores, _ := newTestStores(t, user)
+	service := newTestService(t, stores, testOptions{})
+	ctx := context.Background()
+
+	result, err := service.Login(ctx, "maria", "senha-forte", testClient)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if result.Tokens != nil || result.Challenge == nil || !result.Challenge.MFARequired {
+		t.Fatalf("esperado desafio de 2FA sem tokens, obtido %+v", result)
+	}
+
+	step := time.Now().Unix() / totpPeriod
+	expired, err := totpCode(secret, step-10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.CompleteMFA(ctx, result.Challenge.ChallengeToken, expired, testClient); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("CompleteMFA com código expirado: %v, esperado %v", err, ErrInvalidOTP)
+	}
+	code, err := totpCode(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitBackoff()
+	tokens, err := service.CompleteMFA(ctx, result.Challenge.ChallengeToken, code, testClient)
+	if err != nil {
+		t.Fatalf("CompleteMFA: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Errorf("tokens = %+v, esperado o par completo", tokens)
+	}
+	if _, err := service.CompleteMFA(ctx, result.Challenge.ChallengeToken, code, testClient); !errors.Is(err, ErrInvalidMFAChallenge) {
+		t.Errorf("desafio reutilizado: %v, esperado %v", err, ErrInvalidMFAChallenge)
+	}
+}
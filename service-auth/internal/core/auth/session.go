@@ -0,0 +1,235 @@
+// internal/core/auth/session.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// sessionRevocationPrefix distingue, na lista de revogação, as sessões encerradas dos jti;
+// ':' não ocorre nos jti, que são base64 URL-safe.
+const sessionRevocationPrefix = "sid:"
+
+// errSessionEnded é retornado por touch quando a sessão foi encerrada durante um refresh.
+var errSessionEnded = errors.New("sessão encerrada")
+
+// SessionInfo é a visão de uma sessão ativa exposta pela API. Current indica a sessão do
+// access token usado na consulta.
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Tenant     string    `json:"tenant,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// SessionService lista e encerra as sessões de um usuário. Encerrar uma sessão revoga sua
+// família de refresh tokens e os access tokens já emitidos para ela.
+type SessionService interface {
+	// List retorna as sessões ativas do usuário, da usada mais recentemente para a mais antiga.
+	List(ctx context.Context, username, currentID string) ([]SessionInfo, error)
+	Revoke(ctx context.Context, username, id string) error
+	// RevokeAll encerra todas as sessões ativas do usuário, exceto keepID (se informado), e
+	// retorna quantas foram encerradas.
+	RevokeAll(ctx context.Context, username, keepID string) (int, error)
+}
+
+type sessionService struct {
+	sessions       SessionStore
+	refreshTokens  RefreshTokenStore
+	revocations    RevocationStore
+	audit          auditor
+	accessTokenTTL time.Duration
+}
+
+// NewSessionService cria o serviço de sessões. accessTokenTTL deve ser o mesmo do serviço de
+// autenticação: é por quanto tempo a sessão encerrada fica na lista de revogação.
+func NewSessionService(sessions SessionStore, refreshTokens RefreshTokenStore, revocations RevocationStore, audit AuditStore, accessTokenTTL time.Duration) SessionService {
+	return newSessionService(sessions, refreshTokens, revocations, audit, accessTokenTTL)
+}
+
+func newSessionService(sessions SessionStore, refreshTokens RefreshTokenStore, revocations RevocationStore, audit AuditStore, accessTokenTTL time.Duration) *sessionService {
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+	return &sessionService{
+		sessions:       sessions,
+		refreshTokens:  refreshTokens,
+		revocations:    revocations,
+		audit:          auditor{store: audit},
+		accessTokenTTL: accessTokenTTL,
+	}
+}
+
+func (s *sessionService) List(ctx context.Context, username, currentID string) ([]SessionInfo, error) {
+	sessions, err := s.active(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, SessionInfo{
+			ID:         session.ID,
+			Tenant:     session.Tenant,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentID != "" && session.ID == currentID,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastUsedAt.After(infos[j].LastUsedAt)
+	})
+	return infos, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, username, id string) error {
+	session, err := s.sessions.Find(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if session.Username != username || !session.active(time.Now()) {
+		return ErrSessionNotFound
+	}
+	if err := s.end(ctx, id); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditSessionRevoke, username, true, "sessão "+id)
+	return nil
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, username, keepID string) (int, error) {
+	sessions, err := s.active(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for i := range sessions {
+		if sessions[i].ID == keepID {
+			continue
+		}
+		if err := s.end(ctx, sessions[i].ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	if revoked > 0 {
+		s.audit.record(ctx, AuditSessionRevoke, username, true, "todas as sessões")
+	}
+	return revoked, nil
+}
+
+// active retorna as sessões não encerradas e não expiradas do usuário.
+func (s *sessionService) active(ctx context.Context, username string) ([]Session, error) {
+	sessions, err := s.sessions.ListByUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := sessions[:0]
+	for _, session := range sessions {
+		if session.active(now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// touch registra o uso da sessão na emissão de um par de tokens, criando-a no primeiro uso.
+// A origem é a da requisição anexada ao contexto. Retorna errSessionEnded, sem gravar nada,
+// se a sessão já foi encerrada.
+func (s *sessionService) touch(ctx context.Context, id string, user *User, tenant string, now, expiresAt time.Time) error {
+	client := clientInfoFrom(ctx)
+	return s.sessions.Update(ctx, id, func(session *Session) error {
+		if session.RevokedAt != nil {
+			return errSessionEnded
+		}
+		if session.CreatedAt.IsZero() {
+			session.Username = user.Username
+			session.CreatedAt = now
+		}
+		session.Tenant = tenant
+		session.LastUsedAt = now
+		session.ExpiresAt = expiresAt
+		if client.IP != "" {
+			session.IP = client.IP
+		}
+		if client.UserAgent != "" {
+			session.UserAgent = client.UserAgent
+		}
+		return nil
+	})
+}
+
+// ended informa se a sessão existe e já foi encerrada.
+func (s *sessionService) ended(ctx context.Context, id string) (bool, error) {
+	session, err := s.sessions.Find(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return session.RevokedAt != nil, nil
+}
+
+// endByID encerra a sessão informada, se ela existir e ainda estiver ativa.
+func (s *sessionService) endByID(ctx context.Context, id string) error {
+	session, err := s.sessions.Find(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	return s.end(ctx, id)
+}
+
+// end marca a sessão como encerrada e então revoga sua família de refresh tokens e, até o
+// último access token emitido expirar, todos os access tokens com o seu sid. A marca vem
+// primeiro: a partir dela touch recusa a sessão, então um refresh concorrente ou falha ou
+// grava seu refresh token antes do RevokeFamily.
+func (s *sessionService) end(ctx context.Context, id string) error {
+	now := time.Now()
+	err := s.sessions.Update(ctx, id, func(session *Session) error {
+		if session.CreatedAt.IsZero() {
+			return ErrSessionNotFound
+		}
+		if session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.refreshTokens.RevokeFamily(ctx, id); err != nil {
+		return err
+	}
+	return s.revocations.Revoke(ctx, sessionRevocationPrefix+id, now.Add(s.accessTokenTTL))
+}
+
+// isRevoked informa se a sessão foi encerrada enquanto ainda pode haver access tokens válidos.
+func (s *sessionService) isRevoked(ctx context.Context, id string) (bool, error) {
+	return s.revocations.IsRevoked(ctx, sessionRevocationPrefix+id)
+}
+
+func (session *Session) active(now time.Time) bool {
+	return session.RevokedAt == nil && now.Before(session.ExpiresAt)
+}
@@ -0,0 +1,37 @@
+// internal/core/auth/session_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSessionNotFound é retornado quando a sessão não existe, é de outro usuário ou já foi encerrada.
+var ErrSessionNotFound = errors.New("sessão não encontrada")
+
+// Session é um login de usuário em um dispositivo: a família de refresh tokens iniciada no
+// login, cujo FamilyID é o ID da sessão e vai no claim sid dos access tokens. IP, UserAgent
+// e LastUsedAt são atualizados a cada refresh; ExpiresAt acompanha o último refresh token.
+type Session struct {
+	ID         string     `firestore:"id" json:"id"`
+	Username   string     `firestore:"username" json:"username"`
+	Tenant     string     `firestore:"tenant" json:"tenant,omitempty"`
+	IP         string     `firestore:"ip" json:"ip,omitempty"`
+	UserAgent  string     `firestore:"userAgent" json:"userAgent,omitempty"`
+	CreatedAt  time.Time  `firestore:"createdAt" json:"createdAt"`
+	LastUsedAt time.Time  `firestore:"lastUsedAt" json:"lastUsedAt"`
+	ExpiresAt  time.Time  `firestore:"expiresAt" json:"expiresAt"`
+	RevokedAt  *time.Time `firestore:"revokedAt" json:"revokedAt,omitempty"`
+}
+
+// SessionStore abstrai o armazenamento das sessões.
+type SessionStore interface {
+	// Update aplica a alteração de forma atômica e grava o resultado. Uma sessão inexistente
+	// chega a change apenas com o ID preenchido. Se change retornar erro, nada é gravado e o
+	// erro é devolvido.
+	Update(ctx context.Context, id string, change func(session *Session) error) error
+	Find(ctx context.Context, id string) (*Session, error)
+	// ListByUser retorna todas as sessões gravadas do usuário, inclusive as encerradas.
+	ListByUser(ctx context.Context, username string) ([]Session, error)
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSessionTouchRefusesEndedSession(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	svc := newTestService(t, stores, testOptions{}).(*service)
+	ctx := context.Background()
+
+	result, err := svc.Login(ctx, "maria", "senha-forte", testClient)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	claims, err := svc.ValidateAccessToken(ctx, result.Tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Sessions().Revoke(ctx, "maria", claims.SessionID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	sessions := svc.sessions
+	user := &User{Username: "maria"}
+	now := time.Now()
+	if err := sessions.touch(ctx, claims.SessionID, user, "", now, now.Add(time.Hour)); !errors.Is(err, errSessionEnded) {
+		t.Fatalf("touch após o encerramento: %v, esperado %v", err, errSessionEnded)
+	}
+	stored, err := stores.Sessions.Find(ctx, claims.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.RevokedAt == nil || stored.active(now) {
+		t.Errorf("sessão = %+v, esperada continuar encerrada", stored)
+	}
+	if _, err := svc.issueTokens(ctx, user, claims.SessionID, ""); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("issueTokens para a sessão encerrada: %v, esperado %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestSessionRevokeDuringRefreshLeavesNoValidToken(t *testing.T) {
+	stores, _ := newTestStores(t, testUser(t, "maria", "senha-forte", bcrypt.MinCost))
+	service := newTestService(t, stores, testOptions{})
+	ctx := context.Background()
+
+	for range 20 {
+		result, err := service.Login(ctx, "maria", "senha-forte", testClient)
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		claims, err := service.ValidateAccessToken(ctx, result.Tokens.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Renova em sequência enquanto a sessão é encerrada; o último token obtido não pode
+		// continuar valendo depois do encerramento.
+		var wg sync.WaitGroup
+		latest := result.Tokens.RefreshToken
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				tokens, err := service.Refresh(ctx, latest)
+				if err != nil {
+					return
+				}
+				latest = tokens.RefreshToken
+			}
+		}()
+		if err := service.Sessions().Revoke(ctx, "maria", claims.SessionID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			t.Fatalf("Revoke: %v", err)
+		}
+		wg.Wait()
+
+		if _, err := service.Refresh(ctx, latest); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Fatalf("Refresh após o encerramento: %v, esperado %v", err, ErrInvalidRefreshToken)
+		}
+	}
+}
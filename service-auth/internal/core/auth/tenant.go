@@ -0,0 +1,291 @@
+// internal/core/auth/tenant.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidTenantID é retornado quando o identificador não segue tenantIDPattern.
+	ErrInvalidTenantID = errors.New("identificador de empresa inválido: use de 2 a 64 caracteres entre letras minúsculas, números e '-'")
+	// ErrInvalidTenantName é retornado quando o nome da empresa está vazio.
+	ErrInvalidTenantName = errors.New("o nome da empresa não pode ser vazio")
+	// ErrInvalidCNPJ é retornado quando o CNPJ não tem 14 dígitos ou os dígitos verificadores não conferem.
+	ErrInvalidCNPJ = errors.New("CNPJ inválido")
+	// ErrTenantNotMember é retornado ao selecionar uma empresa da qual o usuário não participa.
+	ErrTenantNotMember = errors.New("o usuário não tem acesso a esta empresa")
+	// ErrTenantDisabled é retornado ao selecionar uma empresa desativada.
+	ErrTenantDisabled = errors.New("empresa desativada")
+)
+
+// tenantIDPattern define o identificador da empresa, usado no claim tenant e nas URLs.
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,63}$`)
+
+// TenantInfo é a visão de uma empresa cliente exposta pela API.
+type TenantInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CNPJ      string    `json:"cnpj,omitempty"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateTenantInput contém os dados para cadastrar uma empresa cliente.
+type CreateTenantInput struct {
+	ID   string
+	Name string
+	CNPJ string
+}
+
+// UpdateTenantInput contém as alterações de uma empresa cliente; campos nil são mantidos.
+type UpdateTenantInput struct {
+	Name     *string
+	CNPJ     *string
+	Disabled *bool
+}
+
+// TenantService define o cadastro de empresas clientes e a consulta das empresas de um usuário.
+type TenantService interface {
+	List(ctx context.Context) ([]TenantInfo, error)
+	Get(ctx context.Context, id string) (*TenantInfo, error)
+	Create(ctx context.Context, input CreateTenantInput) (*TenantInfo, error)
+	Update(ctx context.Context, id string, input UpdateTenantInput) (*TenantInfo, error)
+	// Delete remove a empresa; os vínculos dos usuários com ela passam a ser ignorados.
+	Delete(ctx context.Context, id string) error
+	// ListForUser retorna as empresas ativas das quais o usuário participa, na ordem de User.Tenants.
+	ListForUser(ctx context.Context, username string) ([]TenantInfo, error)
+}
+
+type tenantService struct {
+	tenants TenantStore
+	users   UserRepository
+	audit   auditor
+}
+
+// NewTenantService cria o serviço de empresas clientes.
+func NewTenantService(tenants TenantStore, users UserRepository, audit AuditStore) TenantService {
+	return &tenantService{tenants: tenants, users: users, audit: auditor{store: audit}}
+}
+
+func (s *tenantService) List(ctx context.Context) ([]TenantInfo, error) {
+	tenants, err := s.tenants.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TenantInfo, 0, len(tenants))
+	for _, tenant := range tenants {
+		infos = append(infos, *toTenantInfo(&tenant))
+	}
+	return infos, nil
+}
+
+func (s *tenantService) Get(ctx context.Context, id string) (*TenantInfo, error) {
+	tenant, err := s.tenants.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toTenantInfo(tenant), nil
+}
+
+func (s *tenantService) Create(ctx context.Context, input CreateTenantInput) (*TenantInfo, error) {
+	if !tenantIDPattern.MatchString(input.ID) {
+		return nil, ErrInvalidTenantID
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrInvalidTenantName
+	}
+	cnpj, err := normalizeCNPJ(input.CNPJ)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := &Tenant{ID: input.ID, Name: name, CNPJ: cnpj, CreatedAt: time.Now()}
+	if err := s.tenants.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditTenantCreate, "", true, "empresa "+tenant.ID)
+	return toTenantInfo(tenant), nil
+}
+
+func (s *tenantService) Update(ctx context.Context, id string, input UpdateTenantInput) (*TenantInfo, error) {
+	tenant, err := s.tenants.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrInvalidTenantName
+		}
+		tenant.Name = name
+	}
+	if input.CNPJ != nil {
+		cnpj, err := normalizeCNPJ(*input.CNPJ)
+		if err != nil {
+			return nil, err
+		}
+		tenant.CNPJ = cnpj
+	}
+	if input.Disabled != nil {
+		tenant.Disabled = *input.Disabled
+	}
+	if err := s.tenants.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	detail := "empresa " + tenant.ID
+	if input.Disabled != nil {
+		if *input.Disabled {
+			detail += " desativada"
+		} else {
+			detail += " reativada"
+		}
+	}
+	s.audit.record(ctx, AuditTenantUpdate, "", true, detail)
+	return toTenantInfo(tenant), nil
+}
+
+func (s *tenantService) Delete(ctx context.Context, id string) error {
+	if err := s.tenants.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditTenantDelete, "", true, "empresa "+id)
+	return nil
+}
+
+func (s *tenantService) ListForUser(ctx context.Context, username string) ([]TenantInfo, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TenantInfo, 0, len(user.Tenants))
+	for _, id := range user.Tenants {
+		tenant, err := s.tenants.Find(ctx, id)
+		if errors.Is(err, ErrTenantNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !tenant.Disabled {
+			infos = append(infos, *toTenantInfo(tenant))
+		}
+	}
+	return infos, nil
+}
+
+// memberTenant verifica se o usuário pode trabalhar na empresa informada.
+func memberTenant(ctx context.Context, tenants TenantStore, user *User, id string) error {
+	if !containsTenant(user.Tenants, id) {
+		return ErrTenantNotMember
+	}
+	tenant, err := tenants.Find(ctx, id)
+	if errors.Is(err, ErrTenantNotFound) {
+		return ErrTenantNotMember
+	}
+	if err != nil {
+		return err
+	}
+	if tenant.Disabled {
+		return ErrTenantDisabled
+	}
+	return nil
+}
+
+// defaultTenant retorna a primeira empresa ativa de User.Tenants, ou "" se não houver nenhuma.
+func defaultTenant(ctx context.Context, tenants TenantStore, user *User) (string, error) {
+	for _, id := range user.Tenants {
+		err := memberTenant(ctx, tenants, user, id)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, ErrTenantNotMember) && !errors.Is(err, ErrTenantDisabled) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// normalizeTenants valida as empresas de um usuário, removendo repetições e mantendo a
+// ordem: a primeira é a empresa padrão no login.
+func normalizeTenants(ctx context.Context, tenants TenantStore, ids []string) ([]string, error) {
+	normalized := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if containsTenant(normalized, id) {
+			continue
+		}
+		if _, err := tenants.Find(ctx, id); err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, id)
+	}
+	return normalized, nil
+}
+
+func containsTenant(tenants []string, id string) bool {
+	for _, t := range tenants {
+		if t == id {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCNPJ aceita o CNPJ com ou sem pontuação e o retorna apenas com os dígitos.
+// CNPJ vazio é aceito (empresa sem CNPJ cadastrado).
+func normalizeCNPJ(cnpj string) (string, error) {
+	if strings.TrimSpace(cnpj) == "" {
+		return "", nil
+	}
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == '.' || r == '/' || r == '-' || r == ' ':
+			return -1
+		default:
+			return 'x'
+		}
+	}, cnpj)
+	if len(digits) != 14 || strings.Contains(digits, "x") || strings.Count(digits, digits[:1]) == 14 {
+		return "", ErrInvalidCNPJ
+	}
+	if cnpjCheckDigit(digits[:12]) != digits[12] || cnpjCheckDigit(digits[:13]) != digits[13] {
+		return "", ErrInvalidCNPJ
+	}
+	return digits, nil
+}
+
+// cnpjCheckDigit calcula o dígito verificador (módulo 11) dos dígitos informados.
+func cnpjCheckDigit(digits string) byte {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+func toTenantInfo(tenant *Tenant) *TenantInfo {
+	return &TenantInfo{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		CNPJ:      tenant.CNPJ,
+		Disabled:  tenant.Disabled,
+		CreatedAt: tenant.CreatedAt,
+	}
+}
@@ -0,0 +1,35 @@
+// internal/core/auth/tenant_store.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTenantNotFound é retornado quando a empresa cliente não existe.
+	ErrTenantNotFound = errors.New("empresa cliente não encontrada")
+	// ErrTenantExists é retornado ao criar uma empresa cliente com identificador já usado.
+	ErrTenantExists = errors.New("empresa cliente já existe")
+)
+
+// Tenant representa uma empresa cliente do escritório. Os usuários só trabalham nas
+// empresas listadas em User.Tenants.
+type Tenant struct {
+	ID        string    `firestore:"id" json:"id"`
+	Name      string    `firestore:"name" json:"name"`
+	CNPJ      string    `firestore:"cnpj" json:"cnpj,omitempty"`
+	Disabled  bool      `firestore:"disabled" json:"disabled"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+// TenantStore abstrai o armazenamento das empresas clientes.
+type TenantStore interface {
+	List(ctx context.Context) ([]Tenant, error)
+	Find(ctx context.Context, id string) (*Tenant, error)
+	Create(ctx context.Context, tenant *Tenant) error
+	// Update substitui os dados da empresa identificada por tenant.ID.
+	Update(ctx context.Context, tenant *Tenant) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,249 @@
+// internal/core/auth/throttle.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TooManyAttemptsError é retornado pelo Login quando o username ou o IP de origem está
+// em backoff ou bloqueado. RetryAfter indica quando uma nova tentativa será aceita.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("muitas tentativas de login; tente novamente em %d segundos", retryAfterSeconds(e.RetryAfter))
+}
+
+// RetryAfterSeconds arredonda RetryAfter para cima, no formato do header Retry-After.
+func (e *TooManyAttemptsError) RetryAfterSeconds() int {
+	return retryAfterSeconds(e.RetryAfter)
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// ThrottleConfig define os limites de tentativas de login. Valores zerados usam os padrões.
+type ThrottleConfig struct {
+	// MaxUserFailures é o número de falhas consecutivas que bloqueia um username.
+	MaxUserFailures int
+	// MaxIPFailures é o número de falhas que bloqueia um IP de origem.
+	MaxIPFailures int
+	// LockoutDuration é a duração do bloqueio temporário.
+	LockoutDuration time.Duration
+	// BaseDelay é a espera após a primeira falha; dobra a cada nova falha, até MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// FailureWindow zera a contagem quando não há falhas por esse período.
+	FailureWindow time.Duration
+}
+
+const (
+	defaultMaxUserFailures = 5
+	defaultMaxIPFailures   = 50
+	defaultLockoutDuration = 15 * time.Minute
+	defaultBaseDelay       = time.Second
+	defaultMaxDelay        = 30 * time.Second
+	defaultFailureWindow   = 15 * time.Minute
+
+	// attemptPendingTimeout é o tempo após o qual uma reserva não concluída é descartada.
+	attemptPendingTimeout = time.Minute
+)
+
+// Prefixos das chaves de LoginAttemptStore.
+const (
+	userAttemptKeyPrefix = "user:"
+	ipAttemptKeyPrefix   = "ip:"
+)
+
+func userAttemptKey(username string) string { return userAttemptKeyPrefix + username }
+func ipAttemptKey(ip string) string         { return ipAttemptKeyPrefix + ip }
+
+// loginThrottle aplica backoff exponencial e bloqueio temporário por username e por IP.
+type loginThrottle struct {
+	store  LoginAttemptStore
+	config ThrottleConfig
+}
+
+func newLoginThrottle(store LoginAttemptStore, config ThrottleConfig) *loginThrottle {
+	if config.MaxUserFailures <= 0 {
+		config.MaxUserFailures = defaultMaxUserFailures
+	}
+	if config.MaxIPFailures <= 0 {
+		config.MaxIPFailures = defaultMaxIPFailures
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = defaultLockoutDuration
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaultBaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultMaxDelay
+	}
+	if config.FailureWindow <= 0 {
+		config.FailureWindow = defaultFailureWindow
+	}
+	return &loginThrottle{store: store, config: config}
+}
+
+// reserve confere o backoff e o bloqueio do username e do IP e, na mesma operação atômica
+// do armazenamento, reserva a tentativa antes da conferência da senha. Assim logins
+// paralelos não escapam dos limites: cada username tem uma tentativa em andamento por vez,
+// e as em andamento de um IP contam no seu limite de falhas. A reserva termina com
+// succeed, fail ou release.
+func (t *loginThrottle) reserve(ctx context.Context, username, ip string) (*loginAttempt, error) {
+	now := time.Now()
+	keys := t.keys(username, ip)
+	for i, key := range keys {
+		// keys[0] é o username: uma tentativa por vez; o IP aceita tantas quanto o limite.
+		limit, maxPending := t.config.MaxUserFailures, 1
+		if i > 0 {
+			limit, maxPending = t.config.MaxIPFailures, t.config.MaxIPFailures
+		}
+		err := t.store.Update(ctx, key, func(attempts *LoginAttempts) error {
+			t.expire(attempts, now)
+			wait := t.retryAfter(attempts, now)
+			if wait == 0 && (attempts.Pending >= maxPending || attempts.Failures+attempts.Pending >= limit) {
+				wait = t.config.BaseDelay
+			}
+			if wait > 0 {
+				return &TooManyAttemptsError{RetryAfter: wait}
+			}
+			attempts.Pending++
+			attempts.LastAttempt = now
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Join(err, t.unreserve(ctx, keys[:i]))
+		}
+	}
+	return &loginAttempt{throttle: t, username: username, ip: ip}, nil
+}
+
+// loginAttempt é uma tentativa de login reservada no throttle.
+type loginAttempt struct {
+	throttle *loginThrottle
+	username string
+	ip       string
+	// settled indica que a reserva já foi convertida em sucesso, falha ou liberada.
+	settled bool
+}
+
+// fail converte a reserva em uma falha do username e do IP.
+func (a *loginAttempt) fail(ctx context.Context) error {
+	if a.settled {
+		return nil
+	}
+	a.settled = true
+	t := a.throttle
+	now := time.Now()
+	for i, key := range t.keys(a.username, a.ip) {
+		limit := t.config.MaxUserFailures
+		if i > 0 {
+			limit = t.config.MaxIPFailures
+		}
+		err := t.store.Update(ctx, key, func(attempts *LoginAttempts) error {
+			t.expire(attempts, now)
+			if attempts.Pending > 0 {
+				attempts.Pending--
+			}
+			attempts.Failures++
+			attempts.LastFailure = now
+			if attempts.Failures >= limit {
+				attempts.LockedUntil = now.Add(t.config.LockoutDuration)
+				attempts.Failures = 0
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// succeed zera a contagem do username. A do IP só expira pela janela, para que um login
+// válido não libere novas tentativas contra outras contas; dela só sai a reserva.
+func (a *loginAttempt) succeed(ctx context.Context) error {
+	if a.settled {
+		return nil
+	}
+	a.settled = true
+	err := a.throttle.store.Delete(ctx, userAttemptKey(a.username))
+	return errors.Join(err, a.throttle.unreserve(ctx, a.throttle.keys(a.username, a.ip)[1:]))
+}
+
+// release desfaz a reserva de uma tentativa interrompida antes de a senha ser decidida
+// (ex.: erro do banco de dados ou usuário desativado). Não faz nada se ela já terminou.
+func (a *loginAttempt) release(ctx context.Context) error {
+	if a.settled {
+		return nil
+	}
+	a.settled = true
+	return a.throttle.unreserve(ctx, a.throttle.keys(a.username, a.ip))
+}
+
+// unreserve libera uma reserva de cada chave.
+func (t *loginThrottle) unreserve(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		errs = append(errs, t.store.Update(ctx, key, func(attempts *LoginAttempts) error {
+			if attempts.Pending > 0 {
+				attempts.Pending--
+			}
+			return nil
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// expire zera as falhas fora da janela, se não houver bloqueio ativo, e as reservas
+// esquecidas, como as de um processo que caiu durante a conferência da senha.
+func (t *loginThrottle) expire(attempts *LoginAttempts, now time.Time) {
+	if now.Sub(attempts.LastFailure) > t.config.FailureWindow && now.After(attempts.LockedUntil) {
+		attempts.Failures = 0
+	}
+	if now.Sub(attempts.LastAttempt) > attemptPendingTimeout {
+		attempts.Pending = 0
+	}
+}
+
+func (t *loginThrottle) keys(username, ip string) []string {
+	keys := []string{userAttemptKey(username)}
+	if ip != "" {
+		keys = append(keys, ipAttemptKey(ip))
+	}
+	return keys
+}
+
+// retryAfter calcula a espera restante: o bloqueio, se ativo, ou o backoff exponencial
+// BaseDelay * 2^(falhas-1), limitado a MaxDelay.
+func (t *loginThrottle) retryAfter(attempts *LoginAttempts, now time.Time) time.Duration {
+	if now.Before(attempts.LockedUntil) {
+		return attempts.LockedUntil.Sub(now)
+	}
+	if attempts.Failures == 0 || now.Sub(attempts.LastFailure) > t.config.FailureWindow {
+		return 0
+	}
+
+	delay := t.config.BaseDelay
+	for i := 1; i < attempts.Failures && delay < t.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.config.MaxDelay {
+		delay = t.config.MaxDelay
+	}
+	if next := attempts.LastFailure.Add(delay); now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
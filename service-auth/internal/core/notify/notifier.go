@@ -0,0 +1,80 @@
+// internal/core/notify/notifier.go
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// Message é uma notificação a ser entregue a um usuário.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier entrega mensagens aos usuários (e-mail em produção, log em desenvolvimento).
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier cria um Notifier que apenas escreve as mensagens no log.
+// Útil em desenvolvimento; não use em produção, pois o log passa a conter tokens.
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Send(ctx context.Context, msg Message) error {
+	log.Printf("[notify] Para: %s | Assunto: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// SMTPConfig contém os dados de conexão com o servidor SMTP.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier cria um Notifier que envia e-mails em texto puro via SMTP.
+// A autenticação PLAIN só é usada quando Username está preenchido.
+func NewSMTPNotifier(config SMTPConfig) Notifier {
+	return &smtpNotifier{config: config}
+}
+
+func (n *smtpNotifier) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return fmt.Errorf("destinatário inválido: %q", msg.To)
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	addr := n.config.Host + ":" + n.config.Port
+	if err := smtp.SendMail(addr, auth, n.config.From, []string{msg.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("erro ao enviar e-mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,434 @@
+// internal/ldaptest/server.go
+
+// Package ldaptest implementa um servidor LDAP mínimo, em processo, para testar e
+// desenvolver a autenticação por diretório sem um Active Directory. Atende apenas ao que o
+// serviço de autenticação usa: bind simples, busca (com filtros and, or, not, igualdade,
+// presença e substrings) e unbind. Nunca deve ser exposto em produção.
+package ldaptest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// Operações do protocolo (RFC 4511, seção 4.2 em diante).
+const (
+	appBindRequest      = 0
+	appBindResponse     = 1
+	appUnbindRequest    = 2
+	appSearchRequest    = 3
+	appSearchEntry      = 4
+	appSearchDone       = 5
+	appExtendedRequest  = 23
+	appExtendedResponse = 24
+)
+
+// Códigos de resultado usados nas respostas.
+const (
+	resultSuccess            = 0
+	resultOperationsError    = 1
+	resultProtocolError      = 2
+	resultSizeLimitExceeded  = 4
+	resultNoSuchObject       = 32
+	resultInvalidCredentials = 49
+)
+
+// Escopos de busca.
+const (
+	scopeBaseObject   = 0
+	scopeSingleLevel  = 1
+	scopeWholeSubtree = 2
+)
+
+// Entry é uma entrada do diretório. Password é a senha aceita no bind simples com o DN da
+// entrada; entradas sem senha não podem fazer bind.
+type Entry struct {
+	DN         string              `json:"dn"`
+	Password   string              `json:"password,omitempty"`
+	Attributes map[string][]string `json:"attributes"`
+}
+
+// Server é um servidor LDAP em execução.
+type Server struct {
+	listener net.Listener
+	entries  []Entry
+
+	mu     sync.Mutex
+	conns  map[net.Conn]bool
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// LoadEntries lê as entradas do diretório de um arquivo JSON (lista de Entry).
+func LoadEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o diretório de teste: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("diretório de teste inválido: %w", err)
+	}
+	return entries, nil
+}
+
+// Start sobe o servidor em addr ("127.0.0.1:0" escolhe uma porta livre) com as entradas
+// informadas, que não podem ser alteradas depois.
+func Start(addr string, entries []Entry) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, entries: entries, conns: make(map[net.Conn]bool)}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// URL retorna o endereço do servidor no formato ldap://host:porta.
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// Close encerra o servidor e as conexões abertas.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+// session guarda o estado de uma conexão: o DN autenticado no último bind.
+type session struct {
+	conn  net.Conn
+	bound string
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	sess := &session{conn: conn}
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("ldaptest: erro ao ler requisição: %v", err)
+			}
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+		op := packet.Children[1]
+		if op.ClassType != ber.ClassApplication {
+			return
+		}
+
+		switch op.Tag {
+		case appBindRequest:
+			s.bind(sess, messageID, op)
+		case appUnbindRequest:
+			return
+		case appSearchRequest:
+			s.search(sess, messageID, op)
+		case appExtendedRequest:
+			// StartTLS e demais operações estendidas não são suportadas.
+			sess.reply(messageID, appExtendedResponse, resultProtocolError, "operação estendida não suportada")
+		default:
+			return
+		}
+	}
+}
+
+// bind autentica a conexão com o DN e a senha (bind simples). Sem senha, o bind é anônimo
+// e sempre aceito, como nos servidores reais; a conexão fica sem autenticação.
+func (s *Server) bind(sess *session, messageID int64, op *ber.Packet) {
+	if len(op.Children) < 3 {
+		sess.reply(messageID, appBindResponse, resultProtocolError, "bind inválido")
+		return
+	}
+	dn := text(op.Children[1])
+	auth := op.Children[2]
+	if auth.ClassType != ber.ClassContext || auth.Tag != 0 {
+		sess.reply(messageID, appBindResponse, resultProtocolError, "apenas bind simples é suportado")
+		return
+	}
+	password := text(auth)
+
+	sess.bound = ""
+	if password == "" {
+		sess.reply(messageID, appBindResponse, resultSuccess, "")
+		return
+	}
+	entry := s.find(dn)
+	if entry == nil || entry.Password == "" || entry.Password != password {
+		sess.reply(messageID, appBindResponse, resultInvalidCredentials, "credenciais inválidas")
+		return
+	}
+	sess.bound = entry.DN
+	sess.reply(messageID, appBindResponse, resultSuccess, "")
+}
+
+// search responde às buscas de conexões autenticadas.
+func (s *Server) search(sess *session, messageID int64, op *ber.Packet) {
+	if len(op.Children) < 8 {
+		sess.reply(messageID, appSearchDone, resultProtocolError, "busca inválida")
+		return
+	}
+	if sess.bound == "" {
+		sess.reply(messageID, appSearchDone, resultOperationsError, "é necessário autenticar antes da busca")
+		return
+	}
+	base := normalizeDN(text(op.Children[0]))
+	scope, _ := op.Children[1].Value.(int64)
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var attributes []string
+	for _, attr := range op.Children[7].Children {
+		attributes = append(attributes, text(attr))
+	}
+
+	if base != "" && s.find(base) == nil {
+		sess.reply(messageID, appSearchDone, resultNoSuchObject, "base não encontrada")
+		return
+	}
+
+	sent := int64(0)
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if !inScope(normalizeDN(entry.DN), base, scope) || !matches(entry, filter) {
+			continue
+		}
+		if sizeLimit > 0 && sent == sizeLimit {
+			sess.reply(messageID, appSearchDone, resultSizeLimitExceeded, "")
+			return
+		}
+		sess.send(messageID, searchEntry(entry, attributes))
+		sent++
+	}
+	sess.reply(messageID, appSearchDone, resultSuccess, "")
+}
+
+// find retorna a entrada com o DN informado, sem diferenciar maiúsculas.
+func (s *Server) find(dn string) *Entry {
+	dn = normalizeDN(dn)
+	for i := range s.entries {
+		if normalizeDN(s.entries[i].DN) == dn {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case scopeBaseObject:
+		return dn == base
+	case scopeSingleLevel:
+		parent := ""
+		if _, rest, ok := strings.Cut(dn, ","); ok {
+			parent = rest
+		}
+		return dn != base && parent == base
+	default:
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// matches avalia o filtro de busca (RFC 4511, seção 4.5.1.7) sobre a entrada.
+func matches(entry *Entry, filter *ber.Packet) bool {
+	if filter.ClassType != ber.ClassContext {
+		return false
+	}
+	switch filter.Tag {
+	case 0: // and
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case 1: // or
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case 2: // not
+		return len(filter.Children) == 1 && !matches(entry, filter.Children[0])
+	case 3: // equalityMatch
+		if len(filter.Children) != 2 {
+			return false
+		}
+		want := text(filter.Children[1])
+		for _, value := range values(entry, text(filter.Children[0])) {
+			if strings.EqualFold(value, want) {
+				return true
+			}
+		}
+		return false
+	case 4: // substrings
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, value := range values(entry, text(filter.Children[0])) {
+			if matchSubstrings(strings.ToLower(value), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	case 7: // present
+		return len(values(entry, text(filter))) > 0
+	default:
+		return false
+	}
+}
+
+func matchSubstrings(value string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		sub := strings.ToLower(text(part))
+		switch part.Tag {
+		case 0: // initial
+			if !strings.HasPrefix(value, sub) {
+				return false
+			}
+			value = value[len(sub):]
+		case 1: // any
+			i := strings.Index(value, sub)
+			if i < 0 {
+				return false
+			}
+			value = value[i+len(sub):]
+		case 2: // final
+			if !strings.HasSuffix(value, sub) {
+				return false
+			}
+			value = ""
+		}
+	}
+	return true
+}
+
+// values retorna os valores do atributo, sem diferenciar maiúsculas no nome.
+func values(entry *Entry, name string) []string {
+	for attr, vals := range entry.Attributes {
+		if strings.EqualFold(attr, name) {
+			return vals
+		}
+	}
+	return nil
+}
+
+func searchEntry(entry *Entry, requested []string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, appSearchEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "Object Name"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	all := len(requested) == 0
+	for _, name := range requested {
+		all = all || name == "*"
+	}
+	for attr, vals := range entry.Attributes {
+		if !all && !contains(requested, attr) {
+			continue
+		}
+		item := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		item.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attr, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range vals {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		item.AppendChild(set)
+		attrs.AppendChild(item)
+	}
+	op.AppendChild(attrs)
+	return op
+}
+
+// reply envia uma resposta com resultado (LDAPResult) à operação.
+func (sess *session) reply(messageID int64, tag ber.Tag, code int64, message string) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	sess.send(messageID, op)
+}
+
+func (sess *session) send(messageID int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	packet.AppendChild(op)
+	if _, err := sess.conn.Write(packet.Bytes()); err != nil {
+		log.Printf("ldaptest: erro ao enviar resposta: %v", err)
+	}
+}
+
+// text retorna o conteúdo de um elemento primitivo como texto.
+func text(p *ber.Packet) string {
+	if p.Data == nil {
+		return ""
+	}
+	return p.Data.String()
+}
+
+// normalizeDN simplifica o DN para comparação: minúsculas e sem espaços entre os RDNs.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+// internal/oidctest/provider.go
+
+// Package oidctest implementa um provedor OIDC mínimo, em processo, para testar e
+// desenvolver o login externo sem um provedor real. Aprova qualquer autorização sem tela de
+// login: a identidade é o e-mail em login_hint (ou Config.DefaultEmail) e login_hint=deny
+// simula a recusa do usuário. Nunca deve ser exposto em produção.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyID   = "mock-1"
+	codeTTL = time.Minute
+	idTTL   = 5 * time.Minute
+)
+
+// Config configura o provedor.
+type Config struct {
+	// Issuer é a URL pela qual o provedor é acessado; vazio usa http://<endereço ouvido>.
+	Issuer   string
+	ClientID string
+	// ClientSecret, se preenchido, é exigido na troca do código.
+	ClientSecret string
+	// DefaultEmail é a identidade aprovada quando a autorização não traz login_hint.
+	DefaultEmail string
+}
+
+// authorization é um código de autorização emitido e ainda não trocado.
+type authorization struct {
+	ClientID      string
+	RedirectURI   string
+	CodeChallenge string
+	Nonce         string
+	Email         string
+	ExpiresAt     time.Time
+}
+
+// Server é um provedor OIDC em execução.
+type Server struct {
+	server *http.Server
+	config Config
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+// Start sobe o provedor em addr ("127.0.0.1:0" escolhe uma porta livre), com uma chave de
+// assinatura RS256 gerada na hora.
+func Start(addr string, config Config) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if config.Issuer == "" {
+		config.Issuer = "http://" + listener.Addr().String()
+	}
+	s := &Server{config: config, key: key, codes: make(map[string]authorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Erro no provedor OIDC de teste: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// URL retorna o issuer do provedor.
+func (s *Server) URL() string {
+	return s.config.Issuer
+}
+
+// Close encerra o provedor e as conexões abertas.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := s.config.Issuer
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize aprova o pedido e redireciona ao redirect_uri com o código.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("redirect_uri") == "" {
+		http.Error(w, "redirect_uri inválido", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != s.config.ClientID {
+		http.Error(w, "client_id desconhecido", http.StatusBadRequest)
+		return
+	}
+
+	params := url.Values{"state": {q.Get("state")}}
+	email := q.Get("login_hint")
+	if email == "" {
+		email = s.config.DefaultEmail
+	}
+	switch {
+	case email == "deny":
+		params.Set("error", "access_denied")
+	case q.Get("response_type") != "code":
+		params.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		params.Set("error", "invalid_request")
+		params.Set("error_description", "PKCE S256 obrigatório")
+	default:
+		code := randomString()
+		s.mu.Lock()
+		s.codes[code] = authorization{
+			ClientID:      s.config.ClientID,
+			RedirectURI:   q.Get("redirect_uri"),
+			CodeChallenge: q.Get("code_challenge"),
+			Nonce:         q.Get("nonce"),
+			Email:         email,
+			ExpiresAt:     time.Now().Add(codeTTL),
+		}
+		s.mu.Unlock()
+		params.Set("code", code)
+		log.Printf("Código emitido para %s", email)
+	}
+
+	query := redirectURI.Query()
+	for k, v := range params {
+		query[k] = v
+	}
+	redirectURI.RawQuery = query.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token troca o código pelo ID token, conferindo o cliente, o redirect_uri e o PKCE.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "")
+		return
+	}
+	form := r.PostForm
+	if form.Get("client_id") != s.config.ClientID ||
+		(s.config.ClientSecret != "" && subtle.ConstantTimeCompare([]byte(form.Get("client_secret")), []byte(s.config.ClientSecret)) != 1) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	auth, ok := s.codes[form.Get("code")]
+	delete(s.codes, form.Get("code"))
+	s.mu.Unlock()
+	if !ok || time.Now().After(auth.ExpiresAt) || auth.RedirectURI != form.Get("redirect_uri") {
+		tokenError(w, "invalid_grant", "código inválido, expirado ou já usado")
+		return
+	}
+	sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.CodeChallenge {
+		tokenError(w, "invalid_grant", "code_verifier não confere")
+		return
+	}
+
+	now := time.Now()
+	subject := sha256.Sum256([]byte(auth.Email))
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.config.Issuer,
+		"sub":            "mock-" + hex.EncodeToString(subject[:8]),
+		"aud":            auth.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(idTTL).Unix(),
+		"nonce":          auth.Nonce,
+		"email":          auth.Email,
+		"email_verified": true,
+	})
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(idTTL.Seconds()),
+		"id_token":     signed,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
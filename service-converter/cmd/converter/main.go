@@ -2,16 +2,30 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"converter-service/internal/api/handlers"
 	"converter-service/internal/api/responses"
+	"converter-service/internal/config"
 	"converter-service/internal/core/converter"
 
 	"github.com/gin-gonic/gin"
+	"shared/middleware"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: configuração inválida: %v", err)
+	}
+
 	responses.InitLogger()
 
 	converterService := converter.NewService()
@@ -19,7 +33,7 @@ func main() {
 
 	router := gin.Default()
 
-	apiV1 := router.Group("/api/v1")
+	apiV1 := router.Group("/api/v1", middleware.MaxBodySize(cfg.MaxUploadMB<<20, responses.Error))
 	{
 		apiV1.POST("/convert/francesinha", converterHandler.HandleSicrediConversion)
 		apiV1.POST("/convert/receitas-acisa", converterHandler.HandleReceitasAcisaConversion)
@@ -31,9 +45,8 @@ func main() {
 		c.JSON(200, gin.H{"status": "UP", "service": "converter-service"})
 	})
 
-	const port = "8083"
-	log.Printf("🚀 Converter Service (Go) iniciado e escutando na porta %s", port)
-	if err := router.Run(":" + port); err != nil {
+	log.Printf("🚀 Converter Service (Go) iniciado e escutando na porta %d", cfg.Port)
+	if err := router.Run(fmt.Sprintf(":%d", cfg.Port)); err != nil {
 		log.Fatal("Falha ao iniciar o servidor de conversão: ", err)
 	}
 }
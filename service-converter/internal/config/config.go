@@ -0,0 +1,41 @@
+// internal/config/config.go
+
+// Package config holds the typed configuration of the converter service, loaded from
+// defaults, an optional YAML file, environment variables (including .env) and flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"shared/configloader"
+)
+
+// Config holds all converter service settings.
+type Config struct {
+	Port int `yaml:"port" env:"PORT" flag:"port" default:"8083" usage:"HTTP port of the service"`
+	// MaxUploadMB caps the size of each request body (spreadsheets plus chart of accounts); zero, the default,
+	// leaves it unlimited so that large uploads are bounded only by the gateway.
+	MaxUploadMB int64 `yaml:"max_upload_mb" env:"MAX_UPLOAD_MB" flag:"max-upload-mb" default:"0" usage:"maximum size of each request in MB (0 for no limit)"`
+}
+
+// Load reads the configuration; args are the command line arguments (nil skips flags).
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	if err := configloader.Load(cfg, "converter", args); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the loaded values.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT inválido: %d", c.Port))
+	}
+	if c.MaxUploadMB < 0 {
+		errs = append(errs, fmt.Errorf("MAX_UPLOAD_MB não pode ser negativo: %d", c.MaxUploadMB))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,296 @@
+// configloader/loader.go
+
+// Package configloader fills the typed configuration structs of the services from
+// defaults, an optional YAML file, environment variables and flags. Each service declares
+// its own Config in internal/config and calls Load.
+package configloader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// The loader is generic and driven by the tags on the configuration struct fields:
+//
+//	yaml:"name"        key in the YAML file (nested structs become sections)
+//	env:"NAME"         environment variable
+//	flag:"name"        command line flag
+//	default:"value"    default value
+//	usage:"text"       description shown by -help
+//
+// Supported field types are string, bool, int, int64, float64, time.Duration and []string
+// (CSV in variables and flags, a sequence in YAML).
+
+// ConfigFileEnv names the optional YAML file when the -config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// validator is implemented by configurations that check the loaded values.
+type validator interface {
+	Validate() error
+}
+
+// Load fills cfg, a pointer to a struct, in increasing order of precedence: defaults,
+// the YAML file (-config or CONFIG_FILE), environment variables (including those from a .env
+// file in the working directory, which never override variables already set) and flags.
+// A variable or flag set to an empty value clears the field. Flags are not parsed when args
+// is nil. Validate is called last when cfg implements it.
+func Load(cfg any, name string, args []string) error {
+	root := reflect.ValueOf(cfg)
+	if root.Kind() != reflect.Pointer || root.Elem().Kind() != reflect.Struct {
+		return errors.New("configloader: Load exige um ponteiro para struct")
+	}
+	fields := collectFields(root.Elem(), nil)
+
+	for _, f := range fields {
+		if f.defaultValue == "" {
+			continue
+		}
+		if err := setField(f.value, f.defaultValue); err != nil {
+			return fmt.Errorf("padrão inválido para %s: %w", f.path, err)
+		}
+	}
+
+	configFile := ""
+	var flagValues map[string]string
+	if args != nil {
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		flags.StringVar(&configFile, "config", "", "YAML configuration file (or "+ConfigFileEnv+")")
+		flagValues = make(map[string]string)
+		for _, f := range fields {
+			if f.flag != "" {
+				flags.Var(&flagValue{values: flagValues, name: f.flag, field: f.value}, f.flag, f.usage)
+			}
+		}
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+	}
+
+	if err := loadDotEnv(".env"); err != nil {
+		return err
+	}
+	if configFile == "" {
+		configFile = os.Getenv(ConfigFileEnv)
+	}
+	if configFile != "" {
+		if err := loadYAML(configFile, cfg); err != nil {
+			return err
+		}
+		log.Printf("Configuração carregada de %s", configFile)
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(f.env); ok {
+			if err := setField(f.value, value); err != nil {
+				return fmt.Errorf("%s inválido: %q: %w", f.env, value, err)
+			}
+		}
+	}
+	for _, f := range fields {
+		if value, ok := flagValues[f.flag]; ok && f.flag != "" {
+			if err := setField(f.value, value); err != nil {
+				return fmt.Errorf("-%s inválido: %q: %w", f.flag, value, err)
+			}
+		}
+	}
+
+	if v, ok := cfg.(validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// field is a configurable field found through its tags.
+type field struct {
+	path         string
+	value        reflect.Value
+	env          string
+	flag         string
+	defaultValue string
+	usage        string
+}
+
+// collectFields walks the struct, descending into nested structs.
+func collectFields(v reflect.Value, parents []string) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		path := append(append([]string{}, parents...), name)
+		if sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(v.Field(i), path)...)
+			continue
+		}
+		fields = append(fields, field{
+			path:         strings.Join(path, "."),
+			value:        v.Field(i),
+			env:          sf.Tag.Get("env"),
+			flag:         sf.Tag.Get("flag"),
+			defaultValue: sf.Tag.Get("default"),
+			usage:        sf.Tag.Get("usage"),
+		})
+	}
+	return fields
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField converts text to the field type; empty text sets the zero value.
+func setField(v reflect.Value, text string) error {
+	if text == "" {
+		v.SetZero()
+		return nil
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("tipo não suportado: %s", v.Type())
+		}
+		items := []string{}
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("tipo não suportado: %s", v.Type())
+	}
+	return nil
+}
+
+// flagValue keeps the text given on the command line; it is applied only after the
+// environment variables so that flags take precedence.
+type flagValue struct {
+	values map[string]string
+	name   string
+	field  reflect.Value
+}
+
+func (f *flagValue) String() string {
+	if f == nil || !f.field.IsValid() {
+		return ""
+	}
+	if f.field.Type() == durationType {
+		return time.Duration(f.field.Int()).String()
+	}
+	if f.field.Kind() == reflect.Slice {
+		return strings.Join(f.field.Interface().([]string), ",")
+	}
+	return fmt.Sprint(f.field.Interface())
+}
+
+func (f *flagValue) Set(text string) error {
+	// Parse into a scratch value now so that the error points at the flag.
+	probe := reflect.New(f.field.Type()).Elem()
+	if err := setField(probe, text); err != nil {
+		return err
+	}
+	f.values[f.name] = text
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.field.Kind() == reflect.Bool
+}
+
+// loadYAML applies the file over the defaults; unknown keys are rejected.
+func loadYAML(path string, cfg any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler o arquivo de configuração: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data), yaml.DisallowUnknownField())
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("arquivo de configuração %s inválido: %w", path, err)
+	}
+	return nil
+}
+
+// loadDotEnv sets the variables from the file that are not yet in the environment. Comments,
+// the "export" prefix and single or double quoted values are accepted.
+func loadDotEnv(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("erro ao carregar %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: linha sem \"=\"", path, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if _, exists := os.LookupEnv(key); !exists {
+			os.Setenv(key, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao carregar %s: %w", path, err)
+	}
+	log.Printf("Variáveis de ambiente carregadas de %s", path)
+	return nil
+}
@@ -0,0 +1,146 @@
+package configloader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name    string        `yaml:"name" env:"TEST_NAME" flag:"name" default:"padrao"`
+	Port    int           `yaml:"port" env:"TEST_PORT" flag:"port" default:"8080"`
+	Timeout time.Duration `yaml:"timeout" env:"TEST_TIMEOUT" flag:"timeout" default:"5s"`
+	Hosts   []string      `yaml:"hosts" env:"TEST_HOSTS" default:"a,b"`
+	Nested  struct {
+		Enabled bool `yaml:"enabled" env:"TEST_ENABLED" flag:"enabled"`
+	} `yaml:"nested"`
+}
+
+// setupLoad isolates the test: a working directory without .env, none of the test variables
+// set and, when yamlContent is given, a YAML file in CONFIG_FILE.
+func setupLoad(t *testing.T, yamlContent string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv(ConfigFileEnv, "")
+	for _, env := range []string{"TEST_NAME", "TEST_PORT", "TEST_TIMEOUT", "TEST_HOSTS", "TEST_ENABLED"} {
+		t.Setenv(env, "")
+		os.Unsetenv(env)
+	}
+	if yamlContent != "" {
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte(yamlContent), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(ConfigFileEnv, path)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const yamlContent = "name: yaml\nport: 9000\ntimeout: 10s\nnested:\n  enabled: true\n"
+	tests := []struct {
+		name     string
+		yaml     string
+		env      map[string]string
+		args     []string
+		wantName string
+		wantPort int
+	}{
+		{"padrões", "", nil, []string{}, "padrao", 8080},
+		{"YAML sobre os padrões", yamlContent, nil, []string{}, "yaml", 9000},
+		{"variável sobre o YAML", yamlContent, map[string]string{"TEST_NAME": "env"}, []string{}, "env", 9000},
+		{"flag sobre a variável", yamlContent, map[string]string{"TEST_NAME": "env", "TEST_PORT": "9100"}, []string{"-name", "flag"}, "flag", 9100},
+		{"flags ignoradas com args nil", yamlContent, nil, nil, "yaml", 9000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupLoad(t, tt.yaml)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var cfg testConfig
+			if err := Load(&cfg, "test", tt.args); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Name != tt.wantName || cfg.Port != tt.wantPort {
+				t.Errorf("name = %q, port = %d; esperado %q, %d", cfg.Name, cfg.Port, tt.wantName, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestLoadEmptyVariableClearsValue(t *testing.T) {
+	setupLoad(t, "name: yaml\nhosts: [x, y]\n")
+	t.Setenv("TEST_NAME", "")
+	t.Setenv("TEST_HOSTS", "")
+	t.Setenv("TEST_TIMEOUT", "")
+
+	var cfg testConfig
+	if err := Load(&cfg, "test", nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "" || len(cfg.Hosts) != 0 || cfg.Timeout != 0 {
+		t.Errorf("cfg = %+v, esperados os campos zerados pelas variáveis vazias", cfg)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("port = %d, esperado o padrão para a variável ausente", cfg.Port)
+	}
+}
+
+func TestLoadTypes(t *testing.T) {
+	setupLoad(t, "")
+	t.Setenv("TEST_HOSTS", " c , ,d ")
+	t.Setenv("TEST_ENABLED", "true")
+
+	var cfg testConfig
+	if err := Load(&cfg, "test", []string{"-timeout", "1m30s", "-port=7000"}); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !slices.Equal(cfg.Hosts, []string{"c", "d"}) || !cfg.Nested.Enabled || cfg.Timeout != 90*time.Second || cfg.Port != 7000 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadWrapsParseErrors(t *testing.T) {
+	setupLoad(t, "")
+	t.Setenv("TEST_PORT", "oitenta")
+
+	var cfg testConfig
+	err := Load(&cfg, "test", nil)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Load: %v, esperado erro com %v", err, strconv.ErrSyntax)
+	}
+
+	setupLoad(t, "")
+	if err := Load(&cfg, "test", []string{"-timeout", "logo"}); err == nil {
+		t.Error("Load aceitou -timeout inválido")
+	}
+}
+
+func TestLoadRejectsUnknownYAMLKey(t *testing.T) {
+	setupLoad(t, "nome: yaml\n")
+	var cfg testConfig
+	if err := Load(&cfg, "test", nil); err == nil {
+		t.Error("Load aceitou uma chave desconhecida no YAML")
+	}
+}
+
+func TestLoadDotEnvDoesNotOverrideEnvironment(t *testing.T) {
+	setupLoad(t, "")
+	if err := os.WriteFile(".env", []byte("# comentário\nexport TEST_NAME='dotenv'\nTEST_PORT=\"9200\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_PORT", "9300")
+
+	var cfg testConfig
+	if err := Load(&cfg, "test", nil); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "dotenv" || cfg.Port != 9300 {
+		t.Errorf("name = %q, port = %d; esperado dotenv e a variável já definida (9300)", cfg.Name, cfg.Port)
+	}
+}
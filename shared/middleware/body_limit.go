@@ -0,0 +1,30 @@
+// middleware/body_limit.go
+
+// Package middleware holds the gin middleware shared by the services.
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize rejects requests whose body exceeds limit bytes. Declared sizes are refused
+// up front with 413 through reject, the service's error responder; chunked bodies are cut off
+// while being read. A limit of zero or less accepts any size.
+func MaxBodySize(limit int64, reject func(c *gin.Context, code int, message string, errs ...string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			reject(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Requisição maior que o limite de %d MB", limit>>20))
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}